@@ -0,0 +1,4 @@
+ephemeral "emma_ssh_key_pair" "ssh_key_pair" {
+  name     = "example"
+  key_type = "ED25519"
+}
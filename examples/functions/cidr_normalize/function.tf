@@ -0,0 +1,9 @@
+# "10.0.0.0/8"
+output "ipv4" {
+  value = provider::emma::cidr_normalize("10.1.2.3/8")
+}
+
+# "2001:db8::1"
+output "ipv6" {
+  value = provider::emma::cidr_normalize("2001:0db8:0000:0000:0000:0000:0000:0001/128")
+}
@@ -0,0 +1,18 @@
+locals {
+  data_center = provider::emma::parse_data_center_id("gcp-europe-west8-a")
+}
+
+# "gcp"
+output "provider" {
+  value = local.data_center.provider
+}
+
+# "europe-west8"
+output "region" {
+  value = local.data_center.region
+}
+
+# "europe-west8-a"
+output "zone" {
+  value = local.data_center.zone
+}
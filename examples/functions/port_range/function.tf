@@ -0,0 +1,13 @@
+locals {
+  ports = provider::emma::port_range("3000-3010")
+}
+
+# 3000
+output "from" {
+  value = local.ports.from
+}
+
+# 3010
+output "to" {
+  value = local.ports.to
+}
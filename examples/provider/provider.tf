@@ -10,4 +10,13 @@ terraform {
 provider "emma" {
   client_id     = "your client id"
   client_secret = "your client secret"
+
+  default_labels = {
+    team = "team-a"
+  }
+
+  naming {
+    prefix        = "team-a-"
+    suffix_random = 4
+  }
 }
@@ -1,5 +1,5 @@
 resource "emma_security_group" "security_group" {
-  name = "Example"
+  name = "example"
   rules = [
     {
       direction = "INBOUND"
@@ -12,6 +12,12 @@ resource "emma_security_group" "security_group" {
       protocol  = "all"
       ports     = "8080"
       ip_range  = "4.4.4.4/32"
+    },
+    {
+      direction   = "INBOUND"
+      service     = "postgres"
+      ip_range    = "10.0.0.0/8"
+      description = "Application servers access the database"
     }
   ]
 }
\ No newline at end of file
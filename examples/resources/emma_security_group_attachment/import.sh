@@ -0,0 +1,2 @@
+# Security group attachments can be imported by specifying the security group ID and the instance ID.
+terraform import emma_security_group_attachment.security_group_attachment 123/456
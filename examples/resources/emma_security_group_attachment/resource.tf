@@ -0,0 +1,4 @@
+resource "emma_security_group_attachment" "security_group_attachment" {
+  security_group_id = emma_security_group.security_group.id
+  instance_id       = emma_vm.vm.id
+}
@@ -1,5 +1,5 @@
 resource "emma_spot_instance" "spot_instance" {
-  name               = "Example"
+  name               = "example"
   data_center_id     = data.emma_data_center.aws_spot.id
   os_id              = data.emma_operating_system.ubuntu.id
   cloud_network_type = "multi-cloud"
@@ -1,4 +1,16 @@
 resource "emma_ssh_key" "ssh_key" {
-  name     = "Example"
+  name     = "example"
   key_type = "RSA"
-}
\ No newline at end of file
+}
+
+resource "emma_ssh_key" "ssh_key_with_private_key_file" {
+  name             = "example-with-private-key-file"
+  key_type         = "ED25519"
+  private_key_file = "${path.module}/keys/id_ed25519"
+}
+
+resource "emma_ssh_key" "locally_generated_ssh_key" {
+  name             = "example-generated-locally"
+  key_type         = "ED25519"
+  generate_locally = true
+}
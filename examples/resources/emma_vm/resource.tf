@@ -1,13 +1,18 @@
 resource "emma_vm" "vm" {
-  name               = "Example"
-  data_center_id     = data.emma_data_center.aws.id
-  os_id              = data.emma_operating_system.ubuntu.id
-  cloud_network_type = "multi-cloud"
-  vcpu_type          = "shared"
-  vcpu               = 2
-  ram_gb             = 1
-  volume_type        = "ssd"
-  volume_gb          = 8
-  security_group_id  = emma_security_group.security_group.id
-  ssh_key_id         = emma_ssh_key.ssh_key.id
+  name                = "example"
+  data_center_id      = data.emma_data_center.aws.id
+  os_id               = data.emma_operating_system.ubuntu.id
+  cloud_network_type  = "multi-cloud"
+  vcpu_type           = "shared"
+  vcpu                = 2
+  ram_gb              = 1
+  volume_type         = "ssd"
+  volume_gb           = 8
+  security_group_id   = emma_security_group.security_group.id
+  ssh_key_id          = emma_ssh_key.ssh_key.id
+  deletion_protection = true
+
+  labels = {
+    cost-center = "backend"
+  }
 }
\ No newline at end of file
@@ -0,0 +1,97 @@
+package emma
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"strings"
+)
+
+// cloudCapabilities describes what the cloud provider behind emma supports for the compute instances,
+// the resources plan the changes as in place or as replacement accordingly.
+type cloudCapabilities struct {
+	// resizeVolumeInPlace is true if the volume of the compute instance can be extended without recreating it
+	resizeVolumeInPlace bool
+	// shrinkVolume is true if the volume of the compute instance can be decreased
+	shrinkVolume bool
+	// combinedEdit is true if the volume is changed together with the hardware by the edit hardware action,
+	// otherwise the volume is resized separately by the volume action
+	combinedEdit bool
+	// editVCpuType is true if the vCPU type is changed by the edit hardware action, otherwise the compute instance
+	// is recreated with the other vCPU type
+	editVCpuType bool
+	// spot is true if spot instances can be created in the data centers of the provider
+	spot bool
+	// ipv6 is true if the networks of the provider support IPv6 addresses
+	ipv6 bool
+}
+
+// defaultCloudCapabilities are the capabilities of the providers that aren't in cloudCapabilitiesByProvider yet,
+// nothing is assumed to be supported: the hardware changes replace the compute instance and spot instances fail.
+var defaultCloudCapabilities = cloudCapabilities{}
+
+// cloudCapabilitiesByProvider contains the capabilities by the emma provider name, none of the providers decreases
+// the volumes in place:
+//   - Amazon EC2 extends the EBS volumes with Elastic Volumes, changes the instance type of the instance and has
+//     Spot Instances and dual-stack VPCs.
+//   - Microsoft Azure expands the managed disks, resizes the virtual machines and has Azure Spot Virtual Machines
+//     and dual-stack virtual networks.
+//   - Google Cloud Platform resizes the persistent disks, changes the machine type of the instances and has
+//     Spot VMs and dual-stack subnets.
+//   - DigitalOcean resizes the disk only together with the CPU and the RAM of the Droplet, the Droplet plan family
+//     of the vCPU type isn't changed by the resize, it has no spot Droplets and it has IPv6 addresses.
+var cloudCapabilitiesByProvider = map[string]cloudCapabilities{
+	"Amazon EC2":            {resizeVolumeInPlace: true, editVCpuType: true, spot: true, ipv6: true},
+	"Microsoft Azure":       {resizeVolumeInPlace: true, editVCpuType: true, spot: true, ipv6: true},
+	"Google Cloud Platform": {resizeVolumeInPlace: true, editVCpuType: true, spot: true, ipv6: true},
+	"DigitalOcean":          {resizeVolumeInPlace: true, combinedEdit: true, ipv6: true},
+}
+
+// dataCenterProviders contains the emma provider names by the prefix of the data center ID,
+// data center IDs are strings like "digitalocean-sgp1", "gcp-europe-west8-a" etc...
+var dataCenterProviders = map[string]string{
+	"aws":          "Amazon EC2",
+	"azure":        "Microsoft Azure",
+	"gcp":          "Google Cloud Platform",
+	"digitalocean": "DigitalOcean",
+}
+
+// providerCapabilities returns the capabilities of the emma provider.
+func providerCapabilities(providerName string) cloudCapabilities {
+	for name, capabilities := range cloudCapabilitiesByProvider {
+		if strings.EqualFold(name, providerName) {
+			return capabilities
+		}
+	}
+	return defaultCloudCapabilities
+}
+
+// dataCenterCapabilities returns the capabilities of the provider that owns the data center.
+func dataCenterCapabilities(dataCenterId string) cloudCapabilities {
+	prefix, _, _ := strings.Cut(strings.ToLower(dataCenterId), "-")
+	if providerName, ok := dataCenterProviders[prefix]; ok {
+		return providerCapabilities(providerName)
+	}
+	return defaultCloudCapabilities
+}
+
+// planSpotAvailability fails the plan of the spot instance in the data center of the provider without spot instances.
+func planSpotAvailability(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// the resource is destroyed
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var dataCenterId types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("data_center_id"), &dataCenterId)...)
+	if resp.Diagnostics.HasError() || dataCenterId.IsUnknown() || dataCenterId.IsNull() {
+		return
+	}
+
+	if !dataCenterCapabilities(dataCenterId.ValueString()).spot {
+		resp.Diagnostics.AddAttributeError(path.Root("data_center_id"), "Validation Error",
+			fmt.Sprintf("Spot instances aren't available in the data center %s", dataCenterId.ValueString()))
+	}
+}
@@ -0,0 +1,125 @@
+package emma
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDataCenterCapabilities(t *testing.T) {
+	assert.True(t, dataCenterCapabilities("aws-eu-north-1").editVCpuType)
+	assert.True(t, dataCenterCapabilities("gcp-europe-west8-a").spot)
+	assert.False(t, dataCenterCapabilities("aws-eu-north-1").combinedEdit)
+
+	assert.False(t, dataCenterCapabilities("digitalocean-ams3").editVCpuType)
+	assert.False(t, dataCenterCapabilities("DigitalOcean-sgp1").spot)
+	assert.True(t, dataCenterCapabilities("digitalocean-ams3").combinedEdit)
+
+	assert.Equal(t, defaultCloudCapabilities, dataCenterCapabilities("hetzner-fsn1"))
+	assert.Equal(t, defaultCloudCapabilities, dataCenterCapabilities(""))
+}
+
+func TestProviderCapabilities(t *testing.T) {
+	assert.Equal(t, dataCenterCapabilities("azure-westeurope"), providerCapabilities("Microsoft Azure"))
+	assert.Equal(t, dataCenterCapabilities("digitalocean-ams3"), providerCapabilities("digitalocean"))
+	assert.Equal(t, defaultCloudCapabilities, providerCapabilities("Hetzner"))
+}
+
+func TestPlanSpotAvailability(t *testing.T) {
+	ctx := context.Background()
+	spotSchema := schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"data_center_id": schema.StringAttribute{Required: true},
+		},
+	}
+	objectType := spotSchema.Type().TerraformType(ctx)
+	planSpot := func(dataCenterId string) resource.ModifyPlanResponse {
+		plan := tftypes.NewValue(objectType, map[string]tftypes.Value{
+			"data_center_id": tftypes.NewValue(tftypes.String, dataCenterId),
+		})
+		req := resource.ModifyPlanRequest{Plan: tfsdk.Plan{Schema: spotSchema, Raw: plan}}
+		resp := resource.ModifyPlanResponse{Plan: tfsdk.Plan{Schema: spotSchema, Raw: plan.Copy()}}
+		planSpotAvailability(ctx, req, &resp)
+		return resp
+	}
+
+	assert.Empty(t, planSpot("aws-eu-north-1").Diagnostics)
+	assert.Empty(t, planSpot("gcp-europe-west8-a").Diagnostics)
+
+	// DigitalOcean and the providers that aren't known don't have spot instances
+	for _, dataCenterId := range []string{"digitalocean-ams3", "hetzner-fsn1"} {
+		resp := planSpot(dataCenterId)
+		require.Len(t, resp.Diagnostics, 1)
+		assert.True(t, resp.Diagnostics.HasError())
+		assert.Equal(t, "Spot instances aren't available in the data center "+dataCenterId, resp.Diagnostics[0].Detail())
+	}
+}
+
+func TestPlanVmHardwareChangesVCpuType(t *testing.T) {
+	stateData := protectedVmState(t)
+	stateData.DeletionProtection = types.BoolValue(false)
+	stateData.DataCenterId = types.StringValue("digitalocean-ams3")
+	planData := stateData
+	planData.VCpuType = types.StringValue("standard")
+
+	// DigitalOcean doesn't change the vCPU type in place, the virtual machine is replaced
+	resp := planVmChange(t, stateData, &planData, nil)
+	assert.Empty(t, resp.Diagnostics)
+	assert.Equal(t, []*tftypes.AttributePath{tftypes.NewAttributePath().WithAttributeName("vcpu_type")}, resp.RequiresReplace)
+
+	// the vCPU type is changed in place by the edit hardware action
+	stateData.DataCenterId = types.StringValue("aws-eu-west-1")
+	planData.DataCenterId = stateData.DataCenterId
+	resp = planVmChange(t, stateData, &planData, nil)
+	assert.Empty(t, resp.Diagnostics)
+	assert.Empty(t, resp.RequiresReplace)
+}
+
+func TestPlanVmHardwareChanges(t *testing.T) {
+	vcpuType := tftypes.NewAttributePath().WithAttributeName("vcpu_type")
+	volumeGb := tftypes.NewAttributePath().WithAttributeName("volume_gb")
+	for _, test := range []struct {
+		dataCenterId    string
+		change          func(data *vmResourceModel)
+		requiresReplace []*tftypes.AttributePath
+	}{
+		{"aws-eu-north-1", func(data *vmResourceModel) { data.VolumeGb = types.Int64Value(32) }, nil},
+		{"digitalocean-ams3", func(data *vmResourceModel) { data.VolumeGb = types.Int64Value(32) }, nil},
+		{"aws-eu-north-1", func(data *vmResourceModel) { data.RamGb = types.Int64Value(8) }, nil},
+		// the hardware of the providers that aren't known is replaced
+		{"hetzner-fsn1", func(data *vmResourceModel) { data.VolumeGb = types.Int64Value(32) }, []*tftypes.AttributePath{volumeGb}},
+		{"hetzner-fsn1", func(data *vmResourceModel) { data.VCpuType = types.StringValue("standard") }, []*tftypes.AttributePath{vcpuType}},
+		{"hetzner-fsn1", func(data *vmResourceModel) { data.RamGb = types.Int64Value(8) }, nil},
+	} {
+		stateData := protectedVmState(t)
+		stateData.DeletionProtection = types.BoolValue(false)
+		stateData.DataCenterId = types.StringValue(test.dataCenterId)
+		planData := stateData
+		test.change(&planData)
+
+		resp := planVmChange(t, stateData, &planData, nil)
+		assert.Empty(t, resp.Diagnostics, test.dataCenterId)
+		assert.Equal(t, test.requiresReplace, resp.RequiresReplace, test.dataCenterId)
+	}
+}
+
+func TestPlanVolumeShrinkCapabilities(t *testing.T) {
+	// none of the providers decreases the volumes in place
+	for _, dataCenterId := range []string{"aws-eu-north-1", "azure-westeurope", "gcp-europe-west8-a", "digitalocean-ams3", "hetzner-fsn1"} {
+		stateData := protectedVmState(t)
+		stateData.DeletionProtection = types.BoolValue(false)
+		stateData.DataCenterId = types.StringValue(dataCenterId)
+		planData := stateData
+		planData.VolumeGb = types.Int64Value(8)
+
+		resp := planVmChange(t, stateData, &planData, nil)
+		require.Len(t, resp.Diagnostics, 1, dataCenterId)
+		assert.Contains(t, resp.Diagnostics[0].Detail, "cannot be decreased", dataCenterId)
+	}
+}
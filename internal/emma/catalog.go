@@ -0,0 +1,47 @@
+package emma
+
+import (
+	"context"
+	"errors"
+	"github.com/emma-community/terraform-provider-emma/tools"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// defaultCatalogCacheTtl is the time to live of the catalog lists stored on disk if the ttl isn't set.
+const defaultCatalogCacheTtl = "24h"
+
+// catalogCache caches the catalog lists of the emma API of the host. The lists of the hosts and of the mock API
+// are stored with the different keys, as the cache directory is shared by the provider processes.
+type catalogCache struct {
+	cache *tools.Cache
+	scope string
+}
+
+func newCatalogCache(cache *tools.Cache, host string, mockMode bool) *catalogCache {
+	return &catalogCache{cache: cache, scope: host + " mock=" + strconv.FormatBool(mockMode) + " "}
+}
+
+// readCatalog returns the list of the catalog endpoint, like data centers or operating systems, filtered by the emma
+// API with the query of the request. The lists are cached by the host, the endpoint and the query for the lifetime
+// of the provider process, so the data sources with the same filters share one request.
+func readCatalog[T any](ctx context.Context, catalog *catalogCache, endpoint string, query url.Values,
+	fetch func() ([]T, *http.Response, error)) ([]T, error) {
+	key := catalog.scope + endpoint + "?" + query.Encode()
+	items, cached, err := tools.GetCached(catalog.cache, key, func() ([]T, error) {
+		items, response, err := fetch()
+		if err != nil {
+			if message := tools.ExtractErrorMessage(response); message != "" {
+				return nil, errors.New(message)
+			}
+			return nil, err
+		}
+		return items, nil
+	})
+	if cached {
+		tflog.Debug(ctx, "Read catalog from cache", map[string]interface{}{"key": key})
+	}
+	return items, err
+}
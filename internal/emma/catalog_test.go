@@ -0,0 +1,110 @@
+package emma
+
+import (
+	"context"
+	emmaSdk "github.com/emma-community/emma-go-sdk"
+	"github.com/emma-community/terraform-provider-emma/internal/mock"
+	"github.com/emma-community/terraform-provider-emma/tools"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadCatalogScope(t *testing.T) {
+	directory := t.TempDir()
+	readNames := func(catalog *catalogCache, name string) []string {
+		names, err := readCatalog(context.Background(), catalog, "/v1/providers", url.Values{}, func() ([]string, *http.Response, error) {
+			return []string{name}, nil, nil
+		})
+		require.NoError(t, err)
+		return names
+	}
+
+	assert.Equal(t, []string{"production"}, readNames(newCatalogCache(tools.NewCache(directory, time.Hour), "https://api.emma.ms/external", false), "production"))
+	// the lists are stored on disk by the host and the mock mode
+	assert.Equal(t, []string{"production"}, readNames(newCatalogCache(tools.NewCache(directory, time.Hour), "https://api.emma.ms/external", false), "other"))
+	assert.Equal(t, []string{"mock"}, readNames(newCatalogCache(tools.NewCache(directory, time.Hour), "https://api.emma.ms/external", true), "mock"))
+	assert.Equal(t, []string{"staging"}, readNames(newCatalogCache(tools.NewCache(directory, time.Hour), "https://staging.example.com", false), "staging"))
+}
+
+func TestReadCatalogQuery(t *testing.T) {
+	catalog := newCatalogCache(tools.NewCache("", time.Hour), "https://api.emma.ms/external", false)
+	fetches := 0
+	readNames := func(query url.Values) []string {
+		names, err := readCatalog(context.Background(), catalog, "/v1/providers", query, func() ([]string, *http.Response, error) {
+			fetches++
+			return []string{query.Get("providerName")}, nil, nil
+		})
+		require.NoError(t, err)
+		return names
+	}
+
+	assert.Equal(t, []string{"DigitalOcean"}, readNames(url.Values{"providerName": {"DigitalOcean"}}))
+	assert.Equal(t, []string{"Amazon EC2"}, readNames(url.Values{"providerName": {"Amazon EC2"}}))
+	// the same query is read from the cache
+	assert.Equal(t, []string{"DigitalOcean"}, readNames(url.Values{"providerName": {"DigitalOcean"}}))
+	assert.Equal(t, 2, fetches)
+}
+
+func TestDataCenterDataSourceQuery(t *testing.T) {
+	ctx := context.Background()
+	server, err := mock.NewServer("")
+	require.NoError(t, err)
+	var queries []url.Values
+	configuration := emmaSdk.NewConfiguration()
+	configuration.HTTPClient = &http.Client{Transport: &mock.Transport{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/data-centers") {
+			queries = append(queries, r.URL.Query())
+		}
+		server.ServeHTTP(w, r)
+	})}}
+	apiClient := emmaSdk.NewAPIClient(configuration)
+	token, _, err := apiClient.AuthenticationAPI.IssueToken(ctx).
+		Credentials(emmaSdk.Credentials{ClientId: "id", ClientSecret: "secret"}).Execute()
+	require.NoError(t, err)
+	dataSource := &dataCenterDataSource{apiClient: apiClient, token: token,
+		catalog: newCatalogCache(tools.NewCache("", time.Hour), "mock", true)}
+
+	var schemaResp datasource.SchemaResponse
+	dataSource.Schema(ctx, datasource.SchemaRequest{}, &schemaResp)
+	readDataCenter := func(providerName string, locationId int64) datasource.ReadResponse {
+		config := tfsdk.State{Schema: schemaResp.Schema}
+		require.False(t, config.Set(ctx, &dataCenterDataSourceModel{
+			Id:           types.StringNull(),
+			Name:         types.StringNull(),
+			ProviderName: types.StringValue(providerName),
+			ProviderId:   types.Int64Null(),
+			LocationId:   types.Int64Value(locationId),
+			LocationName: types.StringNull(),
+			Ipv6:         types.BoolNull(),
+		}).HasError())
+		resp := datasource.ReadResponse{State: tfsdk.State{Schema: schemaResp.Schema}}
+		dataSource.Read(ctx, datasource.ReadRequest{Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: config.Raw}}, &resp)
+		return resp
+	}
+
+	resp := readDataCenter("DigitalOcean", 3)
+	require.False(t, resp.Diagnostics.HasError(), resp.Diagnostics)
+	var data dataCenterDataSourceModel
+	require.False(t, resp.State.Get(ctx, &data).HasError())
+	assert.Equal(t, "digitalocean-ams3", data.Id.ValueString())
+	// the filters are sent to the emma API, the data center name isn't set
+	require.Len(t, queries, 1)
+	assert.Equal(t, url.Values{"providerName": {"DigitalOcean"}, "locationId": {"3"}}, queries[0])
+
+	// the same filters are read from the cache
+	require.False(t, readDataCenter("DigitalOcean", 3).Diagnostics.HasError())
+	assert.Len(t, queries, 1)
+
+	resp = readDataCenter("DigitalOcean", 1)
+	require.True(t, resp.Diagnostics.HasError())
+	assert.Equal(t, "Data center not found", resp.Diagnostics.Errors()[0].Detail())
+	assert.Len(t, queries, 2)
+}
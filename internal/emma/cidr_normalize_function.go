@@ -0,0 +1,53 @@
+package emma
+
+import (
+	"context"
+	"fmt"
+	emma "github.com/emma-community/terraform-provider-emma/internal/emma/validation"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+var _ function.Function = &cidrNormalizeFunction{}
+
+func NewCidrNormalizeFunction() function.Function {
+	return &cidrNormalizeFunction{}
+}
+
+// cidrNormalizeFunction defines the function implementation.
+type cidrNormalizeFunction struct{}
+
+func (f *cidrNormalizeFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "cidr_normalize"
+}
+
+func (f *cidrNormalizeFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Normalize an IP address or CIDR",
+		Description: "Returns the canonical form of an IPv4 or IPv6 address or CIDR as the emma_security_group resource " +
+			"compares the ip_range of the rules: the host bits of a CIDR are cleared, a single address CIDR " +
+			"(/32 or /128) becomes the address itself and IPv6 addresses are compressed.",
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "ip_range",
+				Description: "IPv4 or IPv6 address or CIDR, e.g. \"10.0.0.1/8\" or \"2001:0db8::0001/64\".",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *cidrNormalizeFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var ipRange string
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &ipRange))
+	if resp.Error != nil {
+		return
+	}
+
+	if !emma.IsValidIpRange(ipRange) {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Invalid IP range %q, may contain next values: "+
+			"0.0.0.0/0, 1.1.1.1, 1.1.1.1/32, ::/0, 2001:db8::1 or 2001:db8::/32", ipRange))
+		return
+	}
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, emma.NormalizeIpRange(ipRange)))
+}
@@ -0,0 +1,66 @@
+package emma
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	testResource "github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func runCidrNormalizeFunction(ipRange string) function.RunResponse {
+	resp := function.RunResponse{Result: function.NewResultData(types.StringUnknown())}
+	NewCidrNormalizeFunction().Run(context.Background(), function.RunRequest{
+		Arguments: function.NewArgumentsData([]attr.Value{types.StringValue(ipRange)}),
+	}, &resp)
+	return resp
+}
+
+func TestCidrNormalizeFunction(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected string
+	}{
+		{"0.0.0.0/0", "0.0.0.0/0"},
+		{"10.1.2.3/8", "10.0.0.0/8"},
+		{"1.1.1.1/32", "1.1.1.1"},
+		{"1.1.1.1", "1.1.1.1"},
+		{"::/0", "::/0"},
+		{"2001:0db8:0000:0000:0000:0000:0000:0001/64", "2001:db8::/64"},
+		{"2001:db8::1/128", "2001:db8::1"},
+	}
+	for _, test := range tests {
+		resp := runCidrNormalizeFunction(test.in)
+		require.Nil(t, resp.Error, "Is invalid ip value: "+test.in)
+		assert.Equal(t, types.StringValue(test.expected), resp.Result.Value(), "Invalid normalization of: "+test.in)
+	}
+}
+
+func TestCidrNormalizeFunctionInvalidValues(t *testing.T) {
+	for _, ipRange := range []string{"", "a", "1.1.1.1/0", "1.1.1.1/33", "2001:db8::1/129"} {
+		resp := runCidrNormalizeFunction(ipRange)
+		require.NotNil(t, resp.Error, "Is valid ip value: "+ipRange)
+		assert.Equal(t, int64(0), *resp.Error.FunctionArgument)
+	}
+}
+
+func TestAccCidrNormalizeFunction(t *testing.T) {
+	testResource.Test(t, testResource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		TerraformVersionChecks:   []tfversion.TerraformVersionCheck{tfversion.SkipBelow(tfversion.Version1_8_0)},
+		Steps: []testResource.TestStep{
+			{
+				Config: testAccProviderConfig(t) + `
+output "test" {
+  value = provider::emma::cidr_normalize("10.1.2.3/8")
+}
+`,
+				Check: testResource.TestCheckOutput("test", "10.0.0.0/8"),
+			},
+		},
+	})
+}
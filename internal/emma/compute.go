@@ -0,0 +1,668 @@
+package emma
+
+import (
+	"context"
+	"fmt"
+	emmaSdk "github.com/emma-community/emma-go-sdk"
+	emma "github.com/emma-community/terraform-provider-emma/internal/emma/validation"
+	"github.com/emma-community/terraform-provider-emma/tools"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"maps"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	vmStatusBusy   = "BUSY"
+	vmPollInterval = 5 * time.Second
+	vmWaitTimeout  = 10 * time.Minute
+)
+
+// computeResourceModel describes the data model shared by the compute instances, the emma_vm and
+// emma_spot_instance resources embed it and add their own attributes.
+type computeResourceModel struct {
+	Id                         types.String `tfsdk:"id"`
+	Name                       types.String `tfsdk:"name"`
+	FullName                   types.String `tfsdk:"full_name"`
+	DataCenterId               types.String `tfsdk:"data_center_id"`
+	OsId                       types.Int64  `tfsdk:"os_id"`
+	CloudNetworkType           types.String `tfsdk:"cloud_network_type"`
+	VCpuType                   types.String `tfsdk:"vcpu_type"`
+	VCpu                       types.Int64  `tfsdk:"vcpu"`
+	RamGb                      types.Int64  `tfsdk:"ram_gb"`
+	VolumeType                 types.String `tfsdk:"volume_type"`
+	VolumeGb                   types.Int64  `tfsdk:"volume_gb"`
+	SshKeyId                   types.Int64  `tfsdk:"ssh_key_id"`
+	UserPassword               types.String `tfsdk:"user_password"`
+	SecurityGroupId            types.Int64  `tfsdk:"security_group_id"`
+	Status                     types.String `tfsdk:"status"`
+	Disks                      types.List   `tfsdk:"disks"`
+	Networks                   types.List   `tfsdk:"networks"`
+	Cost                       types.Object `tfsdk:"cost"`
+	Labels                     types.Map    `tfsdk:"labels"`
+	EffectiveLabels            types.Map    `tfsdk:"effective_labels"`
+	DeletionProtection         types.Bool   `tfsdk:"deletion_protection"`
+	AllowVolumeShrinkByReplace types.Bool   `tfsdk:"allow_volume_shrink_by_replace"`
+}
+
+type computeResourceDiskModel struct {
+	Id         types.Int64  `tfsdk:"id"`
+	SizeGb     types.Int64  `tfsdk:"size_gb"`
+	TypeId     types.Int64  `tfsdk:"type_id"`
+	Type_      types.String `tfsdk:"type"`
+	IsBootable types.Bool   `tfsdk:"is_bootable"`
+}
+
+type computeResourceNetworkModel struct {
+	Id            types.Int64  `tfsdk:"id"`
+	Ip            types.String `tfsdk:"ip"`
+	NetworkTypeId types.Int64  `tfsdk:"network_type_id"`
+	NetworkType   types.String `tfsdk:"network_type"`
+}
+
+type computeResourceCostModel struct {
+	Unit     types.String  `tfsdk:"unit"`
+	Currency types.String  `tfsdk:"currency"`
+	Price    types.Float64 `tfsdk:"price"`
+}
+
+// computeSchema builds the schema of the compute instance resource. The attributes of the resource are added
+// to the shared attributes, the attribute with the same name replaces the shared one.
+func computeSchema(resourceName string, description string, attributes map[string]schema.Attribute) schema.Schema {
+	computeAttributes := computeSchemaAttributes(resourceName)
+	maps.Copy(computeAttributes, attributes)
+	return schema.Schema{
+		Version:     1,
+		Description: description,
+		Attributes:  computeAttributes,
+	}
+}
+
+// computeReplacedAttributes are the shared attributes of the compute instance that replace it after changing them.
+var computeReplacedAttributes = path.Expressions{
+	path.MatchRoot("name"),
+	path.MatchRoot("data_center_id"),
+	path.MatchRoot("os_id"),
+	path.MatchRoot("cloud_network_type"),
+	path.MatchRoot("volume_type"),
+	path.MatchRoot("ssh_key_id"),
+	path.MatchRoot("user_password"),
+}
+
+// computeSchemaAttributes returns the shared attributes of the compute instance, the hardware of the compute
+// instance is recreated after changing it unless the resource replaces the hardware attributes.
+func computeSchemaAttributes(resourceName string) map[string]schema.Attribute {
+	recreated := fmt.Sprintf("%s will be recreated after changing this value", resourceName)
+	return map[string]schema.Attribute{
+		"id": schema.StringAttribute{
+			Description:   fmt.Sprintf("ID of the %s", resourceName),
+			Computed:      true,
+			PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+		},
+		"name": schema.StringAttribute{
+			Description:   fmt.Sprintf("Name of the %s, %s", resourceName, recreated),
+			Computed:      false,
+			Required:      true,
+			Optional:      false,
+			PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			Validators:    []validator.String{emma.NotEmptyString{}, emma.ResourceName{Rule: emma.ComputeNameRule}},
+		},
+		"full_name": schema.StringAttribute{
+			Description: fmt.Sprintf("Name of the %s in emma, the name with the prefix and the random suffix of the provider naming policy", resourceName),
+			Computed:    true,
+		},
+		"labels": schema.MapAttribute{
+			Description: fmt.Sprintf(labelsDescription, resourceName),
+			ElementType: types.StringType,
+			Computed:    false,
+			Required:    false,
+			Optional:    true,
+		},
+		"effective_labels": schema.MapAttribute{
+			Description: fmt.Sprintf(computeEffectiveLabelsDescription, resourceName),
+			ElementType: types.StringType,
+			Computed:    true,
+		},
+		"deletion_protection": schema.BoolAttribute{
+			Description: fmt.Sprintf(deletionProtectionDescription, resourceName, resourceName),
+			Computed:    false,
+			Required:    false,
+			Optional:    true,
+		},
+		"allow_volume_shrink_by_replace": schema.BoolAttribute{
+			Description: fmt.Sprintf(allowVolumeShrinkByReplaceDescription, resourceName),
+			Computed:    false,
+			Required:    false,
+			Optional:    true,
+		},
+		"data_center_id": schema.StringAttribute{
+			Description:   fmt.Sprintf("Data center ID of the %s, %s", resourceName, recreated),
+			Computed:      false,
+			Required:      true,
+			Optional:      false,
+			PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			Validators:    []validator.String{emma.NotEmptyString{}},
+		},
+		"os_id": schema.Int64Attribute{
+			Description:   fmt.Sprintf("Operating system ID of the %s, %s", resourceName, recreated),
+			Computed:      false,
+			Required:      true,
+			Optional:      false,
+			PlanModifiers: []planmodifier.Int64{int64planmodifier.RequiresReplace()},
+			Validators:    []validator.Int64{emma.PositiveInt64{}},
+		},
+		"cloud_network_type": schema.StringAttribute{
+			Description:   "Cloud network type, available values: multi-cloud, isolated or default, " + recreated,
+			Computed:      false,
+			Required:      true,
+			Optional:      false,
+			PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			Validators:    []validator.String{emma.CloudNetworkType{}},
+		},
+		"vcpu_type": schema.StringAttribute{
+			Description:   "Type of virtual Central Processing Units (vCPUs), available values: shared, standard or hpc, " + recreated,
+			Computed:      false,
+			Required:      true,
+			Optional:      false,
+			PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			Validators:    []validator.String{emma.VCpuType{}},
+		},
+		"vcpu": schema.Int64Attribute{
+			Description:   "Number of virtual Central Processing Units (vCPUs), " + recreated,
+			Computed:      false,
+			Required:      true,
+			Optional:      false,
+			PlanModifiers: []planmodifier.Int64{int64planmodifier.RequiresReplace()},
+			Validators:    []validator.Int64{emma.PositiveInt64{}},
+		},
+		"ram_gb": schema.Int64Attribute{
+			Description:   "Capacity of the RAM in gigabytes, " + recreated,
+			Required:      true,
+			Optional:      false,
+			PlanModifiers: []planmodifier.Int64{int64planmodifier.RequiresReplace()},
+			Validators:    []validator.Int64{emma.PositiveInt64{}},
+		},
+		"volume_type": schema.StringAttribute{
+			Description:   "Volume type of the compute instance, available values: ssd or ssd-plus, " + recreated,
+			Required:      true,
+			Optional:      false,
+			PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			Validators:    []validator.String{emma.VolumeType{}},
+		},
+		"volume_gb": schema.Int64Attribute{
+			Description:   "Volume size in gigabytes, " + recreated,
+			Required:      true,
+			Optional:      false,
+			PlanModifiers: []planmodifier.Int64{int64planmodifier.RequiresReplace()},
+			Validators:    []validator.Int64{emma.PositiveInt64{}},
+		},
+		"ssh_key_id": schema.Int64Attribute{
+			Description:   fmt.Sprintf("Ssh key ID of the %s, %s", resourceName, recreated),
+			Optional:      true,
+			PlanModifiers: []planmodifier.Int64{int64planmodifier.RequiresReplace()},
+			Validators:    []validator.Int64{emma.PositiveInt64{}},
+		},
+		"user_password": schema.StringAttribute{
+			Description:   fmt.Sprintf("User password of the %s, %s", resourceName, recreated),
+			Optional:      true,
+			PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			Validators:    []validator.String{emma.UserPassword{}},
+		},
+		"security_group_id": schema.Int64Attribute{
+			Description: fmt.Sprintf("Security group ID of the %s, the process of changing the security group will start after changing this value", resourceName),
+			Computed:    false,
+			Required:    false,
+			Optional:    true,
+			Validators:  []validator.Int64{emma.PositiveInt64{}},
+		},
+
+		"status": schema.StringAttribute{
+			Description: fmt.Sprintf("Status of the %s", resourceName),
+			Computed:    true,
+		},
+		"disks": schema.ListNestedAttribute{
+			Computed: true,
+			NestedObject: schema.NestedAttributeObject{
+				Attributes: map[string]schema.Attribute{
+					"id": schema.Int64Attribute{
+						Description: "Volume ID",
+						Computed:    true,
+					},
+					"size_gb": schema.Int64Attribute{
+						Description: "Volume size in gigabytes",
+						Computed:    true,
+					},
+					"type_id": schema.Int64Attribute{
+						Description: "ID of the volume type",
+						Computed:    true,
+					},
+					"type": schema.StringAttribute{
+						Description: "Volume type",
+						Computed:    true,
+					},
+					"is_bootable": schema.BoolAttribute{
+						Description: "Indicates whether the volume is bootable or not",
+						Computed:    true,
+					},
+				},
+			},
+		},
+		"networks": schema.ListNestedAttribute{
+			Computed: true,
+			NestedObject: schema.NestedAttributeObject{
+				Attributes: map[string]schema.Attribute{
+					"id": schema.Int64Attribute{
+						Description: "Network ID",
+						Computed:    true,
+					},
+					"ip": schema.StringAttribute{
+						Description: "Network IP",
+						Computed:    true,
+					},
+					"network_type_id": schema.Int64Attribute{
+						Description: "ID of the network type",
+						Computed:    true,
+					},
+					"network_type": schema.StringAttribute{
+						Description: "Network type",
+						Computed:    true,
+					},
+				},
+			},
+		},
+		"cost": schema.SingleNestedAttribute{
+			Computed: true,
+			Attributes: map[string]schema.Attribute{
+				"unit": schema.StringAttribute{
+					Description: "Cost period",
+					Computed:    true,
+				},
+				"currency": schema.StringAttribute{
+					Description: "Currency of cost",
+					Computed:    true,
+				},
+				"price": schema.Float64Attribute{
+					Description: fmt.Sprintf("Cost of the %s for the period", resourceName),
+					Computed:    true,
+				},
+			},
+		},
+	}
+}
+
+// computeUpdater applies the in-place changes of the compute instance in sequence: the security group, the volume
+// and the hardware. The state is saved after each step, so the state keeps the completed steps if a later step fails.
+type computeUpdater struct {
+	apiClient     *emmaSdk.APIClient
+	resourceName  string
+	defaultLabels map[string]string
+	naming        namingPolicy
+	// spot is true if the compute instance is a spot instance, it is read by the spot instances API
+	spot bool
+}
+
+// update applies the changes of the plan to stateData, the model is the resource data model that embeds stateData
+// and it is saved into the state after each step. The attributes of the resource that aren't shared are updated
+// and saved by the caller if update returns true.
+func (u computeUpdater) update(ctx context.Context, model any, stateData *computeResourceModel, planData *computeResourceModel,
+	resp *resource.UpdateResponse) bool {
+	capabilities := dataCenterCapabilities(stateData.DataCenterId.ValueString())
+	hardwareChanged := !planData.RamGb.Equal(stateData.RamGb) || !planData.VCpu.Equal(stateData.VCpu) || !planData.VCpuType.Equal(stateData.VCpuType)
+	volumeChanged := !planData.VolumeGb.Equal(stateData.VolumeGb)
+
+	vmId := tools.StringToInt32(stateData.Id.ValueString())
+	resp.Diagnostics.Append(resp.State.Set(ctx, model)...)
+
+	if !planData.SecurityGroupId.Equal(stateData.SecurityGroupId) {
+		if planData.SecurityGroupId.IsUnknown() || planData.SecurityGroupId.IsNull() {
+			stateData.SecurityGroupId = types.Int64Null()
+		} else {
+			securityGroupInstanceAdd := emmaSdk.SecurityGroupInstanceAdd{InstanceId: &vmId}
+			vm, response, err := u.apiClient.SecurityGroupsAPI.SecurityGroupInstanceAdd(ctx,
+				int32(planData.SecurityGroupId.ValueInt64())).SecurityGroupInstanceAdd(securityGroupInstanceAdd).Execute()
+			if err != nil {
+				resp.Diagnostics.AddError("Client Error",
+					fmt.Sprintf("Unable to add %s to security group, got error: %s", u.resourceName,
+						tools.ExtractErrorMessage(response)))
+				return false
+			}
+			ConvertComputeResponseToResource(ctx, u.naming, stateData, planData, vm, &resp.Diagnostics)
+		}
+		resp.Diagnostics.Append(resp.State.Set(ctx, model)...)
+		if resp.Diagnostics.HasError() {
+			return false
+		}
+	}
+
+	// the volume is resized by the volume action before the hardware is changed, unless the provider
+	// changes the volume together with the hardware
+	if !capabilities.combinedEdit && volumeChanged {
+		if !u.waitForReady(ctx, vmId, stateData, resp) {
+			return false
+		}
+		u.resizeVolume(ctx, stateData, resp, int32(planData.VolumeGb.ValueInt64()))
+		if resp.Diagnostics.HasError() {
+			return false
+		}
+		resp.Diagnostics.Append(resp.State.Set(ctx, model)...)
+	}
+
+	if hardwareChanged || (capabilities.combinedEdit && volumeChanged) {
+		if !u.waitForReady(ctx, vmId, stateData, resp) {
+			return false
+		}
+		// the volume is already resized or it is resized by the edit hardware action
+		u.editHardware(ctx, stateData, resp, planData)
+		if resp.Diagnostics.HasError() {
+			return false
+		}
+	}
+
+	// the name can't be changed in place, full_name of the state created before the naming policy is planned from the name
+	stateData.FullName = planData.FullName
+
+	// the emma API doesn't accept labels yet, they are stored in the state only
+	stateData.Labels = planData.Labels
+	applyEffectiveLabels(ctx, u.defaultLabels, planData.Labels, &stateData.EffectiveLabels, &resp.Diagnostics)
+
+	// deletion protection is enforced by the provider, it is stored in the state only
+	stateData.DeletionProtection = planData.DeletionProtection
+	stateData.AllowVolumeShrinkByReplace = planData.AllowVolumeShrinkByReplace
+	return true
+}
+
+// waitForReady polls the compute instance until the previous action is finished, emma rejects the next action
+// of the busy compute instance. The status of the compute instance is saved in the state.
+func (u computeUpdater) waitForReady(ctx context.Context, vmId int32, stateData *computeResourceModel, resp *resource.UpdateResponse) bool {
+	ctx, cancel := context.WithTimeout(ctx, vmWaitTimeout)
+	defer cancel()
+
+	for {
+		var vm *emmaSdk.Vm
+		var response *http.Response
+		var err error
+		if u.spot {
+			vm, response, err = u.apiClient.SpotInstancesAPI.GetSpot(ctx, vmId).Execute()
+		} else {
+			vm, response, err = u.apiClient.VirtualMachinesAPI.GetVm(ctx, vmId).Execute()
+		}
+		if ctx.Err() != nil {
+			resp.Diagnostics.AddError("Client Error",
+				fmt.Sprintf("Timed out waiting for %s %d: %s", u.resourceName, vmId, ctx.Err()))
+			return false
+		}
+		if err != nil {
+			resp.Diagnostics.AddError("Client Error",
+				fmt.Sprintf("Unable to read %s, got error: %s", u.resourceName,
+					tools.ExtractErrorMessage(response)))
+			return false
+		}
+		if vm.Status != nil && *vm.Status != vmStatusBusy {
+			stateData.Status = types.StringValue(*vm.Status)
+			return true
+		}
+
+		select {
+		case <-ctx.Done():
+			resp.Diagnostics.AddError("Client Error",
+				fmt.Sprintf("Timed out waiting for %s %d: %s", u.resourceName, vmId, ctx.Err()))
+			return false
+		case <-time.After(vmPollInterval):
+		}
+	}
+}
+
+func (u computeUpdater) resizeVolume(ctx context.Context, stateData *computeResourceModel, resp *resource.UpdateResponse, volumeGb int32) {
+	bootableDisk := GetBootableDisk(ctx, stateData, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if bootableDisk == nil {
+		resp.Diagnostics.AddError("Validation Error", "Bootable disk not found")
+		return
+	}
+	volumeEdit := emmaSdk.VolumeActionsRequest{VolumeEdit: emmaSdk.NewVolumeEdit("edit", volumeGb)}
+	volume, response, err := u.apiClient.VolumesAPI.VolumeActions(ctx, int32(bootableDisk.Id.ValueInt64())).VolumeActionsRequest(volumeEdit).Execute()
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error",
+			fmt.Sprintf("Unable to resize volume, got error: %s",
+				tools.ExtractErrorMessage(response)))
+		return
+	}
+
+	var updatedDisks []computeResourceDiskModel
+	disks := GetVolumesAsList(ctx, stateData, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if disks != nil {
+		for _, disk := range disks {
+			if disk.Id.ValueInt64() == int64(*volume.Id) {
+				updatedDisk := computeResourceDiskModel{
+					Id:         types.Int64Value(int64(*volume.Id)),
+					SizeGb:     types.Int64Value(int64(*volume.SizeGb)),
+					TypeId:     types.Int64Value(disk.TypeId.ValueInt64()),
+					Type_:      types.StringValue(*volume.Type),
+					IsBootable: types.BoolValue(*volume.IsSystem),
+				}
+				updatedDisks = append(updatedDisks, updatedDisk)
+			} else {
+				updatedDisks = append(updatedDisks, disk)
+			}
+		}
+	}
+
+	stateData.VolumeGb = types.Int64Value(int64(*volume.SizeGb))
+	disksListValue, disksDiagnostic := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: computeResourceDiskModel{}.attrTypes()}, updatedDisks)
+	stateData.Disks = disksListValue
+	resp.Diagnostics.Append(disksDiagnostic...)
+}
+
+func (u computeUpdater) editHardware(ctx context.Context, stateData *computeResourceModel, resp *resource.UpdateResponse, planData *computeResourceModel) {
+	vmActionEditHardwareRequest := emmaSdk.VmActionsRequest{}
+
+	vmEditHardware := emmaSdk.NewVmEditHardware("edithardware", int32(planData.VCpu.ValueInt64()),
+		int32(planData.RamGb.ValueInt64()), int32(planData.VolumeGb.ValueInt64()))
+	vmEditHardware.VCpuType = planData.VCpuType.ValueStringPointer()
+	vmActionEditHardwareRequest.VmEditHardware = vmEditHardware
+	vm, response, err := u.apiClient.VirtualMachinesAPI.VmActions(ctx,
+		tools.StringToInt32(stateData.Id.ValueString())).VmActionsRequest(vmActionEditHardwareRequest).Execute()
+
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error",
+			fmt.Sprintf("Unable to edit hardware of the %s, got error: %s", u.resourceName,
+				tools.ExtractErrorMessage(response)))
+		return
+	}
+
+	ConvertEditHardwareResponseToResource(ctx, stateData, planData, vm, &resp.Diagnostics)
+}
+
+func GetVolumesAsList(ctx context.Context, stateData *computeResourceModel, diagnostics *diag.Diagnostics) []computeResourceDiskModel {
+	var disks []computeResourceDiskModel
+	diskDiagnostics := stateData.Disks.ElementsAs(ctx, &disks, false)
+	if diskDiagnostics.HasError() {
+		diagnostics.Append(diskDiagnostics...)
+		return nil
+	}
+	return disks
+}
+
+func GetBootableDisk(ctx context.Context, stateData *computeResourceModel, diagnostics *diag.Diagnostics) *computeResourceDiskModel {
+	disks := GetVolumesAsList(ctx, stateData, diagnostics)
+	if disks == nil {
+		return nil
+	}
+
+	for _, disk := range disks {
+		if disk.IsBootable.ValueBool() {
+			return &disk
+		}
+	}
+
+	return nil
+}
+
+func ConvertToVmCreateRequest(data computeResourceModel, vmCreate *emmaSdk.VmCreate) {
+	vmCreate.Name = data.FullName.ValueString()
+	vmCreate.DataCenterId = data.DataCenterId.ValueString()
+	vmCreate.OsId = int32(data.OsId.ValueInt64())
+	vmCreate.CloudNetworkType = data.CloudNetworkType.ValueString()
+	vmCreate.VCpuType = data.VCpuType.ValueString()
+	vmCreate.VCpu = int32(data.VCpu.ValueInt64())
+	vmCreate.RamGb = int32(data.RamGb.ValueInt64())
+	vmCreate.VolumeType = data.VolumeType.ValueString()
+	vmCreate.VolumeGb = int32(data.VolumeGb.ValueInt64())
+
+	if !data.SecurityGroupId.IsUnknown() && !data.SecurityGroupId.IsNull() {
+		vmCreate.SecurityGroupId = tools.ToPointer(int32(data.SecurityGroupId.ValueInt64()))
+	}
+	if !data.UserPassword.IsUnknown() && !data.UserPassword.IsNull() {
+		vmCreate.UserPassword = tools.ToPointer(data.UserPassword.ValueString())
+	}
+	if !data.SshKeyId.IsUnknown() && !data.SshKeyId.IsNull() {
+		vmCreate.SshKeyId = tools.ToInt32PointerOrNil(data.SshKeyId)
+	}
+}
+
+func ConvertEditHardwareResponseToResource(ctx context.Context, stateData *computeResourceModel, planData *computeResourceModel, vm *emmaSdk.Vm, diags *diag.Diagnostics) {
+	stateData.Status = types.StringValue(*vm.Status)
+	convertComputeDetails(ctx, stateData, vm, diags)
+	if diags.HasError() {
+		return
+	}
+
+	stateData.VCpu = tools.GetInt64OrDefault(vm.VCpu, planData.VCpu)
+	stateData.VCpuType = types.StringPointerValue(vm.VCpuType)
+	if vm.VCpuType == nil {
+		stateData.VCpuType = planData.VCpuType
+	}
+	stateData.VolumeGb = planData.VolumeGb
+	stateData.RamGb = tools.GetInt64OrDefault(vm.RamGb, planData.RamGb)
+}
+
+func ConvertComputeResponseToResource(ctx context.Context, naming namingPolicy, stateData *computeResourceModel, planData *computeResourceModel, vm *emmaSdk.Vm, diags *diag.Diagnostics) {
+	stateData.Id = types.StringValue(strconv.Itoa(int(*vm.Id)))
+	stateData.Status = types.StringValue(*vm.Status)
+	stateData.Name, stateData.FullName = readName(naming, stateData.Name, stateData.FullName, *vm.Name)
+
+	for _, responseDisk := range vm.Disks {
+		if *responseDisk.IsBootable {
+			stateData.VolumeGb = types.Int64Value(int64(*responseDisk.SizeGb))
+			stateData.VolumeType = types.StringValue(*responseDisk.Type)
+		}
+	}
+	convertComputeDetails(ctx, stateData, vm, diags)
+	if diags.HasError() {
+		return
+	}
+	stateData.VCpu = types.Int64Value(int64(*vm.VCpu))
+	stateData.VCpuType = types.StringValue(*vm.VCpuType)
+
+	if vm.CloudNetworkType != nil {
+		stateData.CloudNetworkType = types.StringValue(*vm.CloudNetworkType)
+	}
+
+	if planData != nil && !planData.SecurityGroupId.IsUnknown() && !planData.SecurityGroupId.IsNull() {
+		stateData.SecurityGroupId = planData.SecurityGroupId
+	} else if !stateData.SecurityGroupId.IsUnknown() && !stateData.SecurityGroupId.IsNull() {
+		stateData.SecurityGroupId = types.Int64Value(int64(*vm.SecurityGroup.Id))
+	}
+
+	stateData.RamGb = types.Int64Value(int64(*vm.RamGb))
+	stateData.OsId = types.Int64Value(int64(*vm.Os.Id))
+	if vm.DataCenter != nil {
+		stateData.DataCenterId = types.StringValue(*vm.DataCenter.Id)
+	}
+	if vm.UserPassword != nil {
+		stateData.UserPassword = types.StringValue(*vm.UserPassword)
+	}
+	if vm.SshKeyId != nil {
+		stateData.SshKeyId = types.Int64Value(int64(*vm.SshKeyId))
+	}
+}
+
+// convertComputeDetails converts the cost, the disks and the networks of the compute instance.
+func convertComputeDetails(ctx context.Context, stateData *computeResourceModel, vm *emmaSdk.Vm, diags *diag.Diagnostics) {
+	computeResourceCost := computeResourceCostModel{
+		Price:    types.Float64Value(float64(*vm.Cost.Price)),
+		Currency: types.StringValue(*vm.Cost.Currency),
+		Unit:     types.StringValue(*vm.Cost.Unit),
+	}
+
+	costObjectValue, costDiagnostic := types.ObjectValueFrom(ctx, computeResourceCostModel{}.attrTypes(), computeResourceCost)
+	stateData.Cost = costObjectValue
+	diags.Append(costDiagnostic...)
+	if diags.HasError() {
+		return
+	}
+
+	var disks []computeResourceDiskModel
+	for _, responseDisk := range vm.Disks {
+		disk := computeResourceDiskModel{
+			Id:         types.Int64Value(int64(*responseDisk.Id)),
+			Type_:      types.StringValue(*responseDisk.Type),
+			TypeId:     types.Int64Value(int64(*responseDisk.TypeId)),
+			SizeGb:     types.Int64Value(int64(*responseDisk.SizeGb)),
+			IsBootable: types.BoolValue(*responseDisk.IsBootable),
+		}
+		disks = append(disks, disk)
+	}
+	disksListValue, disksDiagnostic := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: computeResourceDiskModel{}.attrTypes()}, disks)
+	stateData.Disks = disksListValue
+	diags.Append(disksDiagnostic...)
+	if diags.HasError() {
+		return
+	}
+
+	var networks []computeResourceNetworkModel
+	for _, responseNetwork := range vm.Networks {
+		network := computeResourceNetworkModel{
+			Id:            types.Int64Value(int64(*responseNetwork.Id)),
+			Ip:            types.StringPointerValue(responseNetwork.Ip),
+			NetworkTypeId: types.Int64Value(int64(*responseNetwork.NetworkTypeId)),
+			NetworkType:   types.StringValue(*responseNetwork.NetworkType),
+		}
+		networks = append(networks, network)
+	}
+	networksListValue, networksDiagnostic := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: computeResourceNetworkModel{}.attrTypes()}, networks)
+	stateData.Networks = networksListValue
+	diags.Append(networksDiagnostic...)
+}
+
+func (o computeResourceCostModel) attrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"unit":     types.StringType,
+		"currency": types.StringType,
+		"price":    types.Float64Type,
+	}
+}
+
+func (o computeResourceDiskModel) attrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"id":          types.Int64Type,
+		"size_gb":     types.Int64Type,
+		"type_id":     types.Int64Type,
+		"type":        types.StringType,
+		"is_bootable": types.BoolType,
+	}
+}
+
+func (o computeResourceNetworkModel) attrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"id":              types.Int64Type,
+		"ip":              types.StringType,
+		"network_type_id": types.Int64Type,
+		"network_type":    types.StringType,
+	}
+}
@@ -0,0 +1,126 @@
+package emma
+
+import (
+	"context"
+	emmaSdk "github.com/emma-community/emma-go-sdk"
+	"github.com/emma-community/terraform-provider-emma/internal/mock"
+	"github.com/emma-community/terraform-provider-emma/tools"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func TestComputeSchema(t *testing.T) {
+	var vmSchema, spotSchema resource.SchemaResponse
+	NewVmResource().Schema(context.Background(), resource.SchemaRequest{}, &vmSchema)
+	NewSpotInstanceResource().Schema(context.Background(), resource.SchemaRequest{}, &spotSchema)
+
+	// the shared attributes are in both schemas
+	for name := range computeSchemaAttributes("compute instance") {
+		assert.Contains(t, vmSchema.Schema.Attributes, name)
+		assert.Contains(t, spotSchema.Schema.Attributes, name)
+	}
+	assert.Equal(t, "ID of the virtual machine", vmSchema.Schema.Attributes["id"].GetDescription())
+	assert.Equal(t, "ID of the spot instance", spotSchema.Schema.Attributes["id"].GetDescription())
+
+	// the hardware of the virtual machine is edited in place, the spot instance is recreated
+	assert.Empty(t, vmSchema.Schema.Attributes["vcpu"].(schema.Int64Attribute).PlanModifiers)
+	assert.NotEmpty(t, spotSchema.Schema.Attributes["vcpu"].(schema.Int64Attribute).PlanModifiers)
+
+	// the spot-specific attributes are added to the spot instance only
+	for _, name := range []string{"price", "on_interruption", "on_demand"} {
+		assert.NotContains(t, vmSchema.Schema.Attributes, name)
+		assert.Contains(t, spotSchema.Schema.Attributes, name)
+	}
+}
+
+func TestConvertComputeResponseToResource(t *testing.T) {
+	vm := &emmaSdk.Vm{
+		Id:       tools.ToPointer(int32(1001)),
+		Name:     tools.ToPointer("example"),
+		Status:   tools.ToPointer("ACTIVE"),
+		VCpu:     tools.ToPointer(int32(2)),
+		VCpuType: tools.ToPointer("shared"),
+		RamGb:    tools.ToPointer(int32(4)),
+		Os:       &emmaSdk.VmOs{Id: tools.ToPointer(int32(2))},
+		Cost: &emmaSdk.VmCost{
+			Unit:     tools.ToPointer("hour"),
+			Currency: tools.ToPointer("EUR"),
+			Price:    tools.ToPointer(float32(0.5)),
+		},
+		Disks: []emmaSdk.KubernetesNodeGroupsInnerNodesInnerDisksInner{{
+			Id:         tools.ToPointer(int32(5001)),
+			SizeGb:     tools.ToPointer(int32(16)),
+			TypeId:     tools.ToPointer(int32(1)),
+			Type:       tools.ToPointer("ssd"),
+			IsBootable: tools.ToPointer(true),
+		}},
+	}
+
+	var diags diag.Diagnostics
+	var vmData vmResourceModel
+	ConvertComputeResponseToResource(context.Background(), namingPolicy{}, &vmData.computeResourceModel, nil, vm, &diags)
+
+	var spotData spotInstanceResourceModel
+	planData := spotInstanceResourceModel{Price: types.Float64Value(0.2)}
+	ConvertSpotInstanceResponseToResource(context.Background(), namingPolicy{}, &spotData, &planData, vm, &diags)
+
+	assert.False(t, diags.HasError(), diags)
+	assert.Equal(t, vmData.computeResourceModel, spotData.computeResourceModel)
+	assert.Equal(t, "1001", vmData.Id.ValueString())
+	assert.Equal(t, int64(16), vmData.VolumeGb.ValueInt64())
+	assert.Equal(t, "ssd", vmData.VolumeType.ValueString())
+	assert.Equal(t, 0.2, spotData.Price.ValueFloat64())
+}
+
+func TestGetBootableDiskError(t *testing.T) {
+	// the disks of the state that don't match the disk model
+	stateData := computeResourceModel{Disks: types.ListValueMust(types.StringType, []attr.Value{types.StringValue("disk")})}
+
+	var diags diag.Diagnostics
+	assert.Nil(t, GetBootableDisk(context.Background(), &stateData, &diags))
+	assert.True(t, diags.HasError())
+
+	// the error is returned by the volume resize of the update
+	var resp resource.UpdateResponse
+	computeUpdater{resourceName: "virtual machine"}.resizeVolume(context.Background(), &stateData, &resp, 32)
+	require.True(t, resp.Diagnostics.HasError())
+	assert.NotContains(t, resp.Diagnostics.Errors()[0].Detail(), "Bootable disk not found")
+}
+
+func TestComputeUpdaterWaitForReadySpot(t *testing.T) {
+	server, err := mock.NewServer("")
+	require.NoError(t, err)
+	configuration := emmaSdk.NewConfiguration()
+	configuration.HTTPClient = &http.Client{Transport: &mock.Transport{Handler: server}}
+	apiClient := emmaSdk.NewAPIClient(configuration)
+	token, _, err := apiClient.AuthenticationAPI.IssueToken(context.Background()).
+		Credentials(emmaSdk.Credentials{ClientId: "id", ClientSecret: "secret"}).Execute()
+	require.NoError(t, err)
+	auth := context.WithValue(context.Background(), emmaSdk.ContextAccessToken, *token.AccessToken)
+
+	spot, _, err := apiClient.SpotInstancesAPI.SpotCreate(auth).SpotCreate(emmaSdk.SpotCreate{
+		Name: "spot", DataCenterId: "aws-eu-central-1", OsId: 3, CloudNetworkType: "multi-cloud", VCpuType: "shared",
+		VCpu: 2, RamGb: 4, VolumeType: "ssd", VolumeGb: 16, UserPassword: emmaSdk.PtrString("password"), Price: 0.05,
+	}).Execute()
+	require.NoError(t, err)
+
+	// the spot instance isn't found by the virtual machines API
+	var stateData computeResourceModel
+	var resp resource.UpdateResponse
+	updater := computeUpdater{apiClient: apiClient, resourceName: "spot instance"}
+	assert.False(t, updater.waitForReady(auth, *spot.Id, &stateData, &resp))
+	assert.True(t, resp.Diagnostics.HasError())
+
+	resp = resource.UpdateResponse{}
+	updater.spot = true
+	assert.True(t, updater.waitForReady(auth, *spot.Id, &stateData, &resp))
+	assert.False(t, resp.Diagnostics.HasError(), resp.Diagnostics)
+	assert.NotEmpty(t, stateData.Status.ValueString())
+}
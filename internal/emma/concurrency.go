@@ -0,0 +1,110 @@
+package emma
+
+import (
+	"context"
+	"github.com/emma-community/terraform-provider-emma/tools"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// limitedTransport limits the number of concurrent requests of the emma API client.
+type limitedTransport struct {
+	base      http.RoundTripper
+	semaphore *tools.Semaphore
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if err := acquireSlot(ctx, t.semaphore, "emma API request", map[string]interface{}{
+		"method": req.Method,
+		"path":   req.URL.Path,
+	}); err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		t.semaphore.Release()
+		return nil, err
+	}
+	// the slot is released after the body is read and closed by the client
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: t.semaphore.Release}
+	return resp, nil
+}
+
+// releasingBody releases the slot of the request once the body of the response is closed.
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// newLimitedHttpClient returns the http client of the emma API client that sends at most maxConcurrentRequests
+// requests at a time with the base round tripper, the requests aren't limited if maxConcurrentRequests is 0.
+func newLimitedHttpClient(base http.RoundTripper, maxConcurrentRequests int) *http.Client {
+	if maxConcurrentRequests == 0 {
+		return &http.Client{Transport: base}
+	}
+	return &http.Client{Transport: &limitedTransport{
+		base:      base,
+		semaphore: tools.NewSemaphore(maxConcurrentRequests),
+	}}
+}
+
+// operationLimiter limits the number of concurrent expensive operations, like creating virtual machines,
+// separately for each operation. The operations aren't limited if the limit is 0.
+type operationLimiter struct {
+	limit      int
+	mutex      sync.Mutex
+	semaphores map[string]*tools.Semaphore
+}
+
+func newOperationLimiter(limit int) *operationLimiter {
+	return &operationLimiter{limit: limit, semaphores: make(map[string]*tools.Semaphore)}
+}
+
+// acquire waits until the operation can be started and returns the function that finishes the operation.
+func (l *operationLimiter) acquire(ctx context.Context, operation string) (func(), error) {
+	if l.limit == 0 {
+		return func() {}, nil
+	}
+	l.mutex.Lock()
+	semaphore, ok := l.semaphores[operation]
+	if !ok {
+		semaphore = tools.NewSemaphore(l.limit)
+		l.semaphores[operation] = semaphore
+	}
+	l.mutex.Unlock()
+
+	if err := acquireSlot(ctx, semaphore, operation, nil); err != nil {
+		return nil, err
+	}
+	return semaphore.Release, nil
+}
+
+// acquireSlot acquires a slot of the semaphore and logs the time spent in the queue if there was no free slot.
+func acquireSlot(ctx context.Context, semaphore *tools.Semaphore, name string, fields map[string]interface{}) error {
+	if semaphore.TryAcquire() {
+		return nil
+	}
+
+	ctx = tflog.SetField(ctx, "limit", semaphore.Limit())
+	for key, value := range fields {
+		ctx = tflog.SetField(ctx, key, value)
+	}
+	tflog.Info(ctx, "Queued "+name+", waiting for a free slot")
+	start := time.Now()
+	if err := semaphore.Acquire(ctx); err != nil {
+		return err
+	}
+	tflog.Info(ctx, "Dequeued "+name, map[string]interface{}{"waited": time.Since(start).String()})
+	return nil
+}
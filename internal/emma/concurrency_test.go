@@ -0,0 +1,176 @@
+package emma
+
+import (
+	"context"
+	emmaSdk "github.com/emma-community/emma-go-sdk"
+	"github.com/emma-community/terraform-provider-emma/internal/mock"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// concurrencyCounter counts the concurrent calls and remembers the maximum.
+type concurrencyCounter struct {
+	current atomic.Int32
+	max     atomic.Int32
+}
+
+func (c *concurrencyCounter) call(duration time.Duration) {
+	current := c.current.Add(1)
+	for {
+		max := c.max.Load()
+		if current <= max || c.max.CompareAndSwap(max, current) {
+			break
+		}
+	}
+	time.Sleep(duration)
+	c.current.Add(-1)
+}
+
+// configureConcurrencyTestProvider configures the provider with the concurrency limits against the stub of the emma
+// API, the requests of the data centers are counted by the counter.
+func configureConcurrencyTestProvider(t *testing.T, maxConcurrentRequests types.Int64, maxConcurrentCreates types.Int64,
+	counter *concurrencyCounter) *Client {
+	ctx := context.Background()
+	mockServer, err := mock.NewServer("")
+	require.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/data-centers") {
+			counter.call(50 * time.Millisecond)
+		}
+		mockServer.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	p := &Provider{}
+	var schemaResp provider.SchemaResponse
+	p.Schema(ctx, provider.SchemaRequest{}, &schemaResp)
+	config := providerModel{
+		Host:                  types.StringValue(server.URL),
+		ClientId:              types.StringValue("client-id"),
+		ClientSecret:          types.StringValue("client-secret"),
+		AllowedSshKeyTypes:    types.ListNull(types.StringType),
+		DefaultLabels:         types.MapNull(types.StringType),
+		MaxConcurrentRequests: maxConcurrentRequests,
+		MaxConcurrentCreates:  maxConcurrentCreates,
+	}
+	configState := tfsdk.State{Schema: schemaResp.Schema}
+	require.False(t, configState.Set(ctx, &config).HasError())
+
+	var configureResp provider.ConfigureResponse
+	p.Configure(ctx, provider.ConfigureRequest{Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: configState.Raw}}, &configureResp)
+	require.False(t, configureResp.Diagnostics.HasError(), configureResp.Diagnostics)
+	return configureResp.ResourceData.(*Client)
+}
+
+// runConcurrently runs the function the number of times at once and waits for them.
+func runConcurrently(count int, f func()) {
+	var wg sync.WaitGroup
+	for range count {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f()
+		}()
+	}
+	wg.Wait()
+}
+
+func TestProviderMaxConcurrentRequests(t *testing.T) {
+	for _, test := range []struct {
+		maxConcurrentRequests types.Int64
+		expectedMax           int32
+	}{
+		{types.Int64Value(2), 2},
+		// the requests aren't limited by default
+		{types.Int64Null(), 6},
+	} {
+		var counter concurrencyCounter
+		client := configureConcurrencyTestProvider(t, test.maxConcurrentRequests, types.Int64Null(), &counter)
+		auth := context.WithValue(context.Background(), emmaSdk.ContextAccessToken, *client.token.AccessToken)
+
+		runConcurrently(6, func() {
+			_, _, err := client.apiClient.DataCentersAPI.GetDataCenters(auth).Execute()
+			assert.NoError(t, err)
+		})
+		assert.Equal(t, test.expectedMax, counter.max.Load(), test.maxConcurrentRequests.String())
+	}
+}
+
+// bodyTransport returns the responses with an empty list, without sending the requests.
+type bodyTransport struct{}
+
+func (bodyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("[]")), Request: req}, nil
+}
+
+func TestLimitedTransportReleasesSlotOnBodyClose(t *testing.T) {
+	client := newLimitedHttpClient(bodyTransport{}, 1)
+	request, err := http.NewRequest(http.MethodGet, "https://api.emma.ms/external/v1/data-centers", nil)
+	require.NoError(t, err)
+	resp, err := client.Do(request)
+	require.NoError(t, err)
+
+	// the next request waits while the body of the first response is open
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = client.Do(request.WithContext(ctx))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	require.NoError(t, resp.Body.Close())
+	// closing the body again doesn't release another slot
+	require.NoError(t, resp.Body.Close())
+	other, err := client.Do(request)
+	require.NoError(t, err)
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = client.Do(request.WithContext(ctx))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.NoError(t, other.Body.Close())
+}
+
+func TestProviderMaxConcurrentCreates(t *testing.T) {
+	for _, test := range []struct {
+		maxConcurrentCreates types.Int64
+		expectedMax          int32
+	}{
+		{types.Int64Value(1), 1},
+		// the creations aren't limited by default
+		{types.Int64Null(), 4},
+	} {
+		var counter concurrencyCounter
+		client := configureConcurrencyTestProvider(t, types.Int64Null(), test.maxConcurrentCreates, &concurrencyCounter{})
+
+		runConcurrently(4, func() {
+			release, err := client.operations.acquire(context.Background(), "vm create")
+			require.NoError(t, err)
+			defer release()
+			counter.call(20 * time.Millisecond)
+		})
+		assert.Equal(t, test.expectedMax, counter.max.Load(), test.maxConcurrentCreates.String())
+	}
+
+	// the operations are limited separately
+	client := configureConcurrencyTestProvider(t, types.Int64Null(), types.Int64Value(1), &concurrencyCounter{})
+	release, err := client.operations.acquire(context.Background(), "vm create")
+	require.NoError(t, err)
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	otherRelease, err := client.operations.acquire(ctx, "spot create")
+	require.NoError(t, err)
+	otherRelease()
+	_, err = client.operations.acquire(ctx, "vm create")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -4,11 +4,12 @@ import (
 	"context"
 	"fmt"
 	emmaSdk "github.com/emma-community/emma-go-sdk"
-	"github.com/emma-community/terraform-provider-emma/tools"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"net/url"
+	"strconv"
 )
 
 var _ datasource.DataSource = &dataCenterDataSource{}
@@ -21,6 +22,7 @@ func NewDataCenterDataSource() datasource.DataSource {
 type dataCenterDataSource struct {
 	apiClient  *emmaSdk.APIClient
 	token      *emmaSdk.Token
+	catalog    *catalogCache
 	LocationID *int64
 }
 
@@ -32,6 +34,7 @@ type dataCenterDataSourceModel struct {
 	ProviderId   types.Int64  `tfsdk:"provider_id"`
 	LocationId   types.Int64  `tfsdk:"location_id"`
 	LocationName types.String `tfsdk:"location_name"`
+	Ipv6         types.Bool   `tfsdk:"ipv6"`
 }
 
 func (d *dataCenterDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -72,6 +75,10 @@ func (d *dataCenterDataSource) Schema(ctx context.Context, req datasource.Schema
 				Description: "Name of the data center location (city or state)",
 				Computed:    true,
 			},
+			"ipv6": schema.BoolAttribute{
+				Description: "Whether the networks of the cloud provider that owns the data center support IPv6 addresses",
+				Computed:    true,
+			},
 		},
 	}
 }
@@ -90,6 +97,7 @@ func (d *dataCenterDataSource) Configure(ctx context.Context, req datasource.Con
 	}
 	d.apiClient = client.apiClient
 	d.token = client.token
+	d.catalog = client.catalog
 }
 
 func (d *dataCenterDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
@@ -106,22 +114,26 @@ func (d *dataCenterDataSource) Read(ctx context.Context, req datasource.ReadRequ
 	// If applicable, this is a great opportunity to initialize any necessary
 	// provider client data and make a call using it.
 	auth := context.WithValue(ctx, emmaSdk.ContextAccessToken, *d.token.AccessToken)
+
 	request := d.apiClient.DataCentersAPI.GetDataCenters(auth)
+	query := url.Values{}
 	if !data.LocationId.IsUnknown() && !data.LocationId.IsNull() {
 		request = request.LocationId(int32(data.LocationId.ValueInt64()))
+		query.Set("locationId", strconv.FormatInt(data.LocationId.ValueInt64(), 10))
 	}
 	if !data.ProviderName.IsUnknown() && !data.ProviderName.IsNull() {
 		request = request.ProviderName(data.ProviderName.ValueString())
+		query.Set("providerName", data.ProviderName.ValueString())
 	}
 	if !data.Name.IsUnknown() && !data.Name.IsNull() {
 		request = request.DataCenterName(data.Name.ValueString())
+		query.Set("dataCenterName", data.Name.ValueString())
 	}
-	dataCenters, response, err := request.Execute()
+	dataCenters, err := readCatalog(ctx, d.catalog, "/v1/data-centers", query, request.Execute)
 
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error",
-			fmt.Sprintf("Unable to read data center, got error: %s",
-				tools.ExtractErrorMessage(response)))
+			fmt.Sprintf("Unable to read data center, got error: %s", err))
 		return
 	}
 
@@ -147,4 +159,5 @@ func ConvertDataCenter(dataCenterModel *dataCenterDataSourceModel, dataCenter *e
 	dataCenterModel.ProviderId = types.Int64Value(int64(*dataCenter.ProviderId))
 	dataCenterModel.LocationId = types.Int64Value(int64(*dataCenter.LocationId))
 	dataCenterModel.LocationName = types.StringValue(*dataCenter.LocationName)
+	dataCenterModel.Ipv6 = types.BoolValue(providerCapabilities(*dataCenter.ProviderName).ipv6)
 }
@@ -0,0 +1,30 @@
+package emma
+
+import (
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"testing"
+)
+
+func TestAccDataCenterDataSource(t *testing.T) {
+	providerConfig := testAccProviderConfig(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+data "emma_data_center" "test" {
+  name          = "ams3"
+  provider_name = "DigitalOcean"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.emma_data_center.test", "id", "digitalocean-ams3"),
+					resource.TestCheckResourceAttr("data.emma_data_center.test", "provider_id", "4"),
+					resource.TestCheckResourceAttr("data.emma_data_center.test", "location_name", "Amsterdam"),
+					resource.TestCheckResourceAttr("data.emma_data_center.test", "ipv6", "true"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,69 @@
+package emma
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// deletionProtectionDescription is the description of the deletion_protection attribute, emma doesn't have
+// a protection flag, so the protection is enforced by the provider.
+const deletionProtectionDescription = "Whether Terraform is prevented from destroying or replacing the %s. " +
+	"The protection is enforced by the provider, set it to false and apply the change before destroying or replacing the %s"
+
+// checkDeletionProtection fails the plan that destroys or replaces the protected resource. The resource ModifyPlan
+// doesn't receive the attributes replaced by the attribute plan modifiers, so the replaced attributes of the resource
+// are compared with the state here, the attributes added to the state by the plan, like new list elements, aren't
+// replaced.
+func checkDeletionProtection(ctx context.Context, resourceName string, replacedAttributes path.Expressions,
+	req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// the resource is created
+	if req.State.Raw.IsNull() {
+		return
+	}
+
+	var deletionProtection types.Bool
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("deletion_protection"), &deletionProtection)...)
+	if resp.Diagnostics.HasError() || !deletionProtection.ValueBool() {
+		return
+	}
+
+	if req.Plan.Raw.IsNull() {
+		addDeletionProtectionError(resourceName, "destroy", &resp.Diagnostics)
+	} else if len(resp.RequiresReplace) > 0 || changesAttributes(ctx, req, replacedAttributes, &resp.Diagnostics) {
+		addDeletionProtectionError(resourceName, "replace", &resp.Diagnostics)
+	}
+}
+
+// changesAttributes returns true if the plan changes an attribute of the state matched by the expressions, the
+// expressions can match the nested attributes and the elements of the lists, sets and maps.
+func changesAttributes(ctx context.Context, req resource.ModifyPlanRequest, expressions path.Expressions, diags *diag.Diagnostics) bool {
+	for _, expression := range expressions {
+		attributePaths, pathDiags := req.State.PathMatches(ctx, expression)
+		diags.Append(pathDiags...)
+		if diags.HasError() {
+			return false
+		}
+		for _, attributePath := range attributePaths {
+			var stateValue, planValue attr.Value
+			diags.Append(req.State.GetAttribute(ctx, attributePath, &stateValue)...)
+			diags.Append(req.Plan.GetAttribute(ctx, attributePath, &planValue)...)
+			if diags.HasError() {
+				return false
+			}
+			if !planValue.Equal(stateValue) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func addDeletionProtectionError(resourceName string, action string, diags *diag.Diagnostics) {
+	diags.AddAttributeError(path.Root("deletion_protection"), "Deletion Protection",
+		fmt.Sprintf("Unable to %s %s: deletion_protection is true, set it to false and apply the change first", action, resourceName))
+}
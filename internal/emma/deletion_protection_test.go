@@ -0,0 +1,227 @@
+package emma
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// protectedVmState returns the state of the virtual machine in testdata/state_v0 with the deletion protection.
+func protectedVmState(t *testing.T) vmResourceModel {
+	var vmData vmResourceModel
+	state := upgradeStateFixture(t, NewVmResource(), "vm.json")
+	require.False(t, state.Get(context.Background(), &vmData).HasError())
+	vmData.FullName = vmData.Name
+	vmData.DeletionProtection = types.BoolValue(true)
+	vmData.AllowVolumeShrinkByReplace = types.BoolValue(false)
+	return vmData
+}
+
+// planVmChange plans the change of the virtual machine through the provider server, a nil plan destroys it.
+func planVmChange(t *testing.T, stateData vmResourceModel, planData *vmResourceModel, private []byte) *tfprotov6.PlanResourceChangeResponse {
+	if planData == nil {
+		return planResourceChange(t, NewVmResource(), "emma_vm", &stateData, nil, private)
+	}
+	return planResourceChange(t, NewVmResource(), "emma_vm", &stateData, planData, private)
+}
+
+// planResourceChange plans the change of the resource through the provider server, a nil plan destroys it.
+func planResourceChange(t *testing.T, r resource.Resource, typeName string, stateData any, planData any,
+	private []byte) *tfprotov6.PlanResourceChangeResponse {
+	ctx := context.Background()
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	objectType := schemaResp.Schema.Type().TerraformType(ctx)
+
+	dynamicValue := func(data any) *tfprotov6.DynamicValue {
+		raw := tftypes.NewValue(objectType, nil)
+		if data != nil {
+			state := tfsdk.State{Schema: schemaResp.Schema, Raw: raw}
+			require.False(t, state.Set(ctx, data).HasError())
+			raw = state.Raw
+		}
+		value, err := tfprotov6.NewDynamicValue(objectType, raw)
+		require.NoError(t, err)
+		return &value
+	}
+
+	server, err := testAccProtoV6ProviderFactories["emma"]()
+	require.NoError(t, err)
+	resp, err := server.PlanResourceChange(ctx, &tfprotov6.PlanResourceChangeRequest{
+		TypeName:         typeName,
+		PriorState:       dynamicValue(stateData),
+		ProposedNewState: dynamicValue(planData),
+		Config:           dynamicValue(planData),
+		PriorPrivate:     private,
+	})
+	require.NoError(t, err)
+	return resp
+}
+
+func assertDeletionProtectionError(t *testing.T, resp *tfprotov6.PlanResourceChangeResponse, action string) {
+	require.Len(t, resp.Diagnostics, 1, resp.Diagnostics)
+	assert.Equal(t, tfprotov6.DiagnosticSeverityError, resp.Diagnostics[0].Severity)
+	assert.Equal(t, "Unable to "+action+" virtual machine: deletion_protection is true, set it to false and apply the change first",
+		resp.Diagnostics[0].Detail)
+}
+
+func TestDeletionProtectionDestroy(t *testing.T) {
+	assertDeletionProtectionError(t, planVmChange(t, protectedVmState(t), nil, nil), "destroy")
+}
+
+func TestDeletionProtectionSchemaReplace(t *testing.T) {
+	// the attributes with the RequiresReplace plan modifier of the schema
+	changes := map[string]func(data *vmResourceModel){
+		"name":               func(data *vmResourceModel) { data.Name = types.StringValue("renamed") },
+		"data_center_id":     func(data *vmResourceModel) { data.DataCenterId = types.StringValue("aws-eu-west-1") },
+		"os_id":              func(data *vmResourceModel) { data.OsId = types.Int64Value(3) },
+		"cloud_network_type": func(data *vmResourceModel) { data.CloudNetworkType = types.StringValue("isolated") },
+		"volume_type":        func(data *vmResourceModel) { data.VolumeType = types.StringValue("ssd-plus") },
+		"ssh_key_id":         func(data *vmResourceModel) { data.SshKeyId = types.Int64Value(3002) },
+		"user_password":      func(data *vmResourceModel) { data.UserPassword = types.StringValue("Password1!") },
+	}
+	for attribute, change := range changes {
+		t.Run(attribute, func(t *testing.T) {
+			stateData := protectedVmState(t)
+			planData := stateData
+			change(&planData)
+
+			assertDeletionProtectionError(t, planVmChange(t, stateData, &planData, nil), "replace")
+
+			// the unprotected virtual machine is replaced
+			stateData.DeletionProtection = types.BoolValue(false)
+			planData.DeletionProtection = types.BoolValue(false)
+			resp := planVmChange(t, stateData, &planData, nil)
+			assert.Empty(t, resp.Diagnostics)
+			assert.Contains(t, resp.RequiresReplace, tftypes.NewAttributePath().WithAttributeName(attribute))
+		})
+	}
+}
+
+func TestDeletionProtectionModifyPlanReplace(t *testing.T) {
+	t.Run("volume_shrink", func(t *testing.T) {
+		stateData := protectedVmState(t)
+		planData := stateData
+		planData.VolumeGb = types.Int64Value(8)
+		planData.AllowVolumeShrinkByReplace = types.BoolValue(true)
+		assertDeletionProtectionError(t, planVmChange(t, stateData, &planData, nil), "replace")
+	})
+
+	t.Run("vcpu_type", func(t *testing.T) {
+		stateData := protectedVmState(t)
+		stateData.DataCenterId = types.StringValue("digitalocean-ams3")
+		planData := stateData
+		planData.VCpuType = types.StringValue("standard")
+		assertDeletionProtectionError(t, planVmChange(t, stateData, &planData, nil), "replace")
+	})
+
+	t.Run("moved_spot_instance", func(t *testing.T) {
+		spotInstanceState, err := os.ReadFile(filepath.Join("testdata", "state_v0", "spot_instance.json"))
+		require.NoError(t, err)
+		moveResp, stateData := moveSpotInstanceState(t, "registry.terraform.io/emma-community/emma", spotInstanceState)
+		stateData.FullName = stateData.Name
+		stateData.DeletionProtection = types.BoolValue(true)
+		stateData.AllowVolumeShrinkByReplace = types.BoolValue(false)
+		planData := stateData
+		assertDeletionProtectionError(t, planVmChange(t, stateData, &planData, moveResp.TargetPrivate), "replace")
+	})
+}
+
+func TestDeletionProtectionUpdate(t *testing.T) {
+	stateData := protectedVmState(t)
+	planData := stateData
+	planData.RamGb = types.Int64Value(8)
+
+	resp := planVmChange(t, stateData, &planData, nil)
+	assert.Empty(t, resp.Diagnostics)
+	assert.Empty(t, resp.RequiresReplace)
+}
+
+func TestDeletionProtectionKubernetesWorkerNodes(t *testing.T) {
+	protectedKubernetesState := func(t *testing.T) kubernetesModel {
+		var kubernetesData kubernetesModel
+		state := upgradeStateFixture(t, NewKubernetesResource(), "kubernetes_cluster.json")
+		require.False(t, state.Get(context.Background(), &kubernetesData).HasError())
+		kubernetesData.FullName = kubernetesData.Name
+		kubernetesData.DeletionProtection = types.BoolValue(true)
+		kubernetesData.AllowVolumeShrinkByReplace = types.BoolValue(false)
+		return kubernetesData
+	}
+	newWorkerNode := func(name string) kubernetesWorkerNodeModel {
+		return kubernetesWorkerNodeModel{Id: types.Int64Unknown(), Name: types.StringValue(name), GeneratedName: types.StringUnknown(),
+			DataCenterID: types.StringValue("aws-eu-central-1"), VCpuType: types.StringValue("shared"), VCpu: types.Int64Value(2),
+			RamGb: types.Int64Value(4), VolumeType: types.StringValue("ssd"), VolumeGb: types.Int64Value(16)}
+	}
+
+	// the changed or removed worker nodes are replaced by the update of the cluster
+	changes := map[string]func(data *kubernetesModel){
+		"worker_node_vcpu": func(data *kubernetesModel) { data.WorkerNodes[0].VCpu = types.Int64Value(4) },
+		"worker_node_data_center_id": func(data *kubernetesModel) {
+			data.WorkerNodes[0].DataCenterID = types.StringValue("aws-eu-north-1")
+		},
+		"worker_node_removed": func(data *kubernetesModel) { data.WorkerNodes = []kubernetesWorkerNodeModel{newWorkerNode("node-2")} },
+	}
+	for name, change := range changes {
+		t.Run(name, func(t *testing.T) {
+			stateData := protectedKubernetesState(t)
+			planData := stateData
+			planData.WorkerNodes = slices.Clone(stateData.WorkerNodes)
+			change(&planData)
+
+			resp := planResourceChange(t, NewKubernetesResource(), "emma_kubernetes_cluster", &stateData, &planData, nil)
+			require.Len(t, resp.Diagnostics, 1, resp.Diagnostics)
+			assert.Equal(t, "Unable to replace Kubernetes cluster: deletion_protection is true, set it to false and apply the change first",
+				resp.Diagnostics[0].Detail)
+
+			// the worker nodes of the unprotected cluster are replaced
+			stateData.DeletionProtection = types.BoolValue(false)
+			planData.DeletionProtection = types.BoolValue(false)
+			resp = planResourceChange(t, NewKubernetesResource(), "emma_kubernetes_cluster", &stateData, &planData, nil)
+			assert.Empty(t, resp.Diagnostics)
+		})
+	}
+
+	t.Run("worker_node_added", func(t *testing.T) {
+		stateData := protectedKubernetesState(t)
+		planData := stateData
+		planData.WorkerNodes = append(slices.Clone(stateData.WorkerNodes), newWorkerNode("node-2"))
+
+		resp := planResourceChange(t, NewKubernetesResource(), "emma_kubernetes_cluster", &stateData, &planData, nil)
+		assert.Empty(t, resp.Diagnostics)
+		assert.Empty(t, resp.RequiresReplace)
+	})
+}
+
+func TestDeletionProtectionSpotInstanceReplace(t *testing.T) {
+	// the hardware of the spot instance can't be edited, it is replaced
+	changes := map[string]func(data *spotInstanceResourceModel){
+		"vcpu":  func(data *spotInstanceResourceModel) { data.VCpu = types.Int64Value(4) },
+		"price": func(data *spotInstanceResourceModel) { data.Price = types.Float64Value(0.5) },
+	}
+	for attribute, change := range changes {
+		t.Run(attribute, func(t *testing.T) {
+			var stateData spotInstanceResourceModel
+			state := upgradeStateFixture(t, NewSpotInstanceResource(), "spot_instance.json")
+			require.False(t, state.Get(context.Background(), &stateData).HasError())
+			stateData.FullName = stateData.Name
+			stateData.DeletionProtection = types.BoolValue(true)
+			stateData.AllowVolumeShrinkByReplace = types.BoolValue(false)
+			planData := stateData
+			change(&planData)
+
+			resp := planResourceChange(t, NewSpotInstanceResource(), "emma_spot_instance", &stateData, &planData, nil)
+			require.Len(t, resp.Diagnostics, 1, resp.Diagnostics)
+			assert.Equal(t, "Unable to replace spot instance: deletion_protection is true, set it to false and apply the change first",
+				resp.Diagnostics[0].Detail)
+		})
+	}
+}
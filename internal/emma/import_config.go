@@ -0,0 +1,424 @@
+package emma
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	emmaSdk "github.com/emma-community/emma-go-sdk"
+	"github.com/emma-community/terraform-provider-emma/tools"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/zclconf/go-cty/cty"
+	"io"
+	"math/big"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// importConfigHeader is the comment at the top of the generated configuration.
+const importConfigHeader = "# Generated by the import command of the emma provider, the import blocks require Terraform 1.5 or later.\n" +
+	"# Review the configuration before the apply: the attributes stored in the state only, like labels and\n" +
+	"# deletion_protection, and the secrets, like user_password, aren't returned by the emma API.\n\n"
+
+// importConfigNameRegex matches the characters of the emma resource names that aren't allowed in the Terraform names.
+var importConfigNameRegex = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// importConfigResource lists the resources of a type in emma, the IDs are the import IDs of the resource.
+type importConfigResource struct {
+	typeName    string
+	newResource func() resource.Resource
+	list        func(auth context.Context, apiClient *emmaSdk.APIClient) ([]string, *http.Response, error)
+}
+
+// importConfigResources are the resources in the order of the generated configuration, the SSH keys and the security
+// groups are first as they are referenced by the compute instances.
+var importConfigResources = []importConfigResource{
+	{typeName: "emma_ssh_key", newResource: NewSshKeyResource, list: listSshKeyIds},
+	{typeName: "emma_security_group", newResource: NewSecurityGroupResource, list: listSecurityGroupIds},
+	{typeName: "emma_vm", newResource: NewVmResource, list: listVmIds},
+	{typeName: "emma_spot_instance", newResource: NewSpotInstanceResource, list: listSpotInstanceIds},
+	{typeName: "emma_kubernetes_cluster", newResource: NewKubernetesResource, list: listKubernetesIds},
+}
+
+// importConfigReferences contains the resource types by the attributes that refer to their IDs, the attributes refer
+// to the resources imported by the same configuration instead of the literal IDs.
+var importConfigReferences = map[string]string{
+	"ssh_key_id":        "emma_ssh_key",
+	"security_group_id": "emma_security_group",
+}
+
+// ImportConfigOptions configures the generation of the import configuration.
+type ImportConfigOptions struct {
+	// Host is the emma API host, the EMMA_HOST environment variable or the public emma API is used if it is empty
+	Host string
+	// ResourceTypes are the generated resource types, all resource types of ImportConfigResourceTypes if it is empty
+	ResourceTypes []string
+}
+
+// ImportConfigResourceTypes returns the resource types that GenerateImportConfig generates.
+func ImportConfigResourceTypes() []string {
+	resourceTypes := make([]string, len(importConfigResources))
+	for i, importConfigResource := range importConfigResources {
+		resourceTypes[i] = importConfigResource.typeName
+	}
+	return resourceTypes
+}
+
+// GenerateImportConfig writes the import blocks and the configuration of the resources in the emma project of the
+// EMMA_CLIENT_ID and EMMA_CLIENT_SECRET credentials. The resources are imported and read by the resources of the
+// provider, so the configuration has the same attribute values as the state after terraform import.
+func GenerateImportConfig(ctx context.Context, w io.Writer, options ImportConfigOptions) error {
+	for _, resourceType := range options.ResourceTypes {
+		if !slices.Contains(ImportConfigResourceTypes(), resourceType) {
+			return fmt.Errorf("unsupported resource type %s, available values: %s", resourceType,
+				strings.Join(ImportConfigResourceTypes(), ", "))
+		}
+	}
+
+	client, err := newImportConfigClient(ctx, options.Host)
+	if err != nil {
+		return err
+	}
+	auth := context.WithValue(ctx, emmaSdk.ContextAccessToken, *client.token.AccessToken)
+
+	file := hclwrite.NewEmptyFile()
+	// the Terraform names of the imported resources by the resource type and the ID, e.g. "emma_ssh_key.3001"
+	imported := make(map[string]string)
+	for _, importConfigResource := range importConfigResources {
+		if len(options.ResourceTypes) > 0 && !slices.Contains(options.ResourceTypes, importConfigResource.typeName) {
+			continue
+		}
+
+		ids, response, err := importConfigResource.list(auth, client.apiClient)
+		if err != nil {
+			return fmt.Errorf("unable to list %s, got error: %s", importConfigResource.typeName, tools.ExtractErrorMessage(response))
+		}
+		if err := importConfigResource.write(ctx, file.Body(), client, ids, imported); err != nil {
+			return err
+		}
+	}
+
+	config := append([]byte(importConfigHeader), hclwrite.Format(file.Bytes())...)
+	_, err = w.Write(append(bytes.TrimRight(config, "\n"), '\n'))
+	return err
+}
+
+// newImportConfigClient returns the emma API client of the provider configured by the environment variables.
+func newImportConfigClient(ctx context.Context, host string) (*Client, error) {
+	p := &Provider{}
+	var schemaResp provider.SchemaResponse
+	p.Schema(ctx, provider.SchemaRequest{}, &schemaResp)
+
+	config := providerModel{
+		Host:               types.StringNull(),
+		AllowedSshKeyTypes: types.ListNull(types.StringType),
+		DefaultLabels:      types.MapNull(types.StringType),
+	}
+	if host != "" {
+		config.Host = types.StringValue(host)
+	}
+	configState := tfsdk.State{Schema: schemaResp.Schema}
+	if err := diagnosticsError(configState.Set(ctx, &config)); err != nil {
+		return nil, err
+	}
+
+	var configureResp provider.ConfigureResponse
+	p.Configure(ctx, provider.ConfigureRequest{Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: configState.Raw}}, &configureResp)
+	if err := diagnosticsError(configureResp.Diagnostics); err != nil {
+		return nil, err
+	}
+	return configureResp.ResourceData.(*Client), nil
+}
+
+// write imports the resources of the IDs and writes their import blocks and configuration, the resources that
+// are already deleted are skipped. The names of the written resources are added to imported.
+func (c importConfigResource) write(ctx context.Context, body *hclwrite.Body, client *Client, ids []string,
+	imported map[string]string) error {
+	r := c.newResource()
+	var configureResp resource.ConfigureResponse
+	r.(resource.ResourceWithConfigure).Configure(ctx, resource.ConfigureRequest{ProviderData: client}, &configureResp)
+	if err := diagnosticsError(configureResp.Diagnostics); err != nil {
+		return err
+	}
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+
+	names := make(map[string]bool)
+	for _, id := range ids {
+		importResp := resource.ImportStateResponse{State: tfsdk.State{
+			Schema: schemaResp.Schema,
+			Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+		}}
+		r.(resource.ResourceWithImportState).ImportState(ctx, resource.ImportStateRequest{ID: id}, &importResp)
+		if err := diagnosticsError(importResp.Diagnostics); err != nil {
+			return fmt.Errorf("unable to import %s %s, %w", c.typeName, id, err)
+		}
+
+		// ImportState only sets the ID, the resource is read like terraform import does
+		readResp := resource.ReadResponse{State: importResp.State, Private: importResp.Private}
+		r.Read(ctx, resource.ReadRequest{State: importResp.State, Private: importResp.Private}, &readResp)
+		if err := diagnosticsError(readResp.Diagnostics); err != nil {
+			return fmt.Errorf("unable to read %s %s, %w", c.typeName, id, err)
+		}
+		if readResp.State.Raw.IsNull() {
+			continue
+		}
+
+		var name types.String
+		if _, ok := schemaResp.Schema.Attributes["name"]; ok {
+			readResp.State.GetAttribute(ctx, path.Root("name"), &name)
+		}
+		importName := importConfigName(name.ValueString(), id, names)
+		if err := writeImportConfig(body, c.typeName, importName, id, schemaResp.Schema, readResp.State.Raw, imported); err != nil {
+			return fmt.Errorf("unable to write %s %s, %w", c.typeName, id, err)
+		}
+		imported[c.typeName+"."+id] = importName
+	}
+	return nil
+}
+
+// importConfigName returns the unique Terraform name of the resource in the snake case of the emma resource name.
+func importConfigName(name string, id string, names map[string]bool) string {
+	importName := strings.Trim(importConfigNameRegex.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if importName == "" {
+		importName = importConfigNameRegex.ReplaceAllString(id, "_")
+	}
+	// the Terraform names start with a letter or an underscore
+	if importName[0] >= '0' && importName[0] <= '9' {
+		importName = "emma_" + importName
+	}
+	if names[importName] {
+		importName = importName + "_" + importConfigNameRegex.ReplaceAllString(id, "_")
+	}
+	names[importName] = true
+	return importName
+}
+
+// writeImportConfig writes the import block and the resource block with the configurable attributes of the state,
+// the attributes of importConfigReferences refer to the imported resources.
+func writeImportConfig(body *hclwrite.Body, typeName string, name string, id string, resourceSchema schema.Schema, state tftypes.Value,
+	imported map[string]string) error {
+	importBody := body.AppendNewBlock("import", nil).Body()
+	importBody.SetAttributeTraversal("to", hcl.Traversal{hcl.TraverseRoot{Name: typeName}, hcl.TraverseAttr{Name: name}})
+	importBody.SetAttributeValue("id", cty.StringVal(id))
+	body.AppendNewline()
+
+	var values map[string]tftypes.Value
+	if err := state.As(&values); err != nil {
+		return err
+	}
+	resourceBody := body.AppendNewBlock("resource", []string{typeName, name}).Body()
+	for _, attributeName := range sortedKeys(resourceSchema.Attributes) {
+		if resourceSchema.Attributes[attributeName].IsRequired() && values[attributeName].IsNull() {
+			resourceBody.AppendUnstructuredTokens(hclwrite.Tokens{{Type: hclsyntax.TokenComment,
+				Bytes: []byte(fmt.Sprintf("# %s is required, it isn't returned by the emma API\n", attributeName))}})
+		}
+	}
+	for _, attributeName := range sortedKeys(resourceSchema.Attributes) {
+		attribute := resourceSchema.Attributes[attributeName]
+		if (!attribute.IsRequired() && !attribute.IsOptional()) || values[attributeName].IsNull() {
+			continue
+		}
+		// the optional computed attributes without a value in emma are omitted, e.g. the error description
+		if attribute.IsComputed() && values[attributeName].Equal(tftypes.NewValue(tftypes.String, "")) {
+			continue
+		}
+		if traversal, ok := importConfigReference(attributeName, values[attributeName], imported); ok {
+			resourceBody.SetAttributeTraversal(attributeName, traversal)
+			continue
+		}
+		value, err := importConfigValue(values[attributeName], nestedAttributes(attribute))
+		if err != nil {
+			return err
+		}
+		resourceBody.SetAttributeValue(attributeName, value)
+	}
+	body.AppendNewline()
+	return nil
+}
+
+// importConfigReference returns the reference to the ID of the imported resource that the attribute refers to,
+// e.g. emma_ssh_key.deploy_key.id for ssh_key_id = 3001.
+func importConfigReference(attributeName string, value tftypes.Value, imported map[string]string) (hcl.Traversal, bool) {
+	typeName, ok := importConfigReferences[attributeName]
+	if !ok || !value.Type().Is(tftypes.Number) {
+		return nil, false
+	}
+	var id big.Float
+	if err := value.As(&id); err != nil {
+		return nil, false
+	}
+	name, ok := imported[typeName+"."+id.Text('f', -1)]
+	if !ok {
+		return nil, false
+	}
+	return hcl.Traversal{hcl.TraverseRoot{Name: typeName}, hcl.TraverseAttr{Name: name}, hcl.TraverseAttr{Name: "id"}}, true
+}
+
+// importConfigValue converts the state value to the configuration value, the nested objects only keep
+// the configurable attributes of the nested attributes.
+func importConfigValue(value tftypes.Value, attributes map[string]schema.Attribute) (cty.Value, error) {
+	if value.IsNull() {
+		return cty.NullVal(cty.DynamicPseudoType), nil
+	}
+
+	switch valueType := value.Type(); {
+	case valueType.Is(tftypes.String):
+		var stringValue string
+		err := value.As(&stringValue)
+		return cty.StringVal(stringValue), err
+	case valueType.Is(tftypes.Number):
+		var numberValue big.Float
+		err := value.As(&numberValue)
+		return cty.NumberVal(&numberValue), err
+	case valueType.Is(tftypes.Bool):
+		var boolValue bool
+		err := value.As(&boolValue)
+		return cty.BoolVal(boolValue), err
+	case valueType.Is(tftypes.List{}), valueType.Is(tftypes.Set{}), valueType.Is(tftypes.Tuple{}):
+		var elements []tftypes.Value
+		if err := value.As(&elements); err != nil {
+			return cty.NilVal, err
+		}
+		if len(elements) == 0 {
+			return cty.EmptyTupleVal, nil
+		}
+		configElements := make([]cty.Value, len(elements))
+		for i, element := range elements {
+			configElement, err := importConfigValue(element, attributes)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			configElements[i] = configElement
+		}
+		return cty.TupleVal(configElements), nil
+	case valueType.Is(tftypes.Map{}), valueType.Is(tftypes.Object{}):
+		var elements map[string]tftypes.Value
+		if err := value.As(&elements); err != nil {
+			return cty.NilVal, err
+		}
+		// the attributes of the objects are filtered, the elements of the maps are converted with the nested attributes
+		isObject := valueType.Is(tftypes.Object{})
+		configElements := make(map[string]cty.Value)
+		for key, element := range elements {
+			elementAttributes := attributes
+			if isObject && attributes != nil {
+				attribute, ok := attributes[key]
+				if !ok || (!attribute.IsRequired() && !attribute.IsOptional()) || element.IsNull() {
+					continue
+				}
+				elementAttributes = nestedAttributes(attribute)
+			}
+			configElement, err := importConfigValue(element, elementAttributes)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			configElements[key] = configElement
+		}
+		if len(configElements) == 0 {
+			return cty.EmptyObjectVal, nil
+		}
+		return cty.ObjectVal(configElements), nil
+	}
+	return cty.NilVal, fmt.Errorf("unsupported value type %s", value.Type())
+}
+
+// nestedAttributes returns the attributes of the nested object of the attribute or nil if the attribute isn't nested.
+func nestedAttributes(attribute schema.Attribute) map[string]schema.Attribute {
+	switch nestedAttribute := attribute.(type) {
+	case schema.ListNestedAttribute:
+		return nestedAttribute.NestedObject.Attributes
+	case schema.SetNestedAttribute:
+		return nestedAttribute.NestedObject.Attributes
+	case schema.MapNestedAttribute:
+		return nestedAttribute.NestedObject.Attributes
+	case schema.SingleNestedAttribute:
+		return nestedAttribute.Attributes
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of the attributes in the alphabetical order like terraform plan -generate-config-out.
+func sortedKeys(attributes map[string]schema.Attribute) []string {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// diagnosticsError returns the errors of the diagnostics as an error or nil if there are no errors.
+func diagnosticsError(diagnostics diag.Diagnostics) error {
+	if !diagnostics.HasError() {
+		return nil
+	}
+	messages := make([]string, 0, diagnostics.ErrorsCount())
+	for _, diagnostic := range diagnostics.Errors() {
+		messages = append(messages, diagnostic.Summary()+": "+diagnostic.Detail())
+	}
+	return errors.New(strings.Join(messages, "\n"))
+}
+
+func listSshKeyIds(auth context.Context, apiClient *emmaSdk.APIClient) ([]string, *http.Response, error) {
+	sshKeys, response, err := apiClient.SSHKeysAPI.SshKeys(auth).Execute()
+	ids := make([]string, 0, len(sshKeys))
+	for _, sshKey := range sshKeys {
+		ids = append(ids, strconv.Itoa(int(*sshKey.Id)))
+	}
+	return ids, response, err
+}
+
+func listSecurityGroupIds(auth context.Context, apiClient *emmaSdk.APIClient) ([]string, *http.Response, error) {
+	securityGroups, response, err := apiClient.SecurityGroupsAPI.GetSecurityGroups(auth).Execute()
+	ids := make([]string, 0, len(securityGroups))
+	for _, securityGroup := range securityGroups {
+		// the default security group is created by emma for the project, it isn't managed by Terraform
+		if securityGroup.Name != nil && *securityGroup.Name == defaultSecurityGroupName {
+			continue
+		}
+		ids = append(ids, strconv.Itoa(int(*securityGroup.Id)))
+	}
+	return ids, response, err
+}
+
+func listVmIds(auth context.Context, apiClient *emmaSdk.APIClient) ([]string, *http.Response, error) {
+	vms, response, err := apiClient.VirtualMachinesAPI.GetVms(auth).Execute()
+	return computeIds(vms), response, err
+}
+
+func listSpotInstanceIds(auth context.Context, apiClient *emmaSdk.APIClient) ([]string, *http.Response, error) {
+	spotInstances, response, err := apiClient.SpotInstancesAPI.GetSpots(auth).Execute()
+	return computeIds(spotInstances), response, err
+}
+
+func computeIds(instances []emmaSdk.Vm) []string {
+	ids := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		ids = append(ids, strconv.Itoa(int(*instance.Id)))
+	}
+	return ids
+}
+
+func listKubernetesIds(auth context.Context, apiClient *emmaSdk.APIClient) ([]string, *http.Response, error) {
+	clusters, response, err := apiClient.KubernetesClustersAPI.GetKubernetesClusters(auth).Execute()
+	ids := make([]string, 0, len(clusters))
+	for _, cluster := range clusters {
+		ids = append(ids, strconv.Itoa(int(*cluster.Id)))
+	}
+	return ids, response, err
+}
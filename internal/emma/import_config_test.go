@@ -0,0 +1,146 @@
+package emma
+
+import (
+	"bytes"
+	"context"
+	emmaSdk "github.com/emma-community/emma-go-sdk"
+	"github.com/emma-community/terraform-provider-emma/internal/mock"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http/httptest"
+	"testing"
+)
+
+// newImportConfigServer returns the host of the stub of the emma API with a resource of each imported type.
+func newImportConfigServer(t *testing.T) string {
+	mockServer, err := mock.NewServer("")
+	require.NoError(t, err)
+	server := httptest.NewServer(mockServer)
+	t.Cleanup(server.Close)
+	t.Setenv("EMMA_CLIENT_ID", "client-id")
+	t.Setenv("EMMA_CLIENT_SECRET", "client-secret")
+
+	configuration := emmaSdk.NewConfiguration()
+	configuration.Servers = emmaSdk.ServerConfigurations{{URL: server.URL}}
+	apiClient := emmaSdk.NewAPIClient(configuration)
+	token, _, err := apiClient.AuthenticationAPI.IssueToken(context.Background()).
+		Credentials(emmaSdk.Credentials{ClientId: "client-id", ClientSecret: "client-secret"}).Execute()
+	require.NoError(t, err)
+	auth := context.WithValue(context.Background(), emmaSdk.ContextAccessToken, *token.AccessToken)
+
+	_, _, err = apiClient.SSHKeysAPI.SshKeysCreateImport(auth).SshKeysCreateImportRequest(emmaSdk.SshKeysCreateImportRequest{
+		SshKeyCreate: &emmaSdk.SshKeyCreate{Name: "Deploy.Key", KeyType: "ED25519"},
+	}).Execute()
+	require.NoError(t, err)
+	_, _, err = apiClient.SecurityGroupsAPI.SecurityGroupCreate(auth).SecurityGroupRequest(emmaSdk.SecurityGroupRequest{
+		Name:  "web",
+		Rules: []emmaSdk.SecurityGroupRuleRequest{{Direction: "INBOUND", Protocol: "TCP", Ports: "443", IpRange: "0.0.0.0/0"}},
+	}).Execute()
+	require.NoError(t, err)
+	_, _, err = apiClient.VirtualMachinesAPI.VmCreate(auth).VmCreate(emmaSdk.VmCreate{
+		Name: "web", DataCenterId: "digitalocean-ams3", OsId: 2, CloudNetworkType: "multi-cloud", VCpuType: "shared",
+		VCpu: 2, RamGb: 4, VolumeType: "ssd", VolumeGb: 16, SshKeyId: emmaSdk.PtrInt32(3001),
+	}).Execute()
+	require.NoError(t, err)
+	_, _, err = apiClient.SpotInstancesAPI.SpotCreate(auth).SpotCreate(emmaSdk.SpotCreate{
+		Name: "web", DataCenterId: "aws-eu-central-1", OsId: 3, CloudNetworkType: "multi-cloud", VCpuType: "shared",
+		VCpu: 2, RamGb: 4, VolumeType: "ssd", VolumeGb: 16, SshKeyId: emmaSdk.PtrInt32(3001), Price: 0.05,
+	}).Execute()
+	require.NoError(t, err)
+	_, _, err = apiClient.KubernetesClustersAPI.CreateKubernetesCluster(auth).KubernetesCreate(emmaSdk.KubernetesCreate{
+		Name: "cluster", DeploymentLocation: "aws-eu-north-1",
+		WorkerNodes: []emmaSdk.KubernetesCreateWorkerNodesInner{
+			{Name: "first", DataCenterId: "aws-eu-north-1", VCpuType: "shared", VCpu: 2, RamGb: 4, VolumeType: "ssd", VolumeGb: 16},
+		},
+	}).Execute()
+	require.NoError(t, err)
+	return server.URL
+}
+
+func TestGenerateImportConfig(t *testing.T) {
+	host := newImportConfigServer(t)
+
+	var config bytes.Buffer
+	require.NoError(t, GenerateImportConfig(context.Background(), &config, ImportConfigOptions{Host: host}))
+
+	_, diags := hclsyntax.ParseConfig(config.Bytes(), "imports.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors(), diags.Error())
+	assert.Contains(t, config.String(), `import {
+  to = emma_vm.web
+  id = "1001"
+}
+
+resource "emma_vm" "web" {
+  cloud_network_type = "multi-cloud"
+  data_center_id     = "digitalocean-ams3"
+  name               = "web"
+  os_id              = 2
+  ram_gb             = 4
+  ssh_key_id         = emma_ssh_key.deploy_key.id
+  vcpu               = 2
+  vcpu_type          = "shared"
+  volume_gb          = 16
+  volume_type        = "ssd"
+}
+`)
+	assert.Contains(t, config.String(), `resource "emma_security_group" "web" {
+  name = "web"
+  rules = [{
+    direction = "INBOUND"
+    ip_range  = "0.0.0.0/0"
+    ports     = "443"
+    protocol  = "TCP"
+  }]
+}
+`)
+	// the price of the spot instance is stored in the state only
+	assert.Contains(t, config.String(), `resource "emma_spot_instance" "web" {
+  # price is required, it isn't returned by the emma API
+`)
+	// the worker nodes of the imported cluster are read from the emma API
+	assert.Contains(t, config.String(), `  worker_nodes = [{
+    data_center_id = "aws-eu-north-1"
+    name           = "cluster-first-7001"
+`)
+	assert.Contains(t, config.String(), `to = emma_ssh_key.deploy_key`)
+	// the generated names are valid with the name rule of the resource type
+	assert.Contains(t, config.String(), `name = "Deploy.Key"`)
+	// the default security group isn't managed by Terraform
+	assert.NotContains(t, config.String(), `id = "2001"`)
+}
+
+func TestGenerateImportConfigResourceTypes(t *testing.T) {
+	host := newImportConfigServer(t)
+
+	var config bytes.Buffer
+	require.NoError(t, GenerateImportConfig(context.Background(), &config, ImportConfigOptions{
+		Host:          host,
+		ResourceTypes: []string{"emma_vm", "emma_ssh_key"},
+	}))
+	assert.Contains(t, config.String(), `resource "emma_vm" "web"`)
+	assert.Contains(t, config.String(), `resource "emma_ssh_key" "deploy_key"`)
+	assert.NotContains(t, config.String(), "emma_spot_instance")
+	assert.NotContains(t, config.String(), "emma_security_group")
+
+	// the SSH key that isn't imported is referred to by the ID
+	config.Reset()
+	require.NoError(t, GenerateImportConfig(context.Background(), &config, ImportConfigOptions{
+		Host:          host,
+		ResourceTypes: []string{"emma_vm"},
+	}))
+	assert.Contains(t, config.String(), `ssh_key_id         = 3001`)
+
+	err := GenerateImportConfig(context.Background(), &config, ImportConfigOptions{Host: host, ResourceTypes: []string{"emma_vms"}})
+	assert.ErrorContains(t, err, "unsupported resource type emma_vms")
+}
+
+func TestImportConfigName(t *testing.T) {
+	names := make(map[string]bool)
+	assert.Equal(t, "web_server", importConfigName("Web Server", "1001", names))
+	assert.Equal(t, "web_server_1002", importConfigName("web-server", "1002", names))
+	assert.Equal(t, "emma_1st", importConfigName("1st", "1003", names))
+	assert.Equal(t, "emma_1004", importConfigName("", "1004", names))
+	assert.Equal(t, "emma_1st_1005", importConfigName("1st", "1005", names))
+}
@@ -6,6 +6,7 @@ import (
 	emmaSdk "github.com/emma-community/emma-go-sdk"
 	emma "github.com/emma-community/terraform-provider-emma/internal/emma/validation"
 	"github.com/emma-community/terraform-provider-emma/tools"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
@@ -13,27 +14,39 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"strconv"
 	"time"
 )
 
 var _ resource.Resource = &kubernetesResource{}
+var _ resource.ResourceWithModifyPlan = &kubernetesResource{}
+var _ resource.ResourceWithUpgradeState = &kubernetesResource{}
+var _ resource.ResourceWithImportState = &kubernetesResource{}
 
 func NewKubernetesResource() resource.Resource {
 	return &kubernetesResource{}
 }
 
 type kubernetesResource struct {
-	apiClient *emmaSdk.APIClient
-	token     *emmaSdk.Token
+	apiClient     *emmaSdk.APIClient
+	token         *emmaSdk.Token
+	naming        namingPolicy
+	defaultLabels map[string]string
+	operations    *operationLimiter
 }
 
 type kubernetesModel struct {
-	Id                 types.Int64                 `tfsdk:"id"`
-	Name               types.String                `tfsdk:"name"`
-	DeploymentLocation types.String                `tfsdk:"deployment_location"`
-	DomainName         types.String                `tfsdk:"domain_name"`
-	WorkerNodes        []kubernetesWorkerNodeModel `tfsdk:"worker_nodes"`
-	AutoscalingConfigs *[]autoscalingConfigModel   `tfsdk:"autoscaling_configs"`
+	Id                         types.String                `tfsdk:"id"`
+	Name                       types.String                `tfsdk:"name"`
+	FullName                   types.String                `tfsdk:"full_name"`
+	DeploymentLocation         types.String                `tfsdk:"deployment_location"`
+	DomainName                 types.String                `tfsdk:"domain_name"`
+	WorkerNodes                []kubernetesWorkerNodeModel `tfsdk:"worker_nodes"`
+	AutoscalingConfigs         *[]autoscalingConfigModel   `tfsdk:"autoscaling_configs"`
+	Labels                     types.Map                   `tfsdk:"labels"`
+	EffectiveLabels            types.Map                   `tfsdk:"effective_labels"`
+	DeletionProtection         types.Bool                  `tfsdk:"deletion_protection"`
+	AllowVolumeShrinkByReplace types.Bool                  `tfsdk:"allow_volume_shrink_by_replace"`
 }
 
 type kubernetesWorkerNodeModel struct {
@@ -74,6 +87,10 @@ type configurationPriorityModel struct {
 	Priority   types.String `tfsdk:"priority"`
 }
 
+func (r *kubernetesResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return stateUpgraders()
+}
+
 func (r *kubernetesResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	// Prevent panic if the provider has not been configured.
 	if req.ProviderData == nil {
@@ -87,6 +104,79 @@ func (r *kubernetesResource) Configure(ctx context.Context, req resource.Configu
 	}
 	r.apiClient = client.apiClient
 	r.token = client.token
+	r.naming = client.naming
+	r.defaultLabels = client.defaultLabels
+	r.operations = client.operations
+}
+
+func (r *kubernetesResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planFullName(ctx, r.naming, emma.KubernetesNameRule, req, resp)
+	planEffectiveLabels(ctx, r.defaultLabels, req, resp)
+	planWorkerNodesVolumeShrink(ctx, req, resp)
+	checkDeletionProtection(ctx, "Kubernetes cluster", kubernetesReplacedAttributes, req, resp)
+}
+
+// kubernetesReplacedAttributes are the attributes of the Kubernetes cluster that replace it or its existing worker
+// nodes after changing them, the worker nodes are updated with a new node if any of them is changed, see
+// ConvertToKubernetesUpdateResourceRequest.
+var kubernetesReplacedAttributes = path.Expressions{
+	path.MatchRoot("name"),
+	path.MatchRoot("deployment_location"),
+	path.MatchRoot("worker_nodes").AtAnyListIndex().AtName("name"),
+	path.MatchRoot("worker_nodes").AtAnyListIndex().AtName("data_center_id"),
+	path.MatchRoot("worker_nodes").AtAnyListIndex().AtName("vcpu_type"),
+	path.MatchRoot("worker_nodes").AtAnyListIndex().AtName("vcpu"),
+	path.MatchRoot("worker_nodes").AtAnyListIndex().AtName("ram_gb"),
+	path.MatchRoot("worker_nodes").AtAnyListIndex().AtName("volume_type"),
+	path.MatchRoot("worker_nodes").AtAnyListIndex().AtName("volume_gb"),
+}
+
+// planWorkerNodesVolumeShrink fails the plan that decreases the volume of a worker node, unless allow_volume_shrink_by_replace
+// is true, then the worker node is replaced by a new node when the cluster is updated. The worker nodes are matched by name,
+// the worker nodes without a name are matched by position.
+func planWorkerNodesVolumeShrink(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// the resource is created or destroyed
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var allowVolumeShrinkByReplace types.Bool
+	var planWorkerNodes, stateWorkerNodes types.List
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("allow_volume_shrink_by_replace"), &allowVolumeShrinkByReplace)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("worker_nodes"), &planWorkerNodes)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("worker_nodes"), &stateWorkerNodes)...)
+	if resp.Diagnostics.HasError() || allowVolumeShrinkByReplace.ValueBool() || planWorkerNodes.IsUnknown() {
+		return
+	}
+
+	var planNodes, stateNodes []kubernetesWorkerNodeModel
+	resp.Diagnostics.Append(planWorkerNodes.ElementsAs(ctx, &planNodes, false)...)
+	resp.Diagnostics.Append(stateWorkerNodes.ElementsAs(ctx, &stateNodes, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	stateNodesByName := make(map[string]kubernetesWorkerNodeModel)
+	for _, stateNode := range stateNodes {
+		if !stateNode.Name.IsNull() {
+			stateNodesByName[stateNode.Name.ValueString()] = stateNode
+		}
+	}
+
+	for i, planNode := range planNodes {
+		stateNode, exists := stateNodesByName[planNode.Name.ValueString()]
+		if planNode.Name.IsNull() {
+			exists = i < len(stateNodes) && stateNodes[i].Name.IsNull()
+			if exists {
+				stateNode = stateNodes[i]
+			}
+		}
+		if !exists || planNode.VolumeGb.IsUnknown() || planNode.VolumeGb.ValueInt64() >= stateNode.VolumeGb.ValueInt64() {
+			continue
+		}
+		addVolumeShrinkError(path.Root("worker_nodes").AtListIndex(i).AtName("volume_gb"), "worker node",
+			stateNode.VolumeGb.ValueInt64(), planNode.VolumeGb.ValueInt64(), &resp.Diagnostics)
+	}
 }
 
 func (r *kubernetesResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -101,9 +191,17 @@ func (r *kubernetesResource) Create(ctx context.Context, req resource.CreateRequ
 	tflog.Info(ctx, "Create kubernetes cluster")
 
 	var kubernetesCreate emmaSdk.KubernetesCreate
+	applyFullName(r.naming, data.Name, &data.FullName)
+	applyEffectiveLabels(ctx, r.defaultLabels, data.Labels, &data.EffectiveLabels, &resp.Diagnostics)
 	ConvertToKubernetesCreateResourceRequest(data, &kubernetesCreate)
 
 	auth := context.WithValue(ctx, emmaSdk.ContextAccessToken, *r.token.AccessToken)
+	release, err := r.operations.acquire(ctx, "kubernetes cluster create")
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create kubernetes cluster, got error: %s", err))
+		return
+	}
+	defer release()
 	kubernetesGroup, response, err := r.apiClient.KubernetesClustersAPI.CreateKubernetesCluster(auth).KubernetesCreate(kubernetesCreate).Execute()
 
 	if err != nil {
@@ -112,7 +210,7 @@ func (r *kubernetesResource) Create(ctx context.Context, req resource.CreateRequ
 	}
 
 	var result kubernetesModel
-	ConvertKubernetesResponseToResource(&result, kubernetesGroup, &data)
+	ConvertKubernetesResponseToResource(r.naming, &result, kubernetesGroup, &data)
 
 	if resp.Diagnostics.HasError() {
 		return
@@ -133,7 +231,7 @@ func (r *kubernetesResource) Read(ctx context.Context, req resource.ReadRequest,
 	tflog.Info(ctx, "Read kubernetes cluster")
 
 	auth := context.WithValue(ctx, emmaSdk.ContextAccessToken, *r.token.AccessToken)
-	kubernetes, response, err := r.apiClient.KubernetesClustersAPI.GetKubernetesCluster(auth, int32(data.Id.ValueInt64())).Execute()
+	kubernetes, response, err := r.apiClient.KubernetesClustersAPI.GetKubernetesCluster(auth, tools.StringToInt32(data.Id.ValueString())).Execute()
 
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error",
@@ -142,7 +240,7 @@ func (r *kubernetesResource) Read(ctx context.Context, req resource.ReadRequest,
 	}
 
 	var result kubernetesModel
-	ConvertKubernetesResponseToResource(&result, kubernetes, &data)
+	ConvertKubernetesResponseToResource(r.naming, &result, kubernetes, &data)
 
 	if resp.Diagnostics.HasError() {
 		return
@@ -165,9 +263,10 @@ func (r *kubernetesResource) Update(ctx context.Context, req resource.UpdateRequ
 	tflog.Info(ctx, "Update kubernetes cluster")
 
 	var kubernetesUpdate emmaSdk.KubernetesUpdate
+	applyEffectiveLabels(ctx, r.defaultLabels, planData.Labels, &planData.EffectiveLabels, &resp.Diagnostics)
 	ConvertToKubernetesUpdateResourceRequest(planData, stateData, &kubernetesUpdate)
 	auth := context.WithValue(ctx, emmaSdk.ContextAccessToken, *r.token.AccessToken)
-	_, updateHttpResponse, updateError := r.apiClient.KubernetesClustersAPI.EditKubernetesCluster(auth, int32(stateData.Id.ValueInt64())).KubernetesUpdate(kubernetesUpdate).Execute()
+	_, updateHttpResponse, updateError := r.apiClient.KubernetesClustersAPI.EditKubernetesCluster(auth, tools.StringToInt32(stateData.Id.ValueString())).KubernetesUpdate(kubernetesUpdate).Execute()
 
 	if updateError != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update kubernetes cluster, got error: %s", tools.ExtractErrorMessage(updateHttpResponse)))
@@ -177,7 +276,7 @@ func (r *kubernetesResource) Update(ctx context.Context, req resource.UpdateRequ
 	// Update response doesn't return updated nodeGroups information, so we need to perform Get request to get updated information
 	// If we perform Get request immediately after Update request, it will return old information
 	time.Sleep(5 * time.Second)
-	getKubernetes, getHttpResponse, getError := r.apiClient.KubernetesClustersAPI.GetKubernetesCluster(auth, int32(stateData.Id.ValueInt64())).Execute()
+	getKubernetes, getHttpResponse, getError := r.apiClient.KubernetesClustersAPI.GetKubernetesCluster(auth, tools.StringToInt32(stateData.Id.ValueString())).Execute()
 
 	if getError != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to get kubernetes cluster, got error: %s", tools.ExtractErrorMessage(getHttpResponse)))
@@ -185,7 +284,7 @@ func (r *kubernetesResource) Update(ctx context.Context, req resource.UpdateRequ
 	}
 
 	var result kubernetesModel
-	ConvertKubernetesResponseToResource(&result, getKubernetes, &planData)
+	ConvertKubernetesResponseToResource(r.naming, &result, getKubernetes, &planData)
 
 	if resp.Diagnostics.HasError() {
 		return
@@ -203,10 +302,15 @@ func (r *kubernetesResource) Delete(ctx context.Context, req resource.DeleteRequ
 		return
 	}
 
+	if data.DeletionProtection.ValueBool() {
+		addDeletionProtectionError("Kubernetes cluster", "destroy", &resp.Diagnostics)
+		return
+	}
+
 	tflog.Info(ctx, "Delete kubernetes cluster")
 
 	auth := context.WithValue(ctx, emmaSdk.ContextAccessToken, *r.token.AccessToken)
-	_, response, err := r.apiClient.KubernetesClustersAPI.DeleteKubernetesCluster(auth, int32(data.Id.ValueInt64())).Execute()
+	_, response, err := r.apiClient.KubernetesClustersAPI.DeleteKubernetesCluster(auth, tools.StringToInt32(data.Id.ValueString())).Execute()
 
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete kubernetes cluster, got error: %s", tools.ExtractErrorMessage(response)))
@@ -216,12 +320,19 @@ func (r *kubernetesResource) Delete(ctx context.Context, req resource.DeleteRequ
 	resp.State.RemoveResource(ctx)
 }
 
+func (r *kubernetesResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	tflog.Info(ctx, "Import kubernetes cluster")
+
+	// Retrieve import ID and save to id attribute, the worker nodes are read from the emma API
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
 func (r *kubernetesResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_kubernetes_cluster"
 }
 
 func ConvertToKubernetesCreateResourceRequest(data kubernetesModel, kubernetes *emmaSdk.KubernetesCreate) {
-	kubernetes.Name = data.Name.ValueString()
+	kubernetes.Name = data.FullName.ValueString()
 	kubernetes.DeploymentLocation = data.DeploymentLocation.ValueString()
 
 	var workerNodes []emmaSdk.KubernetesCreateWorkerNodesInner
@@ -334,17 +445,26 @@ func convertToAutoScalingConfigs(data kubernetesModel) []emmaSdk.KubernetesCreat
 	return autoscalingConfigs
 }
 
-func ConvertKubernetesResponseToResource(result *kubernetesModel, response *emmaSdk.Kubernetes, planData *kubernetesModel) {
+func ConvertKubernetesResponseToResource(naming namingPolicy, result *kubernetesModel, response *emmaSdk.Kubernetes, planData *kubernetesModel) {
 	if response.Id != nil {
-		result.Id = types.Int64Value(int64(*response.Id))
+		result.Id = types.StringValue(strconv.Itoa(int(*response.Id)))
 	} else {
-		result.Id = types.Int64Null()
+		result.Id = types.StringNull()
 	}
 
+	// the emma API doesn't accept labels yet, they are stored in the state only
+	result.Labels = planData.Labels
+	result.EffectiveLabels = planData.EffectiveLabels
+
+	// deletion protection is enforced by the provider, it is stored in the state only
+	result.DeletionProtection = planData.DeletionProtection
+	result.AllowVolumeShrinkByReplace = planData.AllowVolumeShrinkByReplace
+
 	if response.Name != nil {
-		result.Name = types.StringValue(*response.Name)
+		result.Name, result.FullName = readName(naming, planData.Name, planData.FullName, *response.Name)
 	} else {
 		result.Name = planData.Name
+		result.FullName = planData.FullName
 	}
 
 	if response.DeploymentLocation != nil {
@@ -375,6 +495,12 @@ func ConvertKubernetesResponseToResource(result *kubernetesModel, response *emma
 			}
 			result.WorkerNodes[i] = workerNode
 		}
+	} else if len(response.NodeGroups) > 0 && len(planData.WorkerNodes) == 0 {
+		// the imported cluster has no worker nodes in the state, they are read from the emma API
+		result.WorkerNodes = make([]kubernetesWorkerNodeModel, len(response.NodeGroups[0].Nodes))
+		for i, node := range response.NodeGroups[0].Nodes {
+			result.WorkerNodes[i] = convertKubernetesWorkerNode(node)
+		}
 	} else {
 		result.WorkerNodes = planData.WorkerNodes
 	}
@@ -421,8 +547,34 @@ func ConvertKubernetesResponseToResource(result *kubernetesModel, response *emma
 	}
 }
 
+// convertKubernetesWorkerNode converts the worker node of the emma API, the name of the worker node is the generated name.
+func convertKubernetesWorkerNode(node emmaSdk.KubernetesNodeGroupsInnerNodesInner) kubernetesWorkerNodeModel {
+	workerNode := kubernetesWorkerNodeModel{
+		Id:            types.Int64Value(int64(*node.Id)),
+		GeneratedName: types.StringValue(*node.Name),
+		Name:          types.StringValue(*node.Name),
+		DataCenterID:  types.StringNull(),
+		VCpuType:      types.StringPointerValue(node.VCpuType),
+		VCpu:          tools.GetInt64OrDefault(node.VCpu, types.Int64Null()),
+		RamGb:         tools.GetInt64OrDefault(node.RamGb, types.Int64Null()),
+		VolumeType:    types.StringNull(),
+		VolumeGb:      types.Int64Null(),
+	}
+	if node.DataCenter != nil {
+		workerNode.DataCenterID = types.StringPointerValue(node.DataCenter.Id)
+	}
+	for _, disk := range node.Disks {
+		if disk.IsBootable != nil && *disk.IsBootable {
+			workerNode.VolumeType = types.StringPointerValue(disk.Type)
+			workerNode.VolumeGb = tools.GetInt64OrDefault(disk.SizeGb, types.Int64Null())
+		}
+	}
+	return workerNode
+}
+
 func (r *kubernetesResource) Schema(_ context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Version: 1,
 		Description: "This resource creates a Kubernetes cluster.\n\n" +
 			"A Kubernetes cluster is a set of node machines for running containerized applications. " +
 			"The cluster is managed by the Kubernetes control plane, which is responsible for maintaining the desired state of the cluster.\n\n" +
@@ -431,7 +583,7 @@ func (r *kubernetesResource) Schema(_ context.Context, req resource.SchemaReques
 			"After creating a Kubernetes cluster, you can manage its configuration and scaling settings.",
 
 		Attributes: map[string]schema.Attribute{
-			"id": schema.Int64Attribute{
+			"id": schema.StringAttribute{
 				Description: "The ID of the Kubernetes cluster",
 				Computed:    true,
 			},
@@ -439,9 +591,37 @@ func (r *kubernetesResource) Schema(_ context.Context, req resource.SchemaReques
 				Description:   "The name of the Kubernetes cluster",
 				Optional:      true,
 				Computed:      true,
-				Validators:    []validator.String{emma.KubernetesResourceName{FieldName: "name"}},
+				Validators:    []validator.String{emma.NotBlankString{}, emma.ResourceName{Rule: emma.KubernetesNameRule}},
 				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
 			},
+			"full_name": schema.StringAttribute{
+				Description: "Name of the Kubernetes cluster in emma, the name with the prefix and the random suffix of the provider naming policy",
+				Computed:    true,
+			},
+			"labels": schema.MapAttribute{
+				Description: fmt.Sprintf(labelsDescription, "Kubernetes cluster"),
+				ElementType: types.StringType,
+				Computed:    false,
+				Required:    false,
+				Optional:    true,
+			},
+			"effective_labels": schema.MapAttribute{
+				Description: fmt.Sprintf(stateEffectiveLabelsDescription, "Kubernetes cluster", "Kubernetes clusters"),
+				ElementType: types.StringType,
+				Computed:    true,
+			},
+			"deletion_protection": schema.BoolAttribute{
+				Description: fmt.Sprintf(deletionProtectionDescription, "Kubernetes cluster", "Kubernetes cluster"),
+				Computed:    false,
+				Required:    false,
+				Optional:    true,
+			},
+			"allow_volume_shrink_by_replace": schema.BoolAttribute{
+				Description: fmt.Sprintf(allowVolumeShrinkByReplaceDescription, "worker node"),
+				Computed:    false,
+				Required:    false,
+				Optional:    true,
+			},
 			"deployment_location": schema.StringAttribute{
 				Description:   "The deployment location of the Kubernetes cluster",
 				Required:      true,
@@ -465,7 +645,7 @@ func (r *kubernetesResource) Schema(_ context.Context, req resource.SchemaReques
 						"name": schema.StringAttribute{
 							Description: "The name of the worker node",
 							Optional:    true,
-							Validators:  []validator.String{emma.KubernetesResourceName{FieldName: "worker_nodes.name"}},
+							Validators:  []validator.String{emma.NotBlankString{}, emma.ResourceName{Rule: emma.KubernetesNameRule}},
 						},
 						"generated_name": schema.StringAttribute{
 							Description: "The name of the worker node generated by server",
@@ -509,7 +689,7 @@ func (r *kubernetesResource) Schema(_ context.Context, req resource.SchemaReques
 						"group_name": schema.StringAttribute{
 							Description: "The name of the autoscaling group",
 							Required:    true,
-							Validators:  []validator.String{emma.KubernetesResourceName{FieldName: "group_name"}},
+							Validators:  []validator.String{emma.NotBlankString{}, emma.ResourceName{Rule: emma.KubernetesNameRule}},
 						},
 						"data_center_id": schema.StringAttribute{
 							Description: "The data center ID for the autoscaling group",
@@ -0,0 +1,73 @@
+package emma
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"testing"
+)
+
+const testAccKubernetesWorkerNode = `
+    {
+      name           = "%s"
+      data_center_id = "aws-eu-north-1"
+      vcpu_type      = "shared"
+      vcpu           = 2
+      ram_gb         = 4
+      volume_type    = "ssd"
+      volume_gb      = 16
+    }`
+
+func testAccKubernetesResourceConfig(providerConfig string, nodeNames ...string) string {
+	workerNodes := ""
+	for i, nodeName := range nodeNames {
+		if i > 0 {
+			workerNodes += ","
+		}
+		workerNodes += fmt.Sprintf(testAccKubernetesWorkerNode, nodeName)
+	}
+	return providerConfig + `
+resource "emma_kubernetes_cluster" "test" {
+  name                = "cluster"
+  deployment_location = "aws-eu-north-1"
+  worker_nodes        = [` + workerNodes + `]
+}
+`
+}
+
+func TestAccKubernetesResource(t *testing.T) {
+	providerConfig := testAccProviderConfig(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccKubernetesResourceConfig(providerConfig, "worker-1", "worker-2"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("emma_kubernetes_cluster.test", "id", "4001"),
+					resource.TestCheckResourceAttr("emma_kubernetes_cluster.test", "worker_nodes.#", "2"),
+					resource.TestCheckResourceAttr("emma_kubernetes_cluster.test", "worker_nodes.0.id", "7001"),
+					resource.TestCheckResourceAttr("emma_kubernetes_cluster.test", "worker_nodes.1.id", "7002"),
+				),
+			},
+			// Update testing, a worker node is added and the existing nodes are kept
+			{
+				Config: testAccKubernetesResourceConfig(providerConfig, "worker-1", "worker-2", "worker-3"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("emma_kubernetes_cluster.test", "id", "4001"),
+					resource.TestCheckResourceAttr("emma_kubernetes_cluster.test", "worker_nodes.#", "3"),
+					resource.TestCheckResourceAttr("emma_kubernetes_cluster.test", "worker_nodes.0.id", "7001"),
+					resource.TestCheckResourceAttr("emma_kubernetes_cluster.test", "worker_nodes.2.id", "7003"),
+				),
+			},
+			// ImportState testing, the names of the imported worker nodes are the names generated by emma
+			{
+				ResourceName:      "emma_kubernetes_cluster.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{"worker_nodes.0.name", "worker_nodes.1.name",
+					"worker_nodes.2.name"},
+			},
+		},
+	})
+}
@@ -0,0 +1,119 @@
+package emma
+
+import (
+	"context"
+	"fmt"
+	emmaSdk "github.com/emma-community/emma-go-sdk"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// labelsDescription is the description of the labels attribute, the emma API doesn't accept labels yet.
+const labelsDescription = "Labels of the %s, merged with the default_labels of the provider into effective_labels. " +
+	"The emma API doesn't accept labels yet, so they are kept in the Terraform state"
+
+// effectiveLabelsDescription is the description of the effective_labels attribute.
+const effectiveLabelsDescription = "Labels of the %s merged with the default_labels of the provider, " +
+	"the labels of the resource override the default labels with the same key. They are kept in the Terraform state only"
+
+// computeEffectiveLabelsDescription is the description of the effective_labels attribute of the compute instances,
+// emma returns their tags.
+const computeEffectiveLabelsDescription = effectiveLabelsDescription + ", the tags set in emma that differ from them " +
+	"are reported as a warning during refresh"
+
+// stateEffectiveLabelsDescription is the description of the effective_labels attribute of the resources whose
+// tags aren't returned by emma.
+const stateEffectiveLabelsDescription = effectiveLabelsDescription + ", their drift isn't detected because emma " +
+	"doesn't return the tags of the %s"
+
+// planEffectiveLabels sets the effective_labels attribute of the planned resource.
+func planEffectiveLabels(ctx context.Context, defaultLabels map[string]string, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// the resource is destroyed
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var labels types.Map
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("labels"), &labels)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var effectiveLabels types.Map
+	applyEffectiveLabels(ctx, defaultLabels, labels, &effectiveLabels, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("effective_labels"), effectiveLabels)...)
+}
+
+// applyEffectiveLabels merges the default labels and the labels of the resource into the effective labels,
+// the effective labels are null if there are no labels at all.
+func applyEffectiveLabels(ctx context.Context, defaultLabels map[string]string, labels types.Map, effectiveLabels *types.Map, diags *diag.Diagnostics) {
+	if labels.IsUnknown() {
+		*effectiveLabels = types.MapUnknown(types.StringType)
+		return
+	}
+
+	mergedLabels := maps.Clone(defaultLabels)
+	if mergedLabels == nil {
+		mergedLabels = make(map[string]string)
+	}
+	if !labels.IsNull() {
+		var resourceLabels map[string]string
+		diags.Append(labels.ElementsAs(ctx, &resourceLabels, false)...)
+		maps.Copy(mergedLabels, resourceLabels)
+	}
+	if len(mergedLabels) == 0 {
+		*effectiveLabels = types.MapNull(types.StringType)
+		return
+	}
+
+	mapValue, mapDiagnostic := types.MapValueFrom(ctx, types.StringType, mergedLabels)
+	diags.Append(mapDiagnostic...)
+	*effectiveLabels = mapValue
+}
+
+// tagsDrift returns the warning about the tags of the compute instance set in emma that differ from the effective
+// labels. The effective labels are kept in the state only, so the tags are reported without changing the plan.
+// Nothing is reported if emma doesn't return any tags.
+func tagsDrift(ctx context.Context, resourceName string, id string, effectiveLabels types.Map, tags []emmaSdk.Tag) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if len(tags) == 0 || effectiveLabels.IsUnknown() {
+		return diags
+	}
+	labels := make(map[string]string)
+	if !effectiveLabels.IsNull() {
+		diags.Append(effectiveLabels.ElementsAs(ctx, &labels, false)...)
+	}
+	tagValues := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		// a tag without a value is a label with an empty value
+		value := ""
+		if tag.Value.Get() != nil {
+			value = *tag.Value.Get()
+		}
+		tagValues[tag.Key] = value
+	}
+
+	var driftedKeys []string
+	for key, value := range tagValues {
+		if labelValue, ok := labels[key]; !ok || labelValue != value {
+			driftedKeys = append(driftedKeys, key)
+		}
+	}
+	for key := range labels {
+		if _, ok := tagValues[key]; !ok {
+			driftedKeys = append(driftedKeys, key)
+		}
+	}
+	if len(driftedKeys) > 0 {
+		slices.Sort(driftedKeys)
+		diags.AddWarning("Tags Drift", fmt.Sprintf("The tags of the %s %s set in emma differ from effective_labels in the keys %s, "+
+			"the labels are kept in the Terraform state only and the tags aren't changed by the provider",
+			resourceName, id, strings.Join(driftedKeys, ", ")))
+	}
+	return diags
+}
@@ -0,0 +1,110 @@
+package emma
+
+import (
+	"context"
+	emmaSdk "github.com/emma-community/emma-go-sdk"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestApplyEffectiveLabels(t *testing.T) {
+	ctx := context.Background()
+	defaultLabels := map[string]string{"team": "a", "env": "dev"}
+
+	var effectiveLabels types.Map
+	var diags diag.Diagnostics
+	applyEffectiveLabels(ctx, defaultLabels, types.MapValueMust(types.StringType, map[string]attr.Value{
+		"env": types.StringValue("prod"), "app": types.StringValue("web"),
+	}), &effectiveLabels, &diags)
+	assert.False(t, diags.HasError(), diags)
+	// the labels of the resource override the default labels
+	assert.Equal(t, types.MapValueMust(types.StringType, map[string]attr.Value{
+		"team": types.StringValue("a"), "env": types.StringValue("prod"), "app": types.StringValue("web"),
+	}), effectiveLabels)
+
+	applyEffectiveLabels(ctx, defaultLabels, types.MapNull(types.StringType), &effectiveLabels, &diags)
+	assert.Equal(t, types.MapValueMust(types.StringType, map[string]attr.Value{
+		"team": types.StringValue("a"), "env": types.StringValue("dev"),
+	}), effectiveLabels)
+
+	applyEffectiveLabels(ctx, nil, types.MapNull(types.StringType), &effectiveLabels, &diags)
+	assert.True(t, effectiveLabels.IsNull())
+
+	applyEffectiveLabels(ctx, defaultLabels, types.MapUnknown(types.StringType), &effectiveLabels, &diags)
+	assert.True(t, effectiveLabels.IsUnknown())
+	// the default labels aren't changed
+	assert.Equal(t, map[string]string{"team": "a", "env": "dev"}, defaultLabels)
+}
+
+func TestPlanEffectiveLabels(t *testing.T) {
+	ctx := context.Background()
+	labelsSchema := schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"labels":           schema.MapAttribute{ElementType: types.StringType, Optional: true},
+			"effective_labels": schema.MapAttribute{ElementType: types.StringType, Computed: true},
+		},
+	}
+	objectType := labelsSchema.Type().TerraformType(ctx)
+	plan := tftypes.NewValue(objectType, map[string]tftypes.Value{
+		"labels": tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, map[string]tftypes.Value{
+			"app": tftypes.NewValue(tftypes.String, "web"),
+		}),
+		"effective_labels": tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, tftypes.UnknownValue),
+	})
+	req := resource.ModifyPlanRequest{
+		Plan:  tfsdk.Plan{Schema: labelsSchema, Raw: plan},
+		State: tfsdk.State{Schema: labelsSchema, Raw: tftypes.NewValue(objectType, nil)},
+	}
+	resp := resource.ModifyPlanResponse{Plan: tfsdk.Plan{Schema: labelsSchema, Raw: plan.Copy()}}
+	planEffectiveLabels(ctx, map[string]string{"team": "a"}, req, &resp)
+	require.False(t, resp.Diagnostics.HasError(), resp.Diagnostics)
+
+	var effectiveLabels types.Map
+	require.False(t, resp.Plan.GetAttribute(ctx, path.Root("effective_labels"), &effectiveLabels).HasError())
+	assert.Equal(t, types.MapValueMust(types.StringType, map[string]attr.Value{
+		"team": types.StringValue("a"), "app": types.StringValue("web"),
+	}), effectiveLabels)
+
+	// the resource is destroyed
+	req.Plan.Raw = tftypes.NewValue(objectType, nil)
+	resp = resource.ModifyPlanResponse{Plan: tfsdk.Plan{Schema: labelsSchema, Raw: req.Plan.Raw}}
+	planEffectiveLabels(ctx, map[string]string{"team": "a"}, req, &resp)
+	assert.False(t, resp.Diagnostics.HasError(), resp.Diagnostics)
+	assert.True(t, resp.Plan.Raw.IsNull())
+}
+
+func TestTagsDrift(t *testing.T) {
+	ctx := context.Background()
+	effectiveLabels := types.MapValueMust(types.StringType, map[string]attr.Value{
+		"team": types.StringValue("a"), "app": types.StringValue(""),
+	})
+	tag := func(key string, value *string) emmaSdk.Tag {
+		return emmaSdk.Tag{Key: key, Value: *emmaSdk.NewNullableString(value)}
+	}
+
+	// a tag without a value is a label with an empty value
+	diags := tagsDrift(ctx, "virtual machine", "1001", effectiveLabels, []emmaSdk.Tag{tag("team", emmaSdk.PtrString("a")), tag("app", nil)})
+	assert.Empty(t, diags)
+
+	// emma doesn't return the tags
+	assert.Empty(t, tagsDrift(ctx, "virtual machine", "1001", effectiveLabels, nil))
+
+	diags = tagsDrift(ctx, "virtual machine", "1001", effectiveLabels, []emmaSdk.Tag{tag("team", emmaSdk.PtrString("b")), tag("env", emmaSdk.PtrString("dev"))})
+	require.Len(t, diags, 1)
+	assert.Equal(t, diag.SeverityWarning, diags[0].Severity())
+	assert.Equal(t, "The tags of the virtual machine 1001 set in emma differ from effective_labels in the keys app, env, team, "+
+		"the labels are kept in the Terraform state only and the tags aren't changed by the provider", diags[0].Detail())
+
+	diags = tagsDrift(ctx, "spot instance", "1002", types.MapNull(types.StringType), []emmaSdk.Tag{tag("env", emmaSdk.PtrString("dev"))})
+	require.Len(t, diags, 1)
+	assert.Contains(t, diags[0].Detail(), "spot instance 1002")
+}
@@ -3,8 +3,8 @@ package emma
 import (
 	"context"
 	"fmt"
-	"github.com/emma-community/terraform-provider-emma/tools"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"net/url"
 
 	emmaSdk "github.com/emma-community/emma-go-sdk"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
@@ -22,6 +22,7 @@ func NewLocationDataSource() datasource.DataSource {
 type locationDataSource struct {
 	apiClient *emmaSdk.APIClient
 	token     *emmaSdk.Token
+	catalog   *catalogCache
 }
 
 // locationDataSourceModel describes the data source data model.
@@ -66,6 +67,7 @@ func (d *locationDataSource) Configure(ctx context.Context, req datasource.Confi
 	}
 	d.apiClient = client.apiClient
 	d.token = client.token
+	d.catalog = client.catalog
 }
 
 func (d *locationDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
@@ -83,13 +85,13 @@ func (d *locationDataSource) Read(ctx context.Context, req datasource.ReadReques
 	// If applicable, this is a great opportunity to initialize any necessary
 	// provider client data and make a call using it.
 	auth := context.WithValue(ctx, emmaSdk.ContextAccessToken, *d.token.AccessToken)
+
 	request := d.apiClient.LocationsAPI.GetLocations(auth)
 	request = request.Name(data.Name.ValueString())
-	locations, response, err := request.Execute()
+	locations, err := readCatalog(ctx, d.catalog, "/v1/locations", url.Values{"name": {data.Name.ValueString()}}, request.Execute)
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error",
-			fmt.Sprintf("Unable to read location, got error: %s",
-				tools.ExtractErrorMessage(response)))
+			fmt.Sprintf("Unable to read location, got error: %s", err))
 		return
 	}
 	if len(locations) == 0 {
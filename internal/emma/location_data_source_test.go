@@ -0,0 +1,24 @@
+package emma
+
+import (
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"testing"
+)
+
+func TestAccLocationDataSource(t *testing.T) {
+	providerConfig := testAccProviderConfig(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+data "emma_location" "test" {
+  name = "Frankfurt"
+}
+`,
+				Check: resource.TestCheckResourceAttr("data.emma_location.test", "id", "2"),
+			},
+		},
+	})
+}
@@ -0,0 +1,113 @@
+package emma
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	emma "github.com/emma-community/terraform-provider-emma/internal/emma/validation"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"math/big"
+	"strings"
+)
+
+// namingSuffixAlphabet contains the characters of the random name suffix, they are valid in any resource name.
+const namingSuffixAlphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// namingPolicy is the provider naming block that is applied to the names of the resources.
+type namingPolicy struct {
+	prefix       string
+	suffixRandom int
+}
+
+// fullName returns the name of the resource in emma: the prefix, the name and a new random suffix separated by a hyphen.
+func (n namingPolicy) fullName(name string) string {
+	if n.suffixRandom == 0 {
+		return n.prefix + name
+	}
+	suffix := make([]byte, n.suffixRandom)
+	for i := range suffix {
+		index, _ := rand.Int(rand.Reader, big.NewInt(int64(len(namingSuffixAlphabet))))
+		suffix[i] = namingSuffixAlphabet[index.Int64()]
+	}
+	return n.prefix + name + "-" + string(suffix)
+}
+
+// planFullName sets the full_name attribute of the planned resource. The full name is kept while the name
+// isn't changed. A new full name with a random suffix is unknown until apply, because Terraform plans
+// the resource again during apply and the random suffix would differ; the placeholder of the same length is
+// validated with the name rule of the resource type instead.
+func planFullName(ctx context.Context, naming namingPolicy, rule emma.NameRule, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// the resource is destroyed
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var name types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("name"), &name)...)
+	if resp.Diagnostics.HasError() || name.IsUnknown() || name.IsNull() {
+		return
+	}
+
+	if !req.State.Raw.IsNull() {
+		var stateName, stateFullName types.String
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("name"), &stateName)...)
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("full_name"), &stateFullName)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if stateName.Equal(name) {
+			// the state of the resources created before the naming policy doesn't contain full_name
+			if stateFullName.IsNull() || stateFullName.IsUnknown() {
+				stateFullName = stateName
+			}
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("full_name"), stateFullName)...)
+			return
+		}
+	}
+
+	fullName := naming.fullName(name.ValueString())
+	if naming.suffixRandom > 0 {
+		fullName = naming.prefix + name.ValueString() + "-" + strings.Repeat("x", naming.suffixRandom)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("full_name"), types.StringUnknown())...)
+	} else {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("full_name"), fullName)...)
+	}
+
+	if err := rule.Validate(fullName); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("name"), "Validation Error",
+			fmt.Sprintf("name %q with the provider naming prefix and suffix %s", fullName, err))
+	}
+}
+
+// applyFullName sets the full name of the resource that is unknown until apply.
+func applyFullName(naming namingPolicy, name types.String, fullName *types.String) {
+	if fullName.IsUnknown() || fullName.IsNull() {
+		*fullName = types.StringValue(naming.fullName(name.ValueString()))
+	}
+}
+
+// readName returns the name and the full name of the resource with the name returned by emma. The name is kept
+// while the resource isn't renamed outside of Terraform, otherwise the name is the emma name without the prefix
+// and the random suffix of the naming policy, e.g. after import.
+func readName(naming namingPolicy, name types.String, fullName types.String, emmaName string) (types.String, types.String) {
+	if !name.IsNull() && !name.IsUnknown() && fullName.ValueString() == emmaName {
+		return name, fullName
+	}
+	return types.StringValue(naming.name(emmaName)), types.StringValue(emmaName)
+}
+
+// name returns the name of the resource with the full name in emma, the prefix and the random suffix are removed
+// if the full name contains them.
+func (n namingPolicy) name(fullName string) string {
+	name := strings.TrimPrefix(fullName, n.prefix)
+	if n.suffixRandom == 0 || len(name) <= n.suffixRandom+1 {
+		return name
+	}
+	suffix := name[len(name)-n.suffixRandom:]
+	if name[len(name)-n.suffixRandom-1] != '-' || strings.Trim(suffix, namingSuffixAlphabet) != "" {
+		return name
+	}
+	return name[:len(name)-n.suffixRandom-1]
+}
@@ -0,0 +1,143 @@
+package emma
+
+import (
+	"context"
+	emma "github.com/emma-community/terraform-provider-emma/internal/emma/validation"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var namingTestSchema = schema.Schema{
+	Attributes: map[string]schema.Attribute{
+		"name":      schema.StringAttribute{Required: true},
+		"full_name": schema.StringAttribute{Computed: true},
+	},
+}
+
+// namingTestValue returns the raw value of the naming test schema, a nil name returns the null object.
+func namingTestValue(name *string, fullName tftypes.Value) tftypes.Value {
+	objectType := namingTestSchema.Type().TerraformType(context.Background())
+	if name == nil {
+		return tftypes.NewValue(objectType, nil)
+	}
+	return tftypes.NewValue(objectType, map[string]tftypes.Value{
+		"name":      tftypes.NewValue(tftypes.String, *name),
+		"full_name": fullName,
+	})
+}
+
+// planFullNameTest plans the full name of the resource with the planned name and the state, nil values plan
+// the creation or the destruction of the resource.
+func planFullNameTest(t *testing.T, naming namingPolicy, rule emma.NameRule, planName *string, state tftypes.Value) resource.ModifyPlanResponse {
+	ctx := context.Background()
+	plan := namingTestValue(planName, tftypes.NewValue(tftypes.String, tftypes.UnknownValue))
+	req := resource.ModifyPlanRequest{
+		Plan:  tfsdk.Plan{Schema: namingTestSchema, Raw: plan},
+		State: tfsdk.State{Schema: namingTestSchema, Raw: state},
+	}
+	resp := resource.ModifyPlanResponse{Plan: tfsdk.Plan{Schema: namingTestSchema, Raw: plan.Copy()}}
+	planFullName(ctx, naming, rule, req, &resp)
+	return resp
+}
+
+func plannedFullName(t *testing.T, resp resource.ModifyPlanResponse) types.String {
+	var fullName types.String
+	require.False(t, resp.Plan.GetAttribute(context.Background(), path.Root("full_name"), &fullName).HasError())
+	return fullName
+}
+
+func TestPlanFullName(t *testing.T) {
+	name := "web"
+	noState := namingTestValue(nil, tftypes.Value{})
+
+	resp := planFullNameTest(t, namingPolicy{prefix: "team-a-"}, emma.ComputeNameRule, &name, noState)
+	assert.False(t, resp.Diagnostics.HasError(), resp.Diagnostics)
+	assert.Equal(t, "team-a-web", plannedFullName(t, resp).ValueString())
+
+	// the random suffix is unknown until apply
+	resp = planFullNameTest(t, namingPolicy{prefix: "team-a-", suffixRandom: 4}, emma.ComputeNameRule, &name, noState)
+	assert.False(t, resp.Diagnostics.HasError(), resp.Diagnostics)
+	assert.True(t, plannedFullName(t, resp).IsUnknown())
+
+	// the full name of the state is kept while the name isn't changed
+	state := namingTestValue(&name, tftypes.NewValue(tftypes.String, "team-a-web-x1y2"))
+	resp = planFullNameTest(t, namingPolicy{prefix: "team-a-", suffixRandom: 4}, emma.ComputeNameRule, &name, state)
+	assert.Equal(t, "team-a-web-x1y2", plannedFullName(t, resp).ValueString())
+
+	// the state created before the naming policy doesn't contain the full name
+	state = namingTestValue(&name, tftypes.NewValue(tftypes.String, nil))
+	resp = planFullNameTest(t, namingPolicy{prefix: "team-a-"}, emma.ComputeNameRule, &name, state)
+	assert.Equal(t, "web", plannedFullName(t, resp).ValueString())
+
+	// the resource is destroyed
+	resp = planFullNameTest(t, namingPolicy{prefix: "team-a-"}, emma.ComputeNameRule, nil, state)
+	assert.False(t, resp.Diagnostics.HasError(), resp.Diagnostics)
+}
+
+func TestPlanFullNameRule(t *testing.T) {
+	noState := namingTestValue(nil, tftypes.Value{})
+
+	// the name with the prefix and the suffix is validated with the rule of the resource type
+	name := strings.Repeat("web", 19)
+	resp := planFullNameTest(t, namingPolicy{prefix: "team-a-", suffixRandom: 4}, emma.ComputeNameRule, &name, noState)
+	assert.True(t, resp.Diagnostics.HasError())
+
+	sshKeyName := "Deploy.Key"
+	resp = planFullNameTest(t, namingPolicy{prefix: "team-a-", suffixRandom: 4}, emma.SshKeyNameRule, &sshKeyName, noState)
+	assert.False(t, resp.Diagnostics.HasError(), resp.Diagnostics)
+	resp = planFullNameTest(t, namingPolicy{prefix: "team-a-", suffixRandom: 4}, emma.ComputeNameRule, &sshKeyName, noState)
+	assert.True(t, resp.Diagnostics.HasError())
+}
+
+func TestApplyFullName(t *testing.T) {
+	fullName := types.StringUnknown()
+	applyFullName(namingPolicy{prefix: "team-a-", suffixRandom: 4}, types.StringValue("web"), &fullName)
+	assert.Regexp(t, regexp.MustCompile(`^team-a-web-[0-9a-z]{4}$`), fullName.ValueString())
+
+	fullName = types.StringUnknown()
+	applyFullName(namingPolicy{}, types.StringValue("web"), &fullName)
+	assert.Equal(t, "web", fullName.ValueString())
+
+	// the planned full name is kept
+	fullName = types.StringValue("team-a-web-x1y2")
+	applyFullName(namingPolicy{prefix: "team-a-", suffixRandom: 4}, types.StringValue("web"), &fullName)
+	assert.Equal(t, "team-a-web-x1y2", fullName.ValueString())
+}
+
+func TestReadName(t *testing.T) {
+	naming := namingPolicy{prefix: "team-a-", suffixRandom: 4}
+
+	name, fullName := readName(naming, types.StringValue("web"), types.StringValue("team-a-web-x1y2"), "team-a-web-x1y2")
+	assert.Equal(t, "web", name.ValueString())
+	assert.Equal(t, "team-a-web-x1y2", fullName.ValueString())
+
+	// the imported resource doesn't have the name in the state
+	name, fullName = readName(naming, types.StringNull(), types.StringNull(), "team-a-web-x1y2")
+	assert.Equal(t, "web", name.ValueString())
+	assert.Equal(t, "team-a-web-x1y2", fullName.ValueString())
+
+	name, _ = readName(namingPolicy{prefix: "team-a-"}, types.StringNull(), types.StringNull(), "team-a-web")
+	assert.Equal(t, "web", name.ValueString())
+
+	// the prefix and the random suffix are removed only if the emma name contains them
+	for emmaName, expectedName := range map[string]string{"web": "web", "team-a-web": "web", "web-x1y2": "web",
+		"team-a-web-Prod": "web-Prod", "team-a-x1y2": "x1y2", "team-a-web_x1y2": "web_x1y2"} {
+		name, fullName = readName(naming, types.StringNull(), types.StringNull(), emmaName)
+		assert.Equal(t, expectedName, name.ValueString(), emmaName)
+		assert.Equal(t, emmaName, fullName.ValueString())
+	}
+
+	// the resource renamed outside of Terraform
+	name, fullName = readName(naming, types.StringValue("web"), types.StringValue("team-a-web-x1y2"), "team-a-api-z9z9")
+	assert.Equal(t, "api", name.ValueString())
+	assert.Equal(t, "team-a-api-z9z9", fullName.ValueString())
+}
@@ -3,7 +3,7 @@ package emma
 import (
 	"context"
 	"fmt"
-	"github.com/emma-community/terraform-provider-emma/tools"
+	"net/url"
 
 	emmaSdk "github.com/emma-community/emma-go-sdk"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
@@ -22,6 +22,7 @@ func NewOperatingSystemDataSource() datasource.DataSource {
 type operatingSystemDataSource struct {
 	apiClient *emmaSdk.APIClient
 	token     *emmaSdk.Token
+	catalog   *catalogCache
 }
 
 // operatingSystemDataSourceModel describes the data source data model.
@@ -85,6 +86,7 @@ func (d *operatingSystemDataSource) Configure(ctx context.Context, req datasourc
 	}
 	d.apiClient = client.apiClient
 	d.token = client.token
+	d.catalog = client.catalog
 }
 
 func (d *operatingSystemDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
@@ -102,15 +104,20 @@ func (d *operatingSystemDataSource) Read(ctx context.Context, req datasource.Rea
 	// If applicable, this is a great opportunity to initialize any necessary
 	// provider client data and make a call using it.
 	auth := context.WithValue(ctx, emmaSdk.ContextAccessToken, *d.token.AccessToken)
+
 	request := d.apiClient.OperatingSystemsAPI.GetOperatingSystems(auth)
 	request = request.Version(data.Version.ValueString())
 	request = request.Type_(data.Type.ValueString())
 	request = request.Architecture(data.Architecture.ValueString())
-	operatingSystems, response, err := request.Execute()
+	query := url.Values{
+		"version":      {data.Version.ValueString()},
+		"type":         {data.Type.ValueString()},
+		"architecture": {data.Architecture.ValueString()},
+	}
+	operatingSystems, err := readCatalog(ctx, d.catalog, "/v1/operating-systems", query, request.Execute)
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error",
-			fmt.Sprintf("Unable to read operating system, got error: %s",
-				tools.ExtractErrorMessage(response)))
+			fmt.Sprintf("Unable to read operating system, got error: %s", err))
 		return
 	}
 	if len(operatingSystems) == 0 {
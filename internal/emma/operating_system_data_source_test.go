@@ -0,0 +1,29 @@
+package emma
+
+import (
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"testing"
+)
+
+func TestAccOperatingSystemDataSource(t *testing.T) {
+	providerConfig := testAccProviderConfig(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+data "emma_operating_system" "test" {
+  type         = "Ubuntu"
+  architecture = "x86-64"
+  version      = "22.04"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.emma_operating_system.test", "id", "2"),
+					resource.TestCheckResourceAttr("data.emma_operating_system.test", "family", "Linux"),
+				),
+			},
+		},
+	})
+}
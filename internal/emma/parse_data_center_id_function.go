@@ -0,0 +1,113 @@
+package emma
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"regexp"
+)
+
+var (
+	// dataCenterIdRegex matches the data center IDs like "digitalocean-sgp1", "gcp-europe-west8-a" etc...
+	dataCenterIdRegex = regexp.MustCompile(`^([a-z]+)-([a-z0-9]+(?:-[a-z0-9]+)*)$`)
+	// letterZoneRegex matches the zones with a letter suffix like "europe-west8-a" in the region "europe-west8"
+	letterZoneRegex = regexp.MustCompile(`^(.+-[a-z]*[0-9]+)-[a-z]$`)
+	// numberedZoneRegex matches the zones with a letter after the region number like "eu-north-1a" in the region "eu-north-1"
+	numberedZoneRegex = regexp.MustCompile(`^(.+-[0-9]+)[a-z]$`)
+)
+
+var _ function.Function = &parseDataCenterIdFunction{}
+
+func NewParseDataCenterIdFunction() function.Function {
+	return &parseDataCenterIdFunction{}
+}
+
+// parseDataCenterIdFunction defines the function implementation.
+type parseDataCenterIdFunction struct{}
+
+// parseDataCenterIdFunctionModel describes the function result model.
+type parseDataCenterIdFunctionModel struct {
+	Provider     types.String `tfsdk:"provider"`
+	ProviderName types.String `tfsdk:"provider_name"`
+	Region       types.String `tfsdk:"region"`
+	Zone         types.String `tfsdk:"zone"`
+}
+
+func (m parseDataCenterIdFunctionModel) attrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"provider":      types.StringType,
+		"provider_name": types.StringType,
+		"region":        types.StringType,
+		"zone":          types.StringType,
+	}
+}
+
+func (f *parseDataCenterIdFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "parse_data_center_id"
+}
+
+func (f *parseDataCenterIdFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Parse an emma data center ID",
+		Description: "Splits the ID of an emma data center like \"gcp-europe-west8-a\" into the prefix of the cloud provider, " +
+			"the emma provider name, the region and the zone of the data center.\n\n" +
+			"The zone is null if the ID of the data center has no zone suffix, for example \"digitalocean-sgp1\". " +
+			"The provider name is null if the prefix of the cloud provider isn't known to the provider.",
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "data_center_id",
+				Description: "ID of the data center, e.g. \"gcp-europe-west8-a\" or \"aws-eu-north-1\".",
+			},
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: parseDataCenterIdFunctionModel{}.attrTypes(),
+		},
+	}
+}
+
+func (f *parseDataCenterIdFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var dataCenterId string
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &dataCenterId))
+	if resp.Error != nil {
+		return
+	}
+
+	result, ok := parseDataCenterId(dataCenterId)
+	if !ok {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Invalid data center ID %q, "+
+			"must be the prefix of the cloud provider and the location, e.g. \"gcp-europe-west8-a\"", dataCenterId))
+		return
+	}
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, result))
+}
+
+// parseDataCenterId splits the data center ID into the prefix of the cloud provider and the location,
+// the zone is recognised by the letter suffix of the location in the GCP and AWS formats.
+func parseDataCenterId(dataCenterId string) (parseDataCenterIdFunctionModel, bool) {
+	matches := dataCenterIdRegex.FindStringSubmatch(dataCenterId)
+	if matches == nil {
+		return parseDataCenterIdFunctionModel{}, false
+	}
+	prefix, location := matches[1], matches[2]
+
+	result := parseDataCenterIdFunctionModel{
+		Provider:     types.StringValue(prefix),
+		ProviderName: types.StringNull(),
+		Region:       types.StringValue(location),
+		Zone:         types.StringNull(),
+	}
+	if providerName, ok := dataCenterProviders[prefix]; ok {
+		result.ProviderName = types.StringValue(providerName)
+	}
+	for _, zoneRegex := range []*regexp.Regexp{letterZoneRegex, numberedZoneRegex} {
+		if zoneMatches := zoneRegex.FindStringSubmatch(location); zoneMatches != nil {
+			result.Region = types.StringValue(zoneMatches[1])
+			result.Zone = types.StringValue(location)
+			break
+		}
+	}
+	return result, true
+}
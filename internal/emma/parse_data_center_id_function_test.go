@@ -0,0 +1,116 @@
+package emma
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	testResource "github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func runParseDataCenterIdFunction(dataCenterId string) function.RunResponse {
+	resp := function.RunResponse{Result: function.NewResultData(types.ObjectUnknown(parseDataCenterIdFunctionModel{}.attrTypes()))}
+	NewParseDataCenterIdFunction().Run(context.Background(), function.RunRequest{
+		Arguments: function.NewArgumentsData([]attr.Value{types.StringValue(dataCenterId)}),
+	}, &resp)
+	return resp
+}
+
+func TestParseDataCenterIdFunction(t *testing.T) {
+	tests := []struct {
+		dataCenterId string
+		expected     parseDataCenterIdFunctionModel
+	}{
+		{"gcp-europe-west8-a", parseDataCenterIdFunctionModel{
+			Provider:     types.StringValue("gcp"),
+			ProviderName: types.StringValue("Google Cloud Platform"),
+			Region:       types.StringValue("europe-west8"),
+			Zone:         types.StringValue("europe-west8-a"),
+		}},
+		{"aws-eu-north-1", parseDataCenterIdFunctionModel{
+			Provider:     types.StringValue("aws"),
+			ProviderName: types.StringValue("Amazon EC2"),
+			Region:       types.StringValue("eu-north-1"),
+			Zone:         types.StringNull(),
+		}},
+		{"aws-eu-north-1a", parseDataCenterIdFunctionModel{
+			Provider:     types.StringValue("aws"),
+			ProviderName: types.StringValue("Amazon EC2"),
+			Region:       types.StringValue("eu-north-1"),
+			Zone:         types.StringValue("eu-north-1a"),
+		}},
+		{"azure-westeurope", parseDataCenterIdFunctionModel{
+			Provider:     types.StringValue("azure"),
+			ProviderName: types.StringValue("Microsoft Azure"),
+			Region:       types.StringValue("westeurope"),
+			Zone:         types.StringNull(),
+		}},
+		{"digitalocean-sgp1", parseDataCenterIdFunctionModel{
+			Provider:     types.StringValue("digitalocean"),
+			ProviderName: types.StringValue("DigitalOcean"),
+			Region:       types.StringValue("sgp1"),
+			Zone:         types.StringNull(),
+		}},
+		{"other-region-1", parseDataCenterIdFunctionModel{
+			Provider:     types.StringValue("other"),
+			ProviderName: types.StringNull(),
+			Region:       types.StringValue("region-1"),
+			Zone:         types.StringNull(),
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.dataCenterId, func(t *testing.T) {
+			resp := runParseDataCenterIdFunction(test.dataCenterId)
+			require.Nil(t, resp.Error)
+
+			expected, diags := types.ObjectValueFrom(context.Background(), parseDataCenterIdFunctionModel{}.attrTypes(), test.expected)
+			require.False(t, diags.HasError())
+			assert.Equal(t, expected, resp.Result.Value())
+		})
+	}
+}
+
+func TestParseDataCenterIdFunctionInvalidValues(t *testing.T) {
+	for _, dataCenterId := range []string{"", "gcp", "gcp-", "-europe-west8-a", "GCP-europe-west8-a", "gcp-europe--west8"} {
+		resp := runParseDataCenterIdFunction(dataCenterId)
+		require.NotNil(t, resp.Error, "Is valid data center ID: "+dataCenterId)
+		assert.Equal(t, int64(0), *resp.Error.FunctionArgument)
+	}
+}
+
+func TestAccParseDataCenterIdFunction(t *testing.T) {
+	testResource.Test(t, testResource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		TerraformVersionChecks:   []tfversion.TerraformVersionCheck{tfversion.SkipBelow(tfversion.Version1_8_0)},
+		Steps: []testResource.TestStep{
+			{
+				Config: testAccProviderConfig(t) + `
+locals {
+  data_center = provider::emma::parse_data_center_id("gcp-europe-west8-a")
+}
+
+output "provider" {
+  value = local.data_center.provider
+}
+
+output "region" {
+  value = local.data_center.region
+}
+
+output "zone" {
+  value = local.data_center.zone
+}
+`,
+				Check: testResource.ComposeAggregateTestCheckFunc(
+					testResource.TestCheckOutput("provider", "gcp"),
+					testResource.TestCheckOutput("region", "europe-west8"),
+					testResource.TestCheckOutput("zone", "europe-west8-a"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,73 @@
+package emma
+
+import (
+	"context"
+	"fmt"
+	emma "github.com/emma-community/terraform-provider-emma/internal/emma/validation"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ function.Function = &portRangeFunction{}
+
+func NewPortRangeFunction() function.Function {
+	return &portRangeFunction{}
+}
+
+// portRangeFunction defines the function implementation.
+type portRangeFunction struct{}
+
+// portRangeFunctionModel describes the function result model.
+type portRangeFunctionModel struct {
+	From types.Int64 `tfsdk:"from"`
+	To   types.Int64 `tfsdk:"to"`
+}
+
+func (m portRangeFunctionModel) attrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"from": types.Int64Type,
+		"to":   types.Int64Type,
+	}
+}
+
+func (f *portRangeFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "port_range"
+}
+
+func (f *portRangeFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Parse the ports of a security group rule",
+		Description: "Returns the first and the last port of the ports of an emma_security_group rule: " +
+			"\"all\" is the range from 0 to 65535 and a single port like \"22\" is the range from 22 to 22.",
+
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "ports",
+				Description: "Ports of the security group rule, may contain next values: all, 3000 or 1-3010.",
+			},
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: portRangeFunctionModel{}.attrTypes(),
+		},
+	}
+}
+
+func (f *portRangeFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var ports string
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &ports))
+	if resp.Error != nil {
+		return
+	}
+
+	from, to, ok := emma.ParsePortRange(ports)
+	if !ok {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Invalid ports %q, may contain next values: all, 3000 or 1-3010", ports))
+		return
+	}
+	result := portRangeFunctionModel{
+		From: types.Int64Value(int64(from)),
+		To:   types.Int64Value(int64(to)),
+	}
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, result))
+}
@@ -0,0 +1,74 @@
+package emma
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	testResource "github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func runPortRangeFunction(ports string) function.RunResponse {
+	resp := function.RunResponse{Result: function.NewResultData(types.ObjectUnknown(portRangeFunctionModel{}.attrTypes()))}
+	NewPortRangeFunction().Run(context.Background(), function.RunRequest{
+		Arguments: function.NewArgumentsData([]attr.Value{types.StringValue(ports)}),
+	}, &resp)
+	return resp
+}
+
+func TestPortRangeFunction(t *testing.T) {
+	tests := []struct {
+		ports string
+		from  int64
+		to    int64
+	}{
+		{"all", 0, 65535},
+		{"22", 22, 22},
+		{"3000-3010", 3000, 3010},
+	}
+	for _, test := range tests {
+		resp := runPortRangeFunction(test.ports)
+		require.Nil(t, resp.Error, "Is invalid port value: "+test.ports)
+
+		expected, diags := types.ObjectValueFrom(context.Background(), portRangeFunctionModel{}.attrTypes(),
+			portRangeFunctionModel{From: types.Int64Value(test.from), To: types.Int64Value(test.to)})
+		require.False(t, diags.HasError())
+		assert.Equal(t, expected, resp.Result.Value(), "Invalid port range of: "+test.ports)
+	}
+}
+
+func TestPortRangeFunctionInvalidValues(t *testing.T) {
+	for _, ports := range []string{"", "any", "65536", "3010-3000", "1-2-3"} {
+		resp := runPortRangeFunction(ports)
+		require.NotNil(t, resp.Error, "Is valid port value: "+ports)
+		assert.Equal(t, int64(0), *resp.Error.FunctionArgument)
+	}
+}
+
+func TestAccPortRangeFunction(t *testing.T) {
+	testResource.Test(t, testResource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		TerraformVersionChecks:   []tfversion.TerraformVersionCheck{tfversion.SkipBelow(tfversion.Version1_8_0)},
+		Steps: []testResource.TestStep{
+			{
+				Config: testAccProviderConfig(t) + `
+output "from" {
+  value = provider::emma::port_range("3000-3010").from
+}
+
+output "to" {
+  value = provider::emma::port_range("3000-3010").to
+}
+`,
+				Check: testResource.ComposeAggregateTestCheckFunc(
+					testResource.TestCheckOutput("from", "3000"),
+					testResource.TestCheckOutput("to", "3010"),
+				),
+			},
+		},
+	})
+}
@@ -6,17 +6,30 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"os"
+	"path/filepath"
+	"slices"
 
 	emmaSdk "github.com/emma-community/emma-go-sdk"
+	emma "github.com/emma-community/terraform-provider-emma/internal/emma/validation"
+	"github.com/emma-community/terraform-provider-emma/internal/mock"
+	"github.com/emma-community/terraform-provider-emma/tools"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/function"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"net/http"
+	"strings"
+	"time"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ provider.Provider = &Provider{}
+	_ provider.Provider                       = &Provider{}
+	_ provider.ProviderWithEphemeralResources = &Provider{}
+	_ provider.ProviderWithFunctions          = &Provider{}
 )
 
 func New() func() provider.Provider {
@@ -26,9 +39,26 @@ func New() func() provider.Provider {
 }
 
 type providerModel struct {
-	Host         types.String `tfsdk:"host"`
-	ClientId     types.String `tfsdk:"client_id"`
-	ClientSecret types.String `tfsdk:"client_secret"`
+	Host                  types.String               `tfsdk:"host"`
+	ClientId              types.String               `tfsdk:"client_id"`
+	ClientSecret          types.String               `tfsdk:"client_secret"`
+	AllowedSshKeyTypes    types.List                 `tfsdk:"allowed_ssh_key_types"`
+	Naming                *providerNamingModel       `tfsdk:"naming"`
+	CatalogCache          *providerCatalogCacheModel `tfsdk:"catalog_cache"`
+	DefaultLabels         types.Map                  `tfsdk:"default_labels"`
+	MaxConcurrentRequests types.Int64                `tfsdk:"max_concurrent_requests"`
+	MaxConcurrentCreates  types.Int64                `tfsdk:"max_concurrent_creates"`
+	Mock                  types.Bool                 `tfsdk:"mock"`
+}
+
+type providerNamingModel struct {
+	Prefix       types.String `tfsdk:"prefix"`
+	SuffixRandom types.Int64  `tfsdk:"suffix_random"`
+}
+
+type providerCatalogCacheModel struct {
+	Directory types.String `tfsdk:"directory"`
+	Ttl       types.String `tfsdk:"ttl"`
 }
 
 // Provider is the provider implementation.
@@ -55,15 +85,92 @@ func (p *Provider) Schema(_ context.Context, _ provider.SchemaRequest, resp *pro
 				Required: false,
 			},
 			"client_id": schema.StringAttribute{
-				Optional:    false,
-				Required:    true,
-				Description: "Client ID from the Service application in the project",
+				Optional:    true,
+				Required:    false,
+				Description: "Client ID from the Service application in the project, required unless mock mode is enabled",
 			},
 			"client_secret": schema.StringAttribute{
-				Optional:    false,
-				Required:    true,
+				Optional:    true,
+				Required:    false,
 				Sensitive:   true,
-				Description: "Client secret from the Service application in the project",
+				Description: "Client secret from the Service application in the project, required unless mock mode is enabled",
+			},
+			"allowed_ssh_key_types": schema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Required:    false,
+				Description: "SSH key types that can be generated and imported, all key types are allowed if not set, the list must not be empty. " +
+					"Available values: " + strings.Join(emma.SshKeyTypeNames(), ", "),
+				Validators: []validator.List{emma.SshKeyTypeList{}},
+			},
+			"default_labels": schema.MapAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Required:    false,
+				Description: "Labels merged into the effective_labels of virtual machines, spot instances, security groups " +
+					"and kubernetes clusters, the labels of a resource override the default labels with the same key",
+			},
+			"max_concurrent_requests": schema.Int64Attribute{
+				Optional: true,
+				Required: false,
+				Description: "Maximum number of concurrent emma API requests, from 1 to 64, the requests aren't limited if not set. " +
+					"The requests above the limit are queued",
+				Validators: []validator.Int64{emma.Int64Between{Min: 1, Max: 64}},
+			},
+			"max_concurrent_creates": schema.Int64Attribute{
+				Optional: true,
+				Required: false,
+				Description: "Maximum number of virtual machines, spot instances and kubernetes clusters " +
+					"of each type that are created concurrently, from 1 to 32, the creations aren't limited if not set. " +
+					"The creations above the limit are queued",
+				Validators: []validator.Int64{emma.Int64Between{Min: 1, Max: 32}},
+			},
+			"mock": schema.BoolAttribute{
+				Optional: true,
+				Required: false,
+				Description: "Send the requests to an offline fake emma API embedded in the provider instead of the host, " +
+					"so configurations can be planned and applied without credentials and network. It can also be enabled " +
+					"with the EMMA_MOCK=1 environment variable. The fake resources are kept in the file of the " +
+					"EMMA_MOCK_STATE environment variable between the runs, or only while the provider runs if it isn't set",
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"naming": schema.SingleNestedBlock{
+				Description: "Naming policy applied to the names of virtual machines, spot instances, security groups, " +
+					"ssh keys and kubernetes clusters before they are validated and sent to emma. The resulting name " +
+					"is available in the full_name attribute of the resources",
+				Attributes: map[string]schema.Attribute{
+					"prefix": schema.StringAttribute{
+						Optional:    true,
+						Required:    false,
+						Description: "Prefix added to the names, for example team-a-",
+						Validators:  []validator.String{emma.ResourceNamePrefix{}},
+					},
+					"suffix_random": schema.Int64Attribute{
+						Optional:    true,
+						Required:    false,
+						Description: "Length of the random suffix of lowercase alphanumerics added to the names after a hyphen, from 0 to 8",
+						Validators:  []validator.Int64{emma.Int64Between{Min: 0, Max: 8}},
+					},
+				},
+			},
+			"catalog_cache": schema.SingleNestedBlock{
+				Description: "Cache of the data centers, operating systems, providers and locations read by the data sources. " +
+					"The lists are always cached in memory while the provider runs, and also on disk if the directory is set",
+				Attributes: map[string]schema.Attribute{
+					"directory": schema.StringAttribute{
+						Optional:    true,
+						Required:    false,
+						Description: "Directory of the on-disk cache, for example ~/.cache/terraform-provider-emma",
+						Validators:  []validator.String{emma.NotEmptyString{}},
+					},
+					"ttl": schema.StringAttribute{
+						Optional:    true,
+						Required:    false,
+						Description: "Time to live of the lists stored on disk, for example 30m or 24h, default " + defaultCatalogCacheTtl,
+						Validators:  []validator.String{emma.PositiveDuration{}},
+					},
+				},
 			},
 		},
 	}
@@ -121,6 +228,18 @@ func (p *Provider) Configure(ctx context.Context, req provider.ConfigureRequest,
 		clientSecret = config.ClientSecret.ValueString()
 	}
 
+	mockMode := os.Getenv("EMMA_MOCK") == "1"
+	if !config.Mock.IsNull() && !config.Mock.IsUnknown() {
+		mockMode = config.Mock.ValueBool()
+	}
+	// the fake emma API issues the access token for any credentials
+	if mockMode && clientId == "" {
+		clientId = "mock"
+	}
+	if mockMode && clientSecret == "" {
+		clientSecret = "mock"
+	}
+
 	// If any of the expected configurations are missing, return
 	// errors with provider-specific guidance.
 
@@ -149,10 +268,31 @@ func (p *Provider) Configure(ctx context.Context, req provider.ConfigureRequest,
 		host = emmaSdk.NewConfiguration().Servers[0].URL
 	}
 
+	// the requests and the creations aren't limited by default
+	maxConcurrentRequests := 0
+	if !config.MaxConcurrentRequests.IsNull() && !config.MaxConcurrentRequests.IsUnknown() {
+		maxConcurrentRequests = int(config.MaxConcurrentRequests.ValueInt64())
+	}
+	maxConcurrentCreates := 0
+	if !config.MaxConcurrentCreates.IsNull() && !config.MaxConcurrentCreates.IsUnknown() {
+		maxConcurrentCreates = int(config.MaxConcurrentCreates.ValueInt64())
+	}
+
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	var transport http.RoundTripper = http.DefaultTransport
+	if mockMode {
+		mockServer, err := mock.NewServer(os.Getenv("EMMA_MOCK_STATE"))
+		if err != nil {
+			resp.Diagnostics.AddError("Unable to start the mock EMMA API", "Unable to read the mock state, got error: "+err.Error())
+			return
+		}
+		transport = &mock.Transport{Handler: mockServer}
+		tflog.Warn(ctx, "Mock mode is enabled, the requests are sent to the offline fake EMMA API")
+	}
+
 	configuration := &emmaSdk.Configuration{
 		DefaultHeader: make(map[string]string),
 		UserAgent:     "OpenAPI-Generator/0.0.1/go",
@@ -164,6 +304,7 @@ func (p *Provider) Configure(ctx context.Context, req provider.ConfigureRequest,
 			},
 		},
 		OperationServers: map[string]emmaSdk.ServerConfigurations{},
+		HTTPClient:       newLimitedHttpClient(transport, maxConcurrentRequests),
 	}
 	apiClient := emmaSdk.NewAPIClient(configuration)
 	credentials := emmaSdk.Credentials{ClientId: clientId, ClientSecret: clientSecret}
@@ -177,12 +318,51 @@ func (p *Provider) Configure(ctx context.Context, req provider.ConfigureRequest,
 				"EMMA Client Error: "+err.Error())
 		return
 	}
-	providerClient := Client{apiClient: apiClient, token: token}
+	var allowedSshKeyTypes []string
+	if !config.AllowedSshKeyTypes.IsNull() && !config.AllowedSshKeyTypes.IsUnknown() {
+		resp.Diagnostics.Append(config.AllowedSshKeyTypes.ElementsAs(ctx, &allowedSshKeyTypes, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	var defaultLabels map[string]string
+	if !config.DefaultLabels.IsNull() && !config.DefaultLabels.IsUnknown() {
+		resp.Diagnostics.Append(config.DefaultLabels.ElementsAs(ctx, &defaultLabels, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	var naming namingPolicy
+	if config.Naming != nil {
+		naming.prefix = config.Naming.Prefix.ValueString()
+		naming.suffixRandom = int(config.Naming.SuffixRandom.ValueInt64())
+	}
+	catalogCacheDirectory := ""
+	catalogCacheTtl, _ := time.ParseDuration(defaultCatalogCacheTtl)
+	if config.CatalogCache != nil {
+		catalogCacheDirectory = config.CatalogCache.Directory.ValueString()
+		if strings.HasPrefix(catalogCacheDirectory, "~/") {
+			homeDirectory, err := os.UserHomeDir()
+			if err != nil {
+				resp.Diagnostics.AddAttributeError(path.Root("catalog_cache").AtName("directory"), "Invalid Catalog Cache Directory",
+					"Unable to find the home directory, got error: "+err.Error())
+				return
+			}
+			catalogCacheDirectory = filepath.Join(homeDirectory, catalogCacheDirectory[2:])
+		}
+		if !config.CatalogCache.Ttl.IsNull() && !config.CatalogCache.Ttl.IsUnknown() {
+			catalogCacheTtl, _ = time.ParseDuration(config.CatalogCache.Ttl.ValueString())
+		}
+	}
+	providerClient := Client{apiClient: apiClient, token: token, allowedSshKeyTypes: allowedSshKeyTypes, naming: naming,
+		defaultLabels: defaultLabels, operations: newOperationLimiter(maxConcurrentCreates),
+		catalog: newCatalogCache(tools.NewCache(catalogCacheDirectory, catalogCacheTtl), host, mockMode)}
 	tflog.Info(ctx, "Configured EMMA client")
-	// Make the EMMA client available during DataSource and Resource
+	// Make the EMMA client available during DataSource, Resource and EphemeralResource
 	// type Configure methods.
 	resp.DataSourceData = &providerClient
 	resp.ResourceData = &providerClient
+	resp.EphemeralResourceData = &providerClient
 }
 
 // DataSources defines the data sources implemented in the provider.
@@ -201,12 +381,42 @@ func (p *Provider) Resources(_ context.Context) []func() resource.Resource {
 		NewVmResource,
 		NewSshKeyResource,
 		NewSecurityGroupResource,
+		NewSecurityGroupAttachmentResource,
 		NewSpotInstanceResource,
 		NewKubernetesResource,
 	}
 }
 
+// EphemeralResources defines the ephemeral resources implemented in the provider.
+func (p *Provider) EphemeralResources(_ context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		NewSshKeyPairEphemeralResource,
+	}
+}
+
+// Functions defines the provider-defined functions implemented in the provider.
+func (p *Provider) Functions(_ context.Context) []func() function.Function {
+	return []func() function.Function{
+		NewParseDataCenterIdFunction,
+		NewCidrNormalizeFunction,
+		NewPortRangeFunction,
+	}
+}
+
 type Client struct {
 	apiClient *emmaSdk.APIClient
 	token     *emmaSdk.Token
+	// allowedSshKeyTypes is nil if all ssh key types are allowed
+	allowedSshKeyTypes []string
+	naming             namingPolicy
+	defaultLabels      map[string]string
+	// operations limits the concurrent expensive operations, the emma API requests are limited by the http client
+	operations *operationLimiter
+	// catalog caches the lists of the data sources for the lifetime of the provider process
+	catalog *catalogCache
+}
+
+// isSshKeyTypeAllowed reports whether the ssh key type is allowed by the allowed_ssh_key_types policy.
+func isSshKeyTypeAllowed(allowedSshKeyTypes []string, keyType string) bool {
+	return len(allowedSshKeyTypes) == 0 || slices.Contains(allowedSshKeyTypes, keyType)
 }
@@ -3,7 +3,7 @@ package emma
 import (
 	"context"
 	"fmt"
-	"github.com/emma-community/terraform-provider-emma/tools"
+	"net/url"
 
 	emmaSdk "github.com/emma-community/emma-go-sdk"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
@@ -22,6 +22,7 @@ func NewProviderDataSource() datasource.DataSource {
 type providerDataSource struct {
 	apiClient *emmaSdk.APIClient
 	token     *emmaSdk.Token
+	catalog   *catalogCache
 }
 
 // providerDataSourceModel describes the data source data model.
@@ -68,6 +69,7 @@ func (d *providerDataSource) Configure(ctx context.Context, req datasource.Confi
 	}
 	d.apiClient = client.apiClient
 	d.token = client.token
+	d.catalog = client.catalog
 }
 
 func (d *providerDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
@@ -85,13 +87,13 @@ func (d *providerDataSource) Read(ctx context.Context, req datasource.ReadReques
 	// If applicable, this is a great opportunity to initialize any necessary
 	// provider client data and make a call using it.
 	auth := context.WithValue(ctx, emmaSdk.ContextAccessToken, *d.token.AccessToken)
+
 	request := d.apiClient.ProvidersAPI.GetProviders(auth)
 	request = request.ProviderName(data.Name.ValueString())
-	providers, response, err := request.Execute()
+	providers, err := readCatalog(ctx, d.catalog, "/v1/providers", url.Values{"providerName": {data.Name.ValueString()}}, request.Execute)
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error",
-			fmt.Sprintf("Unable to read provider, got error: %s",
-				tools.ExtractErrorMessage(response)))
+			fmt.Sprintf("Unable to read provider, got error: %s", err))
 		return
 	}
 	if len(providers) == 0 {
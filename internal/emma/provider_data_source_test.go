@@ -0,0 +1,24 @@
+package emma
+
+import (
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"testing"
+)
+
+func TestAccProviderDataSource(t *testing.T) {
+	providerConfig := testAccProviderConfig(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+data "emma_provider" "test" {
+  name = "Microsoft Azure"
+}
+`,
+				Check: resource.TestCheckResourceAttr("data.emma_provider.test", "id", "2"),
+			},
+		},
+	})
+}
@@ -0,0 +1,41 @@
+package emma
+
+import (
+	"fmt"
+	"github.com/emma-community/terraform-provider-emma/internal/mock"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"net/http/httptest"
+	"testing"
+)
+
+// testAccProtoV6ProviderFactories are used to instantiate the provider during the acceptance tests.
+var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
+	"emma": providerserver.NewProtocol6WithError(New()()),
+}
+
+// testAccProviderConfig starts the httptest stub of the emma API for the test and returns the provider
+// configuration that sends the requests to it. The stub keeps the resources until the test ends.
+func testAccProviderConfig(t *testing.T) string {
+	providerConfig, _ := testAccProviderConfigWithServer(t)
+	return providerConfig
+}
+
+// testAccProviderConfigWithServer returns the provider configuration and the stub of the emma API, so the test
+// can change the resources outside of Terraform.
+func testAccProviderConfigWithServer(t *testing.T) (string, *mock.Server) {
+	mockServer, err := mock.NewServer("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(mockServer)
+	t.Cleanup(server.Close)
+
+	return fmt.Sprintf(`
+provider "emma" {
+  host          = %q
+  client_id     = "client-id"
+  client_secret = "client-secret"
+}
+`, server.URL), mockServer
+}
@@ -0,0 +1,299 @@
+package emma
+
+import (
+	"context"
+	"fmt"
+	emmaSdk "github.com/emma-community/emma-go-sdk"
+	emma "github.com/emma-community/terraform-provider-emma/internal/emma/validation"
+	"github.com/emma-community/terraform-provider-emma/tools"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"strconv"
+	"strings"
+)
+
+const defaultSecurityGroupName = "default"
+
+var _ resource.Resource = &securityGroupAttachmentResource{}
+var _ resource.ResourceWithImportState = &securityGroupAttachmentResource{}
+var _ resource.ResourceWithUpgradeState = &securityGroupAttachmentResource{}
+
+func NewSecurityGroupAttachmentResource() resource.Resource {
+	return &securityGroupAttachmentResource{}
+}
+
+// securityGroupAttachmentResource defines the resource implementation.
+type securityGroupAttachmentResource struct {
+	apiClient *emmaSdk.APIClient
+	token     *emmaSdk.Token
+}
+
+// securityGroupAttachmentResourceModel describes the resource data model.
+type securityGroupAttachmentResourceModel struct {
+	Id                      types.String `tfsdk:"id"`
+	SecurityGroupId         types.Int64  `tfsdk:"security_group_id"`
+	InstanceId              types.Int64  `tfsdk:"instance_id"`
+	FallbackSecurityGroupId types.Int64  `tfsdk:"fallback_security_group_id"`
+}
+
+func (r *securityGroupAttachmentResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_security_group_attachment"
+}
+
+func (r *securityGroupAttachmentResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Version: 1,
+		// This description is used by the documentation generator and the language server.
+		Description: "This resource adds a compute instance to a security group.\n\n" +
+			"A compute instance always belongs to exactly one security group. Adding the instance to a security group " +
+			"moves it out of its previous security group. When the attachment is destroyed, the instance is moved to " +
+			"the fallback security group, which is the project's default security group unless `fallback_security_group_id` is set.\n\n" +
+			"Both virtual machines and spot instances can be attached. Don't manage the membership of an instance with " +
+			"this resource and the `security_group_id` attribute of `emma_vm` or `emma_spot_instance` at the same time.\n\n" +
+			"The resource waits until the security group is synchronized and recomposed after each change.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description:   "ID of the attachment in the format `<security_group_id>/<instance_id>`",
+				Computed:      true,
+				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"security_group_id": schema.Int64Attribute{
+				Description:   "Security group ID, the attachment will be recreated after changing this value",
+				Computed:      false,
+				Required:      true,
+				Optional:      false,
+				PlanModifiers: []planmodifier.Int64{int64planmodifier.RequiresReplace()},
+				Validators:    []validator.Int64{emma.PositiveInt64{}},
+			},
+			"instance_id": schema.Int64Attribute{
+				Description:   "ID of the virtual machine or spot instance, the attachment will be recreated after changing this value",
+				Computed:      false,
+				Required:      true,
+				Optional:      false,
+				PlanModifiers: []planmodifier.Int64{int64planmodifier.RequiresReplace()},
+				Validators:    []validator.Int64{emma.PositiveInt64{}},
+			},
+			"fallback_security_group_id": schema.Int64Attribute{
+				Description: "Security group ID the instance is moved to when the attachment is destroyed, " +
+					"the project's default security group is used if not set",
+				Computed:   false,
+				Required:   false,
+				Optional:   true,
+				Validators: []validator.Int64{emma.PositiveInt64{}},
+			},
+		},
+	}
+}
+
+func (r *securityGroupAttachmentResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return stateUpgraders()
+}
+
+func (r *securityGroupAttachmentResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T. Please report this issue to the provider developers.",
+				req.ProviderData))
+		return
+	}
+	r.apiClient = client.apiClient
+	r.token = client.token
+}
+
+func (r *securityGroupAttachmentResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data securityGroupAttachmentResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Info(ctx, "Create security group attachment")
+
+	auth := context.WithValue(ctx, emmaSdk.ContextAccessToken, *r.token.AccessToken)
+	securityGroupId := int32(data.SecurityGroupId.ValueInt64())
+	err := r.addInstance(auth, securityGroupId, int32(data.InstanceId.ValueInt64()))
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", err.Error())
+		return
+	}
+
+	data.Id = types.StringValue(securityGroupAttachmentId(data.SecurityGroupId.ValueInt64(), data.InstanceId.ValueInt64()))
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *securityGroupAttachmentResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data securityGroupAttachmentResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Info(ctx, "Read security group attachment")
+
+	auth := context.WithValue(ctx, emmaSdk.ContextAccessToken, *r.token.AccessToken)
+	securityGroupInstances, response, err := r.apiClient.SecurityGroupsAPI.SecurityGroupInstances(auth,
+		int32(data.SecurityGroupId.ValueInt64())).Execute()
+
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error",
+			fmt.Sprintf("Unable to get security group instances, got error: %s",
+				tools.ExtractErrorMessage(response)))
+		return
+	}
+
+	attached := false
+	for _, securityGroupInstance := range securityGroupInstances {
+		if securityGroupInstance.Id != nil && int64(*securityGroupInstance.Id) == data.InstanceId.ValueInt64() {
+			attached = true
+			break
+		}
+	}
+
+	// the instance was moved to another security group outside of terraform
+	if !attached {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	data.Id = types.StringValue(securityGroupAttachmentId(data.SecurityGroupId.ValueInt64(), data.InstanceId.ValueInt64()))
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *securityGroupAttachmentResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var planData securityGroupAttachmentResourceModel
+
+	// Read Terraform plan planData into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &planData)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Info(ctx, "Update security group attachment")
+
+	// only fallback_security_group_id can be changed in place, it is used on delete
+	resp.Diagnostics.Append(resp.State.Set(ctx, &planData)...)
+}
+
+func (r *securityGroupAttachmentResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data securityGroupAttachmentResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Info(ctx, "Delete security group attachment")
+
+	auth := context.WithValue(ctx, emmaSdk.ContextAccessToken, *r.token.AccessToken)
+
+	var fallbackSecurityGroupId int32
+	if !data.FallbackSecurityGroupId.IsUnknown() && !data.FallbackSecurityGroupId.IsNull() {
+		fallbackSecurityGroupId = int32(data.FallbackSecurityGroupId.ValueInt64())
+	} else {
+		securityGroups, response, err := r.apiClient.SecurityGroupsAPI.GetSecurityGroups(auth).Execute()
+		if err != nil {
+			resp.Diagnostics.AddError("Client Error",
+				fmt.Sprintf("Unable to read security groups, got error: %s",
+					tools.ExtractErrorMessage(response)))
+			return
+		}
+		for _, securityGroup := range securityGroups {
+			if securityGroup.Name != nil && strings.EqualFold(*securityGroup.Name, defaultSecurityGroupName) {
+				fallbackSecurityGroupId = *securityGroup.Id
+				break
+			}
+		}
+		if fallbackSecurityGroupId == 0 {
+			resp.Diagnostics.AddError("Client Error",
+				"Unable to remove instance from security group: default security group not found, "+
+					"set fallback_security_group_id to choose the security group the instance is moved to")
+			return
+		}
+	}
+
+	if int64(fallbackSecurityGroupId) == data.SecurityGroupId.ValueInt64() {
+		return
+	}
+
+	err := r.addInstance(auth, fallbackSecurityGroupId, int32(data.InstanceId.ValueInt64()))
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", err.Error())
+		return
+	}
+}
+
+func (r *securityGroupAttachmentResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	tflog.Info(ctx, "Import security group attachment")
+
+	parts := strings.Split(req.ID, "/")
+	if len(parts) != 2 {
+		resp.Diagnostics.AddError("Import Error",
+			fmt.Sprintf("Unable to import security group attachment: expected import ID in the format "+
+				"<security_group_id>/<instance_id>, got: %s", req.ID))
+		return
+	}
+	securityGroupId, securityGroupErr := strconv.ParseInt(parts[0], 10, 32)
+	instanceId, instanceErr := strconv.ParseInt(parts[1], 10, 32)
+	if securityGroupErr != nil || instanceErr != nil {
+		resp.Diagnostics.AddError("Import Error",
+			fmt.Sprintf("Unable to import security group attachment: ids must be numbers, got: %s", req.ID))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("security_group_id"), securityGroupId)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("instance_id"), instanceId)...)
+}
+
+// addInstance moves the instance into the security group and waits until the change is propagated.
+func (r *securityGroupAttachmentResource) addInstance(ctx context.Context, securityGroupId int32, instanceId int32) error {
+	// the error description of an earlier failed modification of the security group isn't the error of this change
+	previousSecurityGroup, response, err := r.apiClient.SecurityGroupsAPI.GetSecurityGroup(ctx, securityGroupId).Execute()
+	if err != nil {
+		return fmt.Errorf("Unable to read security group, got error: %s", tools.ExtractErrorMessage(response))
+	}
+
+	securityGroupInstanceAdd := emmaSdk.SecurityGroupInstanceAdd{InstanceId: &instanceId}
+	_, response, err = r.apiClient.SecurityGroupsAPI.SecurityGroupInstanceAdd(ctx,
+		securityGroupId).SecurityGroupInstanceAdd(securityGroupInstanceAdd).Execute()
+	if err != nil {
+		return fmt.Errorf("Unable to add instance to security group, got error: %s",
+			tools.ExtractErrorMessage(response))
+	}
+
+	securityGroup, err := waitForSecurityGroupSynchronization(ctx, r.apiClient, securityGroupId)
+	if err != nil {
+		return err
+	}
+	return securityGroupModificationError(previousSecurityGroup.LastModificationErrorDescription, securityGroup)
+}
+
+func securityGroupAttachmentId(securityGroupId int64, instanceId int64) string {
+	return fmt.Sprintf("%d/%d", securityGroupId, instanceId)
+}
@@ -0,0 +1,56 @@
+package emma
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"testing"
+)
+
+func testAccSecurityGroupAttachmentResourceConfig(providerConfig string, fallbackSecurityGroupId string) string {
+	return testAccVmResourceConfig(providerConfig, "aws-eu-north-1", 2, 4, 16) + fmt.Sprintf(`
+resource "emma_security_group" "test" {
+  name  = "attachment"
+  rules = [`+testAccSecurityGroupHttpsRule+`]
+}
+
+resource "emma_security_group_attachment" "test" {
+  security_group_id          = emma_security_group.test.id
+  instance_id                = emma_vm.test.id
+  fallback_security_group_id = %s
+}
+`, fallbackSecurityGroupId)
+}
+
+func TestAccSecurityGroupAttachmentResource(t *testing.T) {
+	providerConfig := testAccProviderConfig(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing, the vm is moved from the default security group
+			{
+				Config: testAccSecurityGroupAttachmentResourceConfig(providerConfig, "null"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("emma_security_group_attachment.test", "id", "2002/1001"),
+					resource.TestCheckResourceAttr("emma_security_group_attachment.test", "security_group_id", "2002"),
+					resource.TestCheckResourceAttr("emma_security_group_attachment.test", "instance_id", "1001"),
+				),
+			},
+			// Update testing, the fallback security group is changed in place
+			{
+				Config: testAccSecurityGroupAttachmentResourceConfig(providerConfig, "2001"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("emma_security_group_attachment.test", "id", "2002/1001"),
+					resource.TestCheckResourceAttr("emma_security_group_attachment.test", "fallback_security_group_id", "2001"),
+				),
+			},
+			// ImportState testing, the fallback security group is kept in the Terraform state only
+			{
+				ResourceName:            "emma_security_group_attachment.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"fallback_security_group_id"},
+			},
+		},
+	})
+}
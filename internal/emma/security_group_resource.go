@@ -2,6 +2,7 @@ package emma
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	emmaSdk "github.com/emma-community/emma-go-sdk"
 	emma "github.com/emma-community/terraform-provider-emma/internal/emma/validation"
@@ -11,17 +12,21 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"net/http"
 	"strconv"
 	"strings"
 	"time"
 )
 
 var _ resource.Resource = &securityGroupResource{}
+var _ resource.ResourceWithModifyPlan = &securityGroupResource{}
+var _ resource.ResourceWithUpgradeState = &securityGroupResource{}
 
 func NewSecurityGroupResource() resource.Resource {
 	return &securityGroupResource{}
@@ -29,21 +34,36 @@ func NewSecurityGroupResource() resource.Resource {
 
 // securityGroupResource defines the resource implementation.
 type securityGroupResource struct {
-	apiClient *emmaSdk.APIClient
-	token     *emmaSdk.Token
+	apiClient     *emmaSdk.APIClient
+	token         *emmaSdk.Token
+	naming        namingPolicy
+	defaultLabels map[string]string
 }
 
 // securityGroupResourceModel describes the resource data model.
 type securityGroupResourceModel struct {
 	Id                               types.String `tfsdk:"id"`
 	Name                             types.String `tfsdk:"name"`
+	FullName                         types.String `tfsdk:"full_name"`
 	SynchronizationStatus            types.String `tfsdk:"synchronization_status"`
 	RecomposingStatus                types.String `tfsdk:"recomposing_status"`
 	LastModificationErrorDescription types.String `tfsdk:"last_modification_error_description"`
 	Rules                            types.List   `tfsdk:"rules"`
+	DefaultRules                     types.List   `tfsdk:"default_rules"`
+	Labels                           types.Map    `tfsdk:"labels"`
+	EffectiveLabels                  types.Map    `tfsdk:"effective_labels"`
 }
 
 type securityGroupResourceRuleModel struct {
+	Direction   types.String `tfsdk:"direction"`
+	Protocol    types.String `tfsdk:"protocol"`
+	Ports       types.String `tfsdk:"ports"`
+	IpRange     types.String `tfsdk:"ip_range"`
+	Service     types.String `tfsdk:"service"`
+	Description types.String `tfsdk:"description"`
+}
+
+type securityGroupResourceDefaultRuleModel struct {
 	Direction types.String `tfsdk:"direction"`
 	Protocol  types.String `tfsdk:"protocol"`
 	Ports     types.String `tfsdk:"ports"`
@@ -56,6 +76,7 @@ func (r *securityGroupResource) Metadata(ctx context.Context, req resource.Metad
 
 func (r *securityGroupResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Version: 1,
 		// This description is used by the documentation generator and the language server.
 		Description: "This resource creates a security group.\n\n" +
 			"A security group refers to a set of rules that determine what network traffic is allowed to enter or leave " +
@@ -67,8 +88,10 @@ func (r *securityGroupResource) Schema(ctx context.Context, req resource.SchemaR
 			"define rules that allow traffic, not deny it. All traffic is denied except for explicitly allowed traffic.\n\n" +
 			"Security groups control TCP, SCTP, GRE, ESP, AH, UDP, and ICMP protocols, or all the selected protocols at once.\n\n" +
 			"After creating a security group, a set of default rules is added to the security group. These rules are " +
-			"immutable, and you can't edit or delete them.\n\n" +
-			"All traffic in the selected protocol is allowed if the IP range in a rule is set to `0.0.0.0/0`.",
+			"immutable, and you can't edit or delete them. They are listed in the `default_rules` attribute.\n\n" +
+			"All traffic in the selected protocol is allowed if the IP range in a rule is set to `0.0.0.0/0` for IPv4 or `::/0` for IPv6.\n\n" +
+			"After creating or updating a security group, the resource waits until the rules are synchronized " +
+			"and the security group is recomposed. An error reported by emma for the modification fails the apply.",
 
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
@@ -81,7 +104,23 @@ func (r *securityGroupResource) Schema(ctx context.Context, req resource.SchemaR
 				Computed:    false,
 				Required:    true,
 				Optional:    false,
-				Validators:  []validator.String{emma.NotEmptyString{}, emma.SecurityGroupName{}},
+				Validators:  []validator.String{emma.NotEmptyString{}, emma.ResourceName{Rule: emma.ComputeNameRule}},
+			},
+			"full_name": schema.StringAttribute{
+				Description: "Name of the security group in emma, the name with the prefix and the random suffix of the provider naming policy",
+				Computed:    true,
+			},
+			"labels": schema.MapAttribute{
+				Description: fmt.Sprintf(labelsDescription, "security group"),
+				ElementType: types.StringType,
+				Computed:    false,
+				Required:    false,
+				Optional:    true,
+			},
+			"effective_labels": schema.MapAttribute{
+				Description: fmt.Sprintf(stateEffectiveLabelsDescription, "security group", "security groups"),
+				ElementType: types.StringType,
+				Computed:    true,
 			},
 			"synchronization_status": schema.StringAttribute{
 				Description: "Synchronization status of the security group. When you make changes in the rules the changes are propagated to the respective provider’s security groups. While this is happening the security groups have the status Synchronizing. After it is done the status changes to Synchronized. When another VM is added to the security group it will not be synchronized at first with the other VMs, therefore the status will be Desynchronized.",
@@ -104,6 +143,7 @@ func (r *securityGroupResource) Schema(ctx context.Context, req resource.SchemaR
 				Validators:  []validator.List{emma.NotEmptyList{}},
 				Description: "List of the inbound and outbound rules in the Security group",
 				NestedObject: schema.NestedAttributeObject{
+					Validators: []validator.Object{emma.SecurityGroupRuleValidator{}},
 					Attributes: map[string]schema.Attribute{
 						"direction": schema.StringAttribute{
 							Description: "Direction of the network traffic, available values: INBOUND or OUTBOUND",
@@ -113,26 +153,66 @@ func (r *securityGroupResource) Schema(ctx context.Context, req resource.SchemaR
 							Validators:  []validator.String{emma.Direction{}},
 						},
 						"protocol": schema.StringAttribute{
-							Description: "Network protocol, available values: all, TCP, SCTP, GRE, ESP, AH, UDP or ICMP",
-							Computed:    false,
-							Required:    true,
-							Optional:    false,
+							Description: "Network protocol, available values: all, TCP, SCTP, GRE, ESP, AH, UDP or ICMP, required if service is not set",
+							Computed:    true,
+							Required:    false,
+							Optional:    true,
 							Validators:  []validator.String{emma.Protocol{}},
 						},
 						"ports": schema.StringAttribute{
-							Description: "Allowed port or port range, available values: port number (8080), port range (1000-1005), all ports (all)",
-							Computed:    false,
-							Required:    true,
-							Optional:    false,
+							Description: "Allowed port or port range, available values: port number (8080), port range (1000-1005), all ports (all), required if service is not set",
+							Computed:    true,
+							Required:    false,
+							Optional:    true,
 							Validators:  []validator.String{emma.PortRange{}},
 						},
 						"ip_range": schema.StringAttribute{
-							Description: "Allowed IP or IP range, available values: ip (8.8.8.8), ip range (8.8.8.8\\32), all ip addresses (0.0.0.0\\0)",
+							Description: "Allowed IPv4 or IPv6 address or range, available values: ip (8.8.8.8 or 2001:db8::1), ip range (8.8.8.8/32 or 2001:db8::/32), all ip addresses (0.0.0.0/0 or ::/0)",
 							Computed:    false,
 							Required:    true,
 							Optional:    false,
 							Validators:  []validator.String{emma.IpRange{}},
 						},
+						"service": schema.StringAttribute{
+							Description: "Named preset that sets protocol and ports of the rule, available values: " +
+								strings.Join(emma.SecurityGroupServiceNames(), ", ") + ". Can't be combined with protocol and ports",
+							Computed:   false,
+							Required:   false,
+							Optional:   true,
+							Validators: []validator.String{emma.Service{}},
+						},
+						"description": schema.StringAttribute{
+							Description: "Description of the rule, it is kept in the Terraform state only",
+							Computed:    false,
+							Required:    false,
+							Optional:    true,
+							Validators:  []validator.String{emma.NotEmptyString{}},
+						},
+					},
+				},
+			},
+			"default_rules": schema.ListNestedAttribute{
+				Computed:      true,
+				Description:   "List of the immutable rules that emma adds to every security group",
+				PlanModifiers: []planmodifier.List{listplanmodifier.UseStateForUnknown()},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"direction": schema.StringAttribute{
+							Description: "Direction of the network traffic",
+							Computed:    true,
+						},
+						"protocol": schema.StringAttribute{
+							Description: "Network protocol",
+							Computed:    true,
+						},
+						"ports": schema.StringAttribute{
+							Description: "Allowed port or port range",
+							Computed:    true,
+						},
+						"ip_range": schema.StringAttribute{
+							Description: "Allowed IP or IP range",
+							Computed:    true,
+						},
 					},
 				},
 			},
@@ -140,6 +220,10 @@ func (r *securityGroupResource) Schema(ctx context.Context, req resource.SchemaR
 	}
 }
 
+func (r *securityGroupResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return stateUpgraders()
+}
+
 func (r *securityGroupResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	// Prevent panic if the provider has not been configured.
 	if req.ProviderData == nil {
@@ -154,6 +238,52 @@ func (r *securityGroupResource) Configure(ctx context.Context, req resource.Conf
 	}
 	r.apiClient = client.apiClient
 	r.token = client.token
+	r.naming = client.naming
+	r.defaultLabels = client.defaultLabels
+}
+
+func (r *securityGroupResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Resource is being destroyed
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	planFullName(ctx, r.naming, emma.ComputeNameRule, req, resp)
+	planEffectiveLabels(ctx, r.defaultLabels, req, resp)
+
+	var planData securityGroupResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &planData)...)
+	if resp.Diagnostics.HasError() || planData.Rules.IsUnknown() || planData.Rules.IsNull() {
+		return
+	}
+
+	// expand the service presets, so protocol and ports are known in the plan
+	var rules []securityGroupResourceRuleModel
+	resp.Diagnostics.Append(planData.Rules.ElementsAs(ctx, &rules, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	for idx := range rules {
+		expandSecurityGroupRuleService(&rules[idx])
+	}
+	rulesListValue, rulesDiagnostic := types.ListValueFrom(ctx,
+		types.ObjectType{AttrTypes: securityGroupResourceRuleModel{}.attrTypes()}, rules)
+	resp.Diagnostics.Append(rulesDiagnostic...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("rules"), rulesListValue)...)
+
+	// default rules are known only after the security group is created
+	if req.State.Raw.IsNull() {
+		return
+	}
+	var stateData securityGroupResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &stateData)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	warnAboutDuplicatedDefaultRules(ctx, rules, stateData.DefaultRules, &resp.Diagnostics)
 }
 
 func (r *securityGroupResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -171,6 +301,8 @@ func (r *securityGroupResource) Create(ctx context.Context, req resource.CreateR
 	// If applicable, this is a great opportunity to initialize any necessary
 	// provider client data and make a call using it.
 	var securityGroupRequest emmaSdk.SecurityGroupRequest
+	applyFullName(r.naming, data.Name, &data.FullName)
+	applyEffectiveLabels(ctx, r.defaultLabels, data.Labels, &data.EffectiveLabels, &resp.Diagnostics)
 	ConvertToSecurityGroupRequest(ctx, data, &securityGroupRequest)
 	auth := context.WithValue(ctx, emmaSdk.ContextAccessToken, *r.token.AccessToken)
 	securityGroup, response, err := r.apiClient.SecurityGroupsAPI.SecurityGroupCreate(auth).SecurityGroupRequest(securityGroupRequest).Execute()
@@ -182,13 +314,23 @@ func (r *securityGroupResource) Create(ctx context.Context, req resource.CreateR
 		return
 	}
 
-	ConvertSecurityGroupResponseToResource(ctx, nil, &data, securityGroup, &resp.Diagnostics)
-
-	if resp.Diagnostics.HasError() {
-		return
+	syncedSecurityGroup, err := waitForSecurityGroupSynchronization(auth, r.apiClient, *securityGroup.Id)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", err.Error())
+	} else {
+		securityGroup = syncedSecurityGroup
+		if err = securityGroupModificationError(nil, securityGroup); err != nil {
+			resp.Diagnostics.AddError("Client Error", err.Error())
+		}
 	}
 
-	// Save data into Terraform state
+	ConvertSecurityGroupResponseToResource(ctx, r.naming, nil, &data, securityGroup, &resp.Diagnostics)
+
+	var rules []securityGroupResourceRuleModel
+	resp.Diagnostics.Append(data.Rules.ElementsAs(ctx, &rules, false)...)
+	warnAboutDuplicatedDefaultRules(ctx, rules, data.DefaultRules, &resp.Diagnostics)
+
+	// Save data into Terraform state, the security group exists even if the synchronization failed
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
@@ -216,7 +358,7 @@ func (r *securityGroupResource) Read(ctx context.Context, req resource.ReadReque
 		return
 	}
 
-	ConvertSecurityGroupResponseToResource(ctx, nil, &data, securityGroup, &resp.Diagnostics)
+	ConvertSecurityGroupResponseToResource(ctx, r.naming, nil, &data, securityGroup, &resp.Diagnostics)
 
 	if resp.Diagnostics.HasError() {
 		return
@@ -252,14 +394,17 @@ func (r *securityGroupResource) Update(ctx context.Context, req resource.UpdateR
 		return
 	}
 
+	previousErrorDescription := securityGroup.LastModificationErrorDescription
 	defaultSecurityGroupRules := make([]emmaSdk.SecurityGroupRule, 0)
 	for _, securityGroupRule := range securityGroup.Rules {
-		if !*securityGroupRule.IsMutable {
+		if isDefaultSecurityGroupRule(securityGroupRule) {
 			defaultSecurityGroupRules = append(defaultSecurityGroupRules, securityGroupRule)
 		}
 	}
 
 	var securityGroupRequest emmaSdk.SecurityGroupRequest
+	applyFullName(r.naming, planData.Name, &planData.FullName)
+	applyEffectiveLabels(ctx, r.defaultLabels, planData.Labels, &planData.EffectiveLabels, &resp.Diagnostics)
 	ConvertToSecurityGroupUpdateRequest(ctx, planData, &securityGroupRequest, defaultSecurityGroupRules)
 	securityGroup, response, err = r.apiClient.SecurityGroupsAPI.SecurityGroupUpdate(auth, tools.StringToInt32(stateData.Id.ValueString())).SecurityGroupRequest(securityGroupRequest).Execute()
 
@@ -270,14 +415,25 @@ func (r *securityGroupResource) Update(ctx context.Context, req resource.UpdateR
 		return
 	}
 
-	ConvertSecurityGroupResponseToResource(ctx, &planData, &stateData, securityGroup, &resp.Diagnostics)
-
-	if resp.Diagnostics.HasError() {
+	syncedSecurityGroup, err := waitForSecurityGroupSynchronization(auth, r.apiClient, *securityGroup.Id)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", err.Error())
+		// the rules aren't known to be synchronized, the prior rules are kept, so the next plan updates them again
+		priorRules := stateData.Rules
+		ConvertSecurityGroupResponseToResource(ctx, r.naming, &planData, &stateData, securityGroup, &resp.Diagnostics)
+		stateData.Rules = priorRules
+		resp.Diagnostics.Append(resp.State.Set(ctx, &stateData)...)
 		return
 	}
+	if err = securityGroupModificationError(previousErrorDescription, syncedSecurityGroup); err != nil {
+		resp.Diagnostics.AddError("Client Error", err.Error())
+	}
+
+	// the rules are synchronized now, so they are read back in the order of the configuration
+	ConvertSecurityGroupResponseToResource(ctx, r.naming, nil, &planData, syncedSecurityGroup, &resp.Diagnostics)
 
 	// Save planData into Terraform state
-	resp.Diagnostics.Append(resp.State.Set(ctx, &stateData)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &planData)...)
 }
 
 func (r *securityGroupResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -293,31 +449,29 @@ func (r *securityGroupResource) Delete(ctx context.Context, req resource.DeleteR
 	tflog.Info(ctx, "Delete security group")
 
 	auth := context.WithValue(ctx, emmaSdk.ContextAccessToken, *r.token.AccessToken)
-	i := 0
-	for i < 36 {
-		i++
-		securityGroup, response, err := r.apiClient.SecurityGroupsAPI.GetSecurityGroup(auth, tools.StringToInt32(data.Id.ValueString())).Execute()
-		if *securityGroup.SynchronizationStatus != "SYNCHRONIZED" || *securityGroup.RecomposingStatus != "RECOMPOSED" {
-			time.Sleep(5 * time.Second)
-			continue
-		}
-
-		securityGroupInstances, response, err := r.apiClient.SecurityGroupsAPI.SecurityGroupInstances(auth, tools.StringToInt32(data.Id.ValueString())).Execute()
+	securityGroupId := tools.StringToInt32(data.Id.ValueString())
+	// security group can be deleted only when it is synchronized and all instances are moved out of it
+	err := waitForSecurityGroup(auth, securityGroupId, func(ctx context.Context) (bool, error) {
+		securityGroup, response, err := r.apiClient.SecurityGroupsAPI.GetSecurityGroup(ctx, securityGroupId).Execute()
 		if err != nil {
-			resp.Diagnostics.AddError("Client Error",
-				fmt.Sprintf("Unable to get security group instances, got error: %s, %s",
-					tools.ExtractErrorMessage(response), err))
-			return
+			return false, fmt.Errorf("Unable to read security group, got error: %s", tools.ExtractErrorMessage(response))
+		}
+		if !isSecurityGroupSynchronized(securityGroup) {
+			return false, nil
 		}
 
-		if len(securityGroupInstances) != 0 {
-			time.Sleep(5 * time.Second)
-			continue
+		securityGroupInstances, response, err := r.apiClient.SecurityGroupsAPI.SecurityGroupInstances(ctx, securityGroupId).Execute()
+		if err != nil {
+			return false, fmt.Errorf("Unable to get security group instances, got error: %s", tools.ExtractErrorMessage(response))
 		}
-		break
+		return len(securityGroupInstances) == 0, nil
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", err.Error())
+		return
 	}
 
-	_, response, err := r.apiClient.SecurityGroupsAPI.SecurityGroupDelete(auth, tools.StringToInt32(data.Id.ValueString())).Execute()
+	_, response, err := r.apiClient.SecurityGroupsAPI.SecurityGroupDelete(auth, securityGroupId).Execute()
 	// If applicable, this is a great opportunity to initialize any necessary
 	// provider client data and make a call using it.
 	if err != nil {
@@ -331,20 +485,18 @@ func (r *securityGroupResource) Delete(ctx context.Context, req resource.DeleteR
 func (r *securityGroupResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	tflog.Info(ctx, "Import security group")
 
-	// Retrieve import ID and save to id attribute
+	// Retrieve import ID and save to id attribute, the resource is read from the emma API after the import
 	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
-
-	r.Read(ctx, resource.ReadRequest{State: resp.State, Private: resp.Private},
-		&resource.ReadResponse{State: resp.State, Private: resp.Private, Diagnostics: resp.Diagnostics})
 }
 
 func ConvertToSecurityGroupRequest(ctx context.Context, data securityGroupResourceModel, securityGroupRequest *emmaSdk.SecurityGroupRequest) {
-	securityGroupRequest.Name = data.Name.ValueString()
+	securityGroupRequest.Name = data.FullName.ValueString()
 	var rules []securityGroupResourceRuleModel
 	rulesListValue, _ := data.Rules.ToListValue(ctx)
 	rulesListValue.ElementsAs(ctx, &rules, false)
 	var requestRules []emmaSdk.SecurityGroupRuleRequest
 	for _, rule := range rules {
+		expandSecurityGroupRuleService(&rule)
 		requestRule := emmaSdk.SecurityGroupRuleRequest{
 			Direction: rule.Direction.ValueString(),
 			Protocol:  rule.Protocol.ValueString(),
@@ -368,11 +520,11 @@ func ConvertToSecurityGroupUpdateRequest(ctx context.Context, data securityGroup
 	securityGroupRequest.Rules = append(securityGroupRequest.Rules, defaultSecurityGroupRequestRules...)
 }
 
-func ConvertSecurityGroupResponseToResource(ctx context.Context, planData *securityGroupResourceModel,
+func ConvertSecurityGroupResponseToResource(ctx context.Context, naming namingPolicy, planData *securityGroupResourceModel,
 	stateData *securityGroupResourceModel, securityGroupResponse *emmaSdk.SecurityGroup, diags *diag.Diagnostics) {
 
 	stateData.Id = types.StringValue(strconv.Itoa(int(*securityGroupResponse.Id)))
-	stateData.Name = types.StringValue(*securityGroupResponse.Name)
+	stateData.Name, stateData.FullName = readName(naming, stateData.Name, stateData.FullName, *securityGroupResponse.Name)
 	stateData.SynchronizationStatus = types.StringValue(*securityGroupResponse.SynchronizationStatus)
 	stateData.RecomposingStatus = types.StringValue(*securityGroupResponse.RecomposingStatus)
 	if securityGroupResponse.LastModificationErrorDescription != nil {
@@ -380,35 +532,57 @@ func ConvertSecurityGroupResponseToResource(ctx context.Context, planData *secur
 	} else {
 		stateData.LastModificationErrorDescription = types.StringValue("")
 	}
+	defaultRules := make([]securityGroupResourceDefaultRuleModel, 0)
+	for _, securityGroupRule := range securityGroupResponse.Rules {
+		if !isDefaultSecurityGroupRule(securityGroupRule) {
+			continue
+		}
+		defaultRules = append(defaultRules, securityGroupResourceDefaultRuleModel{
+			Direction: types.StringPointerValue(securityGroupRule.Direction),
+			Protocol:  types.StringPointerValue(securityGroupRule.Protocol),
+			Ports:     types.StringPointerValue(securityGroupRule.Ports),
+			IpRange:   types.StringPointerValue(securityGroupRule.IpRange),
+		})
+	}
+	defaultRulesListValue, defaultRulesDiagnostic := types.ListValueFrom(ctx,
+		types.ObjectType{AttrTypes: securityGroupResourceDefaultRuleModel{}.attrTypes()}, defaultRules)
+	stateData.DefaultRules = defaultRulesListValue
+	diags.Append(defaultRulesDiagnostic...)
+
 	if planData != nil {
 		// since we have async security group update we store requested state
 		stateData.Rules = planData.Rules
 		stateData.Name = planData.Name
+		stateData.FullName = planData.FullName
 	} else if securityGroupResponse.Rules != nil {
 		var rules []securityGroupResourceRuleModel
 		rulesListValue, _ := stateData.Rules.ToListValue(ctx)
 		rulesListValue.ElementsAs(ctx, &rules, false)
 		ruleOrderMap := make(map[string]int)
 		for idx, rule := range rules {
-			ruleOrderMap[rule.Direction.ValueString()+rule.Protocol.ValueString()+rule.Ports.ValueString()+rule.IpRange.ValueString()] = idx
+			ruleOrderMap[securityGroupRuleKey(rule.Direction.ValueString(), rule.Protocol.ValueString(), rule.Ports.ValueString(), rule.IpRange.ValueString())] = idx
 		}
 		securityGroupRuleModels := make([]securityGroupResourceRuleModel, len(ruleOrderMap))
 		for _, securityGroupRule := range securityGroupResponse.Rules {
-			if securityGroupRule.IsMutable == nil || !*securityGroupRule.IsMutable {
+			if isDefaultSecurityGroupRule(securityGroupRule) {
 				continue
 			}
 			securityGroupRuleModel := securityGroupResourceRuleModel{
-				Direction: types.StringValue(*securityGroupRule.Direction),
-				Protocol:  types.StringValue(*securityGroupRule.Protocol),
-				Ports:     types.StringValue(*securityGroupRule.Ports),
-				IpRange:   types.StringValue(*securityGroupRule.IpRange),
+				Direction:   types.StringValue(*securityGroupRule.Direction),
+				Protocol:    types.StringValue(*securityGroupRule.Protocol),
+				Ports:       types.StringValue(*securityGroupRule.Ports),
+				IpRange:     types.StringValue(*securityGroupRule.IpRange),
+				Service:     types.StringNull(),
+				Description: types.StringNull(),
 			}
-			// to save same order as in configuration we have map, and we have 2 different checks with subnet mask and without
-			if idx, ok := ruleOrderMap[*securityGroupRule.Direction+*securityGroupRule.Protocol+*securityGroupRule.Ports+*securityGroupRule.IpRange]; ok {
+			// to save same order as in configuration we have map, the ip range is compared in the canonical form,
+			// so 8.8.8.8 matches 8.8.8.8/32 and a compressed IPv6 address matches the full form
+			if idx, ok := ruleOrderMap[securityGroupRuleKey(*securityGroupRule.Direction, *securityGroupRule.Protocol, *securityGroupRule.Ports, *securityGroupRule.IpRange)]; ok {
+				// keep the ip range as it is written in the configuration, service and description aren't stored by emma
+				securityGroupRuleModel.IpRange = rules[idx].IpRange
+				securityGroupRuleModel.Service = rules[idx].Service
+				securityGroupRuleModel.Description = rules[idx].Description
 				securityGroupRuleModels[idx] = securityGroupRuleModel
-			} else if idx1, ok1 := ruleOrderMap[*securityGroupRule.Direction+*securityGroupRule.Protocol+*securityGroupRule.Ports+stripSubnetMask(*securityGroupRule.IpRange)]; ok1 {
-				securityGroupRuleModel.IpRange = types.StringValue(stripSubnetMask(securityGroupRuleModel.IpRange.ValueString()))
-				securityGroupRuleModels[idx1] = securityGroupRuleModel
 			} else {
 				securityGroupRuleModels = append(securityGroupRuleModels, securityGroupRuleModel)
 			}
@@ -421,6 +595,17 @@ func ConvertSecurityGroupResponseToResource(ctx context.Context, planData *secur
 }
 
 func (o securityGroupResourceRuleModel) attrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"direction":   types.StringType,
+		"protocol":    types.StringType,
+		"ports":       types.StringType,
+		"ip_range":    types.StringType,
+		"service":     types.StringType,
+		"description": types.StringType,
+	}
+}
+
+func (o securityGroupResourceDefaultRuleModel) attrTypes() map[string]attr.Type {
 	return map[string]attr.Type{
 		"direction": types.StringType,
 		"protocol":  types.StringType,
@@ -429,9 +614,123 @@ func (o securityGroupResourceRuleModel) attrTypes() map[string]attr.Type {
 	}
 }
 
-func stripSubnetMask(ipRange string) string {
-	if strings.Contains(ipRange, "/") {
-		return strings.Split(ipRange, "/")[0]
+func isDefaultSecurityGroupRule(securityGroupRule emmaSdk.SecurityGroupRule) bool {
+	return securityGroupRule.IsMutable == nil || !*securityGroupRule.IsMutable
+}
+
+// warnAboutDuplicatedDefaultRules adds a warning for every rule that is already allowed by a default rule.
+func warnAboutDuplicatedDefaultRules(ctx context.Context, rules []securityGroupResourceRuleModel, defaultRules types.List, diags *diag.Diagnostics) {
+	if defaultRules.IsNull() || defaultRules.IsUnknown() {
+		return
+	}
+	var defaultRuleModels []securityGroupResourceDefaultRuleModel
+	diags.Append(defaultRules.ElementsAs(ctx, &defaultRuleModels, false)...)
+	defaultRuleKeys := make(map[string]struct{})
+	for _, defaultRule := range defaultRuleModels {
+		defaultRuleKeys[securityGroupRuleKey(defaultRule.Direction.ValueString(), defaultRule.Protocol.ValueString(),
+			defaultRule.Ports.ValueString(), defaultRule.IpRange.ValueString())] = struct{}{}
+	}
+	for idx, rule := range rules {
+		if rule.Protocol.IsUnknown() || rule.Ports.IsUnknown() || rule.IpRange.IsUnknown() {
+			continue
+		}
+		key := securityGroupRuleKey(rule.Direction.ValueString(), rule.Protocol.ValueString(), rule.Ports.ValueString(), rule.IpRange.ValueString())
+		if _, ok := defaultRuleKeys[key]; ok {
+			diags.AddAttributeWarning(path.Root("rules").AtListIndex(idx), "Duplicated Default Rule",
+				fmt.Sprintf("The rule %s %s %s %s duplicates an immutable default rule of the security group, "+
+					"it can be removed from the configuration", rule.Direction.ValueString(), rule.Protocol.ValueString(),
+					rule.Ports.ValueString(), rule.IpRange.ValueString()))
+		}
+	}
+}
+
+// expandSecurityGroupRuleService sets protocol and ports of the rule from its service preset.
+func expandSecurityGroupRuleService(rule *securityGroupResourceRuleModel) {
+	if rule.Service.IsNull() || rule.Service.IsUnknown() {
+		return
+	}
+	if service, ok := emma.SecurityGroupServices[rule.Service.ValueString()]; ok {
+		rule.Protocol = types.StringValue(service.Protocol)
+		rule.Ports = types.StringValue(service.Ports)
+	}
+}
+
+func securityGroupRuleKey(direction string, protocol string, ports string, ipRange string) string {
+	return direction + protocol + ports + emma.NormalizeIpRange(ipRange)
+}
+
+const (
+	securityGroupSynchronized = "SYNCHRONIZED"
+	securityGroupRecomposed   = "RECOMPOSED"
+	securityGroupPollInterval = 5 * time.Second
+	securityGroupWaitTimeout  = 3 * time.Minute
+)
+
+// waitForSecurityGroupSynchronization polls the security group until the changes are propagated
+// to the provider's security groups and all instances of the group are recomposed.
+func waitForSecurityGroupSynchronization(ctx context.Context, apiClient *emmaSdk.APIClient, securityGroupId int32) (*emmaSdk.SecurityGroup, error) {
+	var securityGroup *emmaSdk.SecurityGroup
+	err := waitForSecurityGroup(ctx, securityGroupId, func(ctx context.Context) (bool, error) {
+		var response *http.Response
+		var err error
+		securityGroup, response, err = apiClient.SecurityGroupsAPI.GetSecurityGroup(ctx, securityGroupId).Execute()
+		if err != nil {
+			return false, fmt.Errorf("Unable to read security group, got error: %s", tools.ExtractErrorMessage(response))
+		}
+		return isSecurityGroupSynchronized(securityGroup), nil
+	})
+	return securityGroup, err
+}
+
+// waitForSecurityGroup calls done every securityGroupPollInterval until it reports true, returns an error,
+// the context is cancelled or securityGroupWaitTimeout is reached.
+func waitForSecurityGroup(ctx context.Context, securityGroupId int32, done func(ctx context.Context) (bool, error)) error {
+	ctx, cancel := context.WithTimeout(ctx, securityGroupWaitTimeout)
+	defer cancel()
+
+	for {
+		finished, err := done(ctx)
+		if ctx.Err() != nil {
+			return securityGroupWaitError(ctx, securityGroupId)
+		}
+		if err != nil {
+			return err
+		}
+		if finished {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return securityGroupWaitError(ctx, securityGroupId)
+		case <-time.After(securityGroupPollInterval):
+		}
+	}
+}
+
+// securityGroupWaitError returns the error of the wait stopped by the context, the timeout or the cancellation.
+func securityGroupWaitError(ctx context.Context, securityGroupId int32) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("Timed out waiting for security group %d synchronization: %s", securityGroupId, ctx.Err())
+	}
+	return fmt.Errorf("Cancelled waiting for security group %d synchronization: %s", securityGroupId, ctx.Err())
+}
+
+func isSecurityGroupSynchronized(securityGroup *emmaSdk.SecurityGroup) bool {
+	return securityGroup.SynchronizationStatus != nil && *securityGroup.SynchronizationStatus == securityGroupSynchronized &&
+		securityGroup.RecomposingStatus != nil && *securityGroup.RecomposingStatus == securityGroupRecomposed
+}
+
+// securityGroupModificationError returns the error reported by emma for the modification of the security group.
+// The error description of an earlier failed modification, the previous description, is ignored if the security
+// group is synchronized since.
+func securityGroupModificationError(previousDescription *string, securityGroup *emmaSdk.SecurityGroup) error {
+	description := securityGroup.LastModificationErrorDescription
+	if description == nil || *description == "" {
+		return nil
+	}
+	if isSecurityGroupSynchronized(securityGroup) && previousDescription != nil && *previousDescription == *description {
+		return nil
 	}
-	return ipRange
+	return fmt.Errorf("Security group %d modification failed: %s", *securityGroup.Id, *description)
 }
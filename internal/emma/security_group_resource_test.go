@@ -0,0 +1,185 @@
+package emma
+
+import (
+	"context"
+	"encoding/json"
+	emmaSdk "github.com/emma-community/emma-go-sdk"
+	"github.com/emma-community/terraform-provider-emma/internal/mock"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	testResource "github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testAccSecurityGroupResourceConfig(providerConfig string, name string, rules string) string {
+	return providerConfig + `
+resource "emma_security_group" "test" {
+  name  = "` + name + `"
+  rules = [` + rules + `]
+}
+`
+}
+
+const testAccSecurityGroupHttpsRule = `
+    {
+      direction = "INBOUND"
+      protocol  = "TCP"
+      ports     = "443"
+      ip_range  = "0.0.0.0/0"
+    }`
+
+const testAccSecurityGroupHttpRule = `
+    {
+      direction   = "INBOUND"
+      service     = "http"
+      ip_range    = "10.0.0.0/8"
+      description = "internal http"
+    }`
+
+func TestAccSecurityGroupResource(t *testing.T) {
+	providerConfig := testAccProviderConfig(t)
+
+	testResource.Test(t, testResource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []testResource.TestStep{
+			// Create and Read testing, emma adds the immutable default rules to the security group
+			{
+				Config: testAccSecurityGroupResourceConfig(providerConfig, "web", testAccSecurityGroupHttpsRule),
+				Check: testResource.ComposeAggregateTestCheckFunc(
+					testResource.TestCheckResourceAttr("emma_security_group.test", "id", "2002"),
+					testResource.TestCheckResourceAttr("emma_security_group.test", "synchronization_status", "SYNCHRONIZED"),
+					testResource.TestCheckResourceAttr("emma_security_group.test", "recomposing_status", "RECOMPOSED"),
+					testResource.TestCheckResourceAttr("emma_security_group.test", "rules.#", "1"),
+					testResource.TestCheckResourceAttr("emma_security_group.test", "rules.0.ports", "443"),
+					testResource.TestCheckResourceAttr("emma_security_group.test", "default_rules.#", "2"),
+					testResource.TestCheckResourceAttr("emma_security_group.test", "default_rules.0.direction", "INBOUND"),
+					testResource.TestCheckResourceAttr("emma_security_group.test", "default_rules.0.ports", "22"),
+					testResource.TestCheckResourceAttr("emma_security_group.test", "default_rules.1.direction", "OUTBOUND"),
+				),
+			},
+			// Update testing, the default rules are sent back unchanged and aren't duplicated
+			{
+				Config: testAccSecurityGroupResourceConfig(providerConfig, "web-renamed",
+					testAccSecurityGroupHttpsRule+","+testAccSecurityGroupHttpRule),
+				Check: testResource.ComposeAggregateTestCheckFunc(
+					testResource.TestCheckResourceAttr("emma_security_group.test", "id", "2002"),
+					testResource.TestCheckResourceAttr("emma_security_group.test", "full_name", "web-renamed"),
+					testResource.TestCheckResourceAttr("emma_security_group.test", "rules.#", "2"),
+					testResource.TestCheckResourceAttr("emma_security_group.test", "rules.1.protocol", "TCP"),
+					testResource.TestCheckResourceAttr("emma_security_group.test", "rules.1.ports", "80"),
+					testResource.TestCheckResourceAttr("emma_security_group.test", "default_rules.#", "2"),
+				),
+			},
+			// ImportState testing, service and description of the rules are kept in the Terraform state only
+			{
+				ResourceName:            "emma_security_group.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"rules.1.service", "rules.1.description"},
+			},
+		},
+	})
+}
+
+func TestWaitForSecurityGroupCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := waitForSecurityGroup(ctx, 2002, func(ctx context.Context) (bool, error) { return false, nil })
+	assert.ErrorContains(t, err, "Cancelled waiting for security group 2002 synchronization")
+}
+
+func TestSecurityGroupModificationError(t *testing.T) {
+	newSecurityGroup := func(synchronizationStatus string, description string) *emmaSdk.SecurityGroup {
+		return &emmaSdk.SecurityGroup{
+			Id:                               emmaSdk.PtrInt32(2002),
+			SynchronizationStatus:            emmaSdk.PtrString(synchronizationStatus),
+			RecomposingStatus:                emmaSdk.PtrString(securityGroupRecomposed),
+			LastModificationErrorDescription: emmaSdk.PtrString(description),
+		}
+	}
+	earlierError := emmaSdk.PtrString("earlier error")
+
+	assert.NoError(t, securityGroupModificationError(nil, newSecurityGroup(securityGroupSynchronized, "")))
+	assert.Error(t, securityGroupModificationError(nil, newSecurityGroup(securityGroupSynchronized, "error")))
+	// the error of an earlier failed modification doesn't fail the synchronized change
+	assert.NoError(t, securityGroupModificationError(earlierError, newSecurityGroup(securityGroupSynchronized, "earlier error")))
+	assert.Error(t, securityGroupModificationError(earlierError, newSecurityGroup("FAILED", "earlier error")))
+	assert.Error(t, securityGroupModificationError(earlierError, newSecurityGroup(securityGroupSynchronized, "new error")))
+}
+
+func TestSecurityGroupUpdateSynchronizationTimeout(t *testing.T) {
+	server, err := mock.NewServer("")
+	require.NoError(t, err)
+	// the security group isn't synchronized after the update
+	var updated atomic.Bool
+	configuration := emmaSdk.NewConfiguration()
+	configuration.HTTPClient = &http.Client{Transport: &mock.Transport{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			updated.Store(true)
+		}
+		if r.Method != http.MethodGet || !updated.Load() {
+			server.ServeHTTP(w, r)
+			return
+		}
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, r)
+		var securityGroup emmaSdk.SecurityGroup
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &securityGroup))
+		securityGroup.SynchronizationStatus = emmaSdk.PtrString("SYNCHRONIZING")
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(securityGroup))
+	})}}
+	apiClient := emmaSdk.NewAPIClient(configuration)
+	token, _, err := apiClient.AuthenticationAPI.IssueToken(context.Background()).
+		Credentials(emmaSdk.Credentials{ClientId: "id", ClientSecret: "secret"}).Execute()
+	require.NoError(t, err)
+	auth := context.WithValue(context.Background(), emmaSdk.ContextAccessToken, *token.AccessToken)
+	_, _, err = apiClient.SecurityGroupsAPI.SecurityGroupCreate(auth).SecurityGroupRequest(emmaSdk.SecurityGroupRequest{
+		Name: "example",
+		Rules: []emmaSdk.SecurityGroupRuleRequest{
+			{Direction: "INBOUND", Protocol: "TCP", Ports: "443", IpRange: "0.0.0.0/0"},
+		},
+	}).Execute()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	r := &securityGroupResource{apiClient: apiClient, token: token}
+	state := upgradeStateFixture(t, r, "security_group.json")
+	var stateData securityGroupResourceModel
+	require.False(t, state.Get(ctx, &stateData).HasError())
+	stateData.FullName = stateData.Name
+	stateData.Labels = types.MapNull(types.StringType)
+	stateData.EffectiveLabels = types.MapNull(types.StringType)
+	require.False(t, state.Set(ctx, &stateData).HasError())
+
+	planData := stateData
+	planRules, diags := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: securityGroupResourceRuleModel{}.attrTypes()},
+		[]securityGroupResourceRuleModel{{Direction: types.StringValue("INBOUND"), Protocol: types.StringValue("TCP"),
+			Ports: types.StringValue("80"), IpRange: types.StringValue("0.0.0.0/0"), Service: types.StringNull(),
+			Description: types.StringNull()}})
+	require.False(t, diags.HasError())
+	planData.Rules = planRules
+	plan := tfsdk.Plan{Schema: state.Schema, Raw: state.Raw}
+	require.False(t, plan.Set(ctx, &planData).HasError())
+
+	// the wait times out, the rules of the state aren't changed
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	resp := resource.UpdateResponse{State: tfsdk.State{Schema: state.Schema, Raw: state.Raw}}
+	r.Update(ctx, resource.UpdateRequest{Plan: plan, State: state}, &resp)
+	require.True(t, resp.Diagnostics.HasError())
+	assert.Contains(t, resp.Diagnostics.Errors()[0].Detail(), "Timed out waiting for security group 2002 synchronization")
+
+	var result securityGroupResourceModel
+	require.False(t, resp.State.Get(ctx, &result).HasError())
+	assert.True(t, result.Rules.Equal(stateData.Rules), result.Rules.String())
+	assert.Equal(t, "SYNCHRONIZING", result.SynchronizationStatus.ValueString())
+}
@@ -0,0 +1,184 @@
+package emma
+
+import (
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func testAccSpotInstanceResourceConfig(providerConfig string, securityGroupId string) string {
+	return testAccSpotInstanceResourceDataCenterConfig(providerConfig, "aws-eu-central-1", securityGroupId)
+}
+
+func testAccSpotInstanceResourceDataCenterConfig(providerConfig string, dataCenterId string, securityGroupId string) string {
+	return providerConfig + `
+resource "emma_ssh_key" "test" {
+  name     = "spot-key"
+  key_type = "ED25519"
+}
+
+resource "emma_security_group" "test" {
+  name = "spot-security-group"
+  rules = [
+    {
+      direction = "INBOUND"
+      protocol  = "TCP"
+      ports     = "443"
+      ip_range  = "0.0.0.0/0"
+    }
+  ]
+}
+
+resource "emma_spot_instance" "test" {
+  name               = "spot"
+  data_center_id     = "` + dataCenterId + `"
+  os_id              = 3
+  cloud_network_type = "multi-cloud"
+  vcpu_type          = "shared"
+  vcpu               = 2
+  ram_gb             = 4
+  volume_type        = "ssd"
+  volume_gb          = 16
+  ssh_key_id         = emma_ssh_key.test.id
+  security_group_id  = ` + securityGroupId + `
+  price              = 0.05
+}
+`
+}
+
+func TestAccSpotInstanceResource(t *testing.T) {
+	providerConfig := testAccProviderConfig(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing, the spot instance is added to the default security group
+			{
+				Config: testAccSpotInstanceResourceConfig(providerConfig, "null"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("emma_spot_instance.test", "id", "1001"),
+					resource.TestCheckResourceAttr("emma_spot_instance.test", "price", "0.05"),
+					resource.TestCheckResourceAttr("emma_spot_instance.test", "disks.0.size_gb", "16"),
+					resource.TestCheckResourceAttr("emma_spot_instance.test", "networks.#", "2"),
+					resource.TestCheckNoResourceAttr("emma_spot_instance.test", "security_group_id"),
+				),
+			},
+			// Update testing, the spot instance is moved to the security group in place
+			{
+				Config: testAccSpotInstanceResourceConfig(providerConfig, "emma_security_group.test.id"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("emma_spot_instance.test", "id", "1001"),
+					resource.TestCheckResourceAttrPair("emma_spot_instance.test", "security_group_id", "emma_security_group.test", "id"),
+				),
+			},
+			// ImportState testing, emma doesn't return the offer price and the security group is read only if it is set
+			{
+				ResourceName:            "emma_spot_instance.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"status", "price", "security_group_id"},
+			},
+		},
+	})
+}
+
+func TestAccSpotInstanceResourceUnsupportedDataCenter(t *testing.T) {
+	providerConfig := testAccProviderConfig(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// DigitalOcean doesn't have spot instances, the plan fails before anything is created
+			{
+				Config:      testAccSpotInstanceResourceDataCenterConfig(providerConfig, "digitalocean-ams3", "null"),
+				ExpectError: regexp.MustCompile("Spot instances aren't available in the data center digitalocean-ams3"),
+			},
+		},
+	})
+}
+
+func testAccSpotInstanceResourceOnInterruptionConfig(providerConfig string, onInterruption string) string {
+	return strings.Replace(testAccSpotInstanceResourceConfig(providerConfig, "null"), `  price              = 0.05`,
+		`  price              = 0.05
+  on_interruption    = "`+onInterruption+`"`, 1)
+}
+
+func TestAccSpotInstanceResourceInterruptionRecreate(t *testing.T) {
+	providerConfig, server := testAccProviderConfigWithServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSpotInstanceResourceOnInterruptionConfig(providerConfig, "recreate"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("emma_spot_instance.test", "id", "1001"),
+					resource.TestCheckResourceAttr("emma_spot_instance.test", "on_demand", "false"),
+				),
+			},
+			// The reclaimed spot instance is replaced by a new spot instance
+			{
+				PreConfig: func() { server.InterruptSpot(1001) },
+				Config:    testAccSpotInstanceResourceOnInterruptionConfig(providerConfig, "recreate"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("emma_spot_instance.test", "id", "1002"),
+					resource.TestCheckResourceAttr("emma_spot_instance.test", "status", "RUNNING"),
+					resource.TestCheckResourceAttr("emma_spot_instance.test", "on_demand", "false"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccSpotInstanceResourceInterruptionFallbackOnDemand(t *testing.T) {
+	providerConfig, server := testAccProviderConfigWithServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSpotInstanceResourceOnInterruptionConfig(providerConfig, "fallback_on_demand"),
+				Check:  resource.TestCheckResourceAttr("emma_spot_instance.test", "id", "1001"),
+			},
+			// The reclaimed spot instance is replaced by an on-demand virtual machine in place
+			{
+				PreConfig: func() { server.InterruptSpot(1001) },
+				Config:    testAccSpotInstanceResourceOnInterruptionConfig(providerConfig, "fallback_on_demand"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("emma_spot_instance.test", "id", "1002"),
+					resource.TestCheckResourceAttr("emma_spot_instance.test", "on_demand", "true"),
+					resource.TestCheckResourceAttr("emma_spot_instance.test", "vcpu", "2"),
+					resource.TestCheckResourceAttr("emma_spot_instance.test", "disks.0.size_gb", "16"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccSpotInstanceResourceInterruptionIgnore(t *testing.T) {
+	providerConfig, server := testAccProviderConfigWithServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSpotInstanceResourceOnInterruptionConfig(providerConfig, "ignore"),
+				Check:  resource.TestCheckResourceAttr("emma_spot_instance.test", "id", "1001"),
+			},
+			// The reclaimed spot instance is kept in the state with its status
+			{
+				PreConfig:    func() { server.InterruptSpot(1001) },
+				RefreshState: true,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("emma_spot_instance.test", "id", "1001"),
+					resource.TestCheckResourceAttr("emma_spot_instance.test", "status", "FAILED"),
+				),
+			},
+			{
+				Config:   testAccSpotInstanceResourceOnInterruptionConfig(providerConfig, "ignore"),
+				PlanOnly: true,
+			},
+		},
+	})
+}
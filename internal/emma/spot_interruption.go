@@ -0,0 +1,110 @@
+package emma
+
+import (
+	"context"
+	"fmt"
+	emmaSdk "github.com/emma-community/emma-go-sdk"
+	"github.com/emma-community/terraform-provider-emma/tools"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"net/http"
+	"slices"
+)
+
+// The policies of the on_interruption attribute.
+const (
+	onInterruptionRecreate         = "recreate"
+	onInterruptionFallbackOnDemand = "fallback_on_demand"
+	onInterruptionIgnore           = "ignore"
+)
+
+// spotStatusDeleted is the status of the spot instance that was reclaimed and removed by the cloud provider.
+const spotStatusDeleted = "DELETED"
+
+// spotInterruptedStatuses are the statuses of the spot instance that was reclaimed by the cloud provider
+// or failed, the spot instance doesn't run again in these statuses.
+var spotInterruptedStatuses = []string{spotStatusDeleted, "FAILED"}
+
+// isSpotInterrupted returns true if the spot instance was interrupted, the on-demand virtual machine that replaced
+// the interrupted spot instance isn't interrupted by the cloud provider.
+func isSpotInterrupted(data spotInstanceResourceModel) bool {
+	return !data.OnDemand.ValueBool() && slices.Contains(spotInterruptedStatuses, data.Status.ValueString())
+}
+
+// planSpotInterruption plans the interrupted spot instance according to on_interruption: recreate replaces the spot
+// instance, fallback_on_demand updates it to an on-demand virtual machine and ignore keeps it.
+func planSpotInterruption(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// the resource is created or destroyed
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var stateData spotInstanceResourceModel
+	var onInterruption types.String
+	resp.Diagnostics.Append(req.State.Get(ctx, &stateData)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("on_interruption"), &onInterruption)...)
+	if resp.Diagnostics.HasError() || !isSpotInterrupted(stateData) {
+		return
+	}
+
+	switch onInterruption.ValueString() {
+	case onInterruptionIgnore:
+		return
+	case onInterruptionFallbackOnDemand:
+		// the on-demand virtual machine is created by the update, its attributes are known after apply
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("on_demand"), true)...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("id"), types.StringUnknown())...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("disks"),
+			types.ListUnknown(types.ObjectType{AttrTypes: computeResourceDiskModel{}.attrTypes()}))...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("networks"),
+			types.ListUnknown(types.ObjectType{AttrTypes: computeResourceNetworkModel{}.attrTypes()}))...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("cost"),
+			types.ObjectUnknown(computeResourceCostModel{}.attrTypes()))...)
+	case onInterruptionRecreate, "":
+		resp.RequiresReplace = append(resp.RequiresReplace, path.Root("status"))
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("status"), types.StringUnknown())...)
+}
+
+// fallbackOnDemand replaces the interrupted spot instance with an on-demand virtual machine of the same configuration,
+// the virtual machine is created with the converters of the emma_vm resource.
+func (r *spotInstanceResource) fallbackOnDemand(ctx context.Context, stateData *spotInstanceResourceModel, planData *spotInstanceResourceModel,
+	resp *resource.UpdateResponse) {
+	tflog.Warn(ctx, "Replace interrupted spot instance with on-demand virtual machine",
+		map[string]interface{}{"spot_instance_id": stateData.Id.ValueString(), "status": stateData.Status.ValueString()})
+
+	// the virtual machine gets the name of the spot instance
+	vmData := planData.computeResourceModel
+	vmData.FullName = stateData.FullName
+	var vmCreateRequest emmaSdk.VmCreate
+	ConvertToVmCreateRequest(vmData, &vmCreateRequest)
+
+	release, err := r.operations.acquire(ctx, "vm create")
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create on-demand virtual machine, got error: %s", err))
+		return
+	}
+	defer release()
+	vm, response, err := r.apiClient.VirtualMachinesAPI.VmCreate(ctx).VmCreate(vmCreateRequest).Execute()
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error",
+			fmt.Sprintf("Unable to create on-demand virtual machine, got error: %s",
+				tools.ExtractErrorMessage(response)))
+		return
+	}
+
+	// the interrupted spot instance is removed unless the cloud provider has already removed it
+	if stateData.Status.ValueString() != spotStatusDeleted {
+		_, response, err := r.apiClient.SpotInstancesAPI.SpotDelete(ctx, tools.StringToInt32(stateData.Id.ValueString())).Execute()
+		if err != nil && (response == nil || response.StatusCode != http.StatusNotFound) {
+			resp.Diagnostics.AddWarning("Client Error",
+				fmt.Sprintf("Unable to delete interrupted spot instance %s, delete it in emma, got error: %s",
+					stateData.Id.ValueString(), tools.ExtractErrorMessage(response)))
+		}
+	}
+
+	stateData.OnDemand = types.BoolValue(true)
+	ConvertSpotInstanceResponseToResource(ctx, r.naming, stateData, planData, vm, &resp.Diagnostics)
+}
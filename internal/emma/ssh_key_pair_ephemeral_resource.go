@@ -0,0 +1,197 @@
+package emma
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	emmaSdk "github.com/emma-community/emma-go-sdk"
+	emma "github.com/emma-community/terraform-provider-emma/internal/emma/validation"
+	"github.com/emma-community/terraform-provider-emma/tools"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"strconv"
+)
+
+// sshKeyPairPrivateKeyId is the private data key that holds the ID of the SSH key deleted on close.
+const sshKeyPairPrivateKeyId = "ssh_key_id"
+
+var _ ephemeral.EphemeralResource = &sshKeyPairEphemeralResource{}
+var _ ephemeral.EphemeralResourceWithConfigure = &sshKeyPairEphemeralResource{}
+var _ ephemeral.EphemeralResourceWithClose = &sshKeyPairEphemeralResource{}
+
+func NewSshKeyPairEphemeralResource() ephemeral.EphemeralResource {
+	return &sshKeyPairEphemeralResource{}
+}
+
+// sshKeyPairEphemeralResource defines the ephemeral resource implementation.
+type sshKeyPairEphemeralResource struct {
+	apiClient          *emmaSdk.APIClient
+	token              *emmaSdk.Token
+	allowedSshKeyTypes []string
+}
+
+// sshKeyPairEphemeralResourceModel describes the ephemeral resource data model.
+type sshKeyPairEphemeralResourceModel struct {
+	Id          types.String `tfsdk:"id"`
+	Name        types.String `tfsdk:"name"`
+	KeyType     types.String `tfsdk:"key_type"`
+	Key         types.String `tfsdk:"key"`
+	Fingerprint types.String `tfsdk:"fingerprint"`
+	PrivateKey  types.String `tfsdk:"private_key"`
+}
+
+func (r *sshKeyPairEphemeralResource) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_ssh_key_pair"
+}
+
+func (r *sshKeyPairEphemeralResource) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		Description: "This ephemeral resource generates an SSH key in emma and returns the private key only to the " +
+			"current Terraform run. Neither the SSH key nor the private key is stored in the Terraform state or plan.\n\n" +
+			"The SSH key is generated each time the ephemeral resource is opened and it is deleted from emma when the " +
+			"ephemeral resource is closed at the end of the run, so use it for short-lived access, for example " +
+			"in provisioner connections or to pass the private key to a secret store.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "ID of the SSH key",
+				Computed:    true,
+			},
+			"name": schema.StringAttribute{
+				Description: "SSH key name",
+				Computed:    false,
+				Required:    true,
+				Optional:    false,
+				Validators:  []validator.String{emma.NotBlankString{}, emma.ResourceName{Rule: emma.SshKeyNameRule}},
+			},
+			"key_type": schema.StringAttribute{
+				Description: "SSH key type, available values: RSA or ED25519",
+				Computed:    false,
+				Required:    true,
+				Optional:    false,
+				Validators:  []validator.String{emma.KeyType{}},
+			},
+			"key": schema.StringAttribute{
+				Description: "SSH public key",
+				Computed:    true,
+			},
+			"fingerprint": schema.StringAttribute{
+				Description: "SSH key fingerprint",
+				Computed:    true,
+			},
+			"private_key": schema.StringAttribute{
+				Description: "SSH private key",
+				Computed:    true,
+				Sensitive:   true,
+			},
+		},
+	}
+}
+
+func (r *sshKeyPairEphemeralResource) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+	client, ok := req.ProviderData.(*Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Ephemeral Resource Configure Type",
+			fmt.Sprintf("Expected *Client, got: %T. Please report this issue to the provider developers.",
+				req.ProviderData))
+		return
+	}
+	r.apiClient = client.apiClient
+	r.token = client.token
+	r.allowedSshKeyTypes = client.allowedSshKeyTypes
+}
+
+func (r *sshKeyPairEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data sshKeyPairEphemeralResourceModel
+
+	// Read Terraform config data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if !isSshKeyTypeAllowed(r.allowedSshKeyTypes, data.KeyType.ValueString()) {
+		resp.Diagnostics.AddAttributeError(path.Root("key_type"), "Validation Error",
+			fmt.Sprintf("key_type %s is not allowed by the allowed_ssh_key_types provider policy", data.KeyType.ValueString()))
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Info(ctx, "Open ssh key pair")
+
+	sshKeyCreateImportRequest := emmaSdk.SshKeysCreateImportRequest{
+		SshKeyCreate: &emmaSdk.SshKeyCreate{Name: data.Name.ValueString(), KeyType: data.KeyType.ValueString()},
+	}
+	auth := context.WithValue(ctx, emmaSdk.ContextAccessToken, *r.token.AccessToken)
+
+	sshKey, response, err := r.apiClient.SSHKeysAPI.SshKeysCreateImport(auth).SshKeysCreateImportRequest(sshKeyCreateImportRequest).Execute()
+
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error",
+			fmt.Sprintf("Unable to generate ssh key, got error: %s",
+				tools.ExtractErrorMessage(response)))
+		return
+	}
+	if sshKey.SshKeyGenerated == nil || sshKey.SshKeyGenerated.Id == nil {
+		resp.Diagnostics.AddError("Client Error", "Unable to generate ssh key, got error: generated key is missing in the response")
+		return
+	}
+
+	// the key is deleted on close, the private data is sent back to the provider in the close request
+	sshKeyId, _ := json.Marshal(*sshKey.SshKeyGenerated.Id)
+	resp.Diagnostics.Append(resp.Private.SetKey(ctx, sshKeyPairPrivateKeyId, sshKeyId)...)
+
+	ConvertSshKeyGeneratedResponseToEphemeralResource(&data, sshKey.SshKeyGenerated)
+
+	// Save data into the ephemeral result
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
+
+func (r *sshKeyPairEphemeralResource) Close(ctx context.Context, req ephemeral.CloseRequest, resp *ephemeral.CloseResponse) {
+	sshKeyIdBytes, diags := req.Private.GetKey(ctx, sshKeyPairPrivateKeyId)
+	resp.Diagnostics.Append(diags...)
+
+	if resp.Diagnostics.HasError() || sshKeyIdBytes == nil {
+		return
+	}
+
+	tflog.Info(ctx, "Close ssh key pair")
+
+	var sshKeyId int32
+	if err := json.Unmarshal(sshKeyIdBytes, &sshKeyId); err != nil {
+		resp.Diagnostics.AddError("Internal Error",
+			fmt.Sprintf("Unable to read ssh key id from private data, got error: %s", err))
+		return
+	}
+
+	auth := context.WithValue(ctx, emmaSdk.ContextAccessToken, *r.token.AccessToken)
+	response, err := r.apiClient.SSHKeysAPI.SshKeyDelete(auth, sshKeyId).Execute()
+
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error",
+			fmt.Sprintf("Unable to delete ssh key, got error: %s",
+				tools.ExtractErrorMessage(response)))
+		return
+	}
+}
+
+func ConvertSshKeyGeneratedResponseToEphemeralResource(data *sshKeyPairEphemeralResourceModel, sshKeyResponse *emmaSdk.SshKeyGenerated) {
+	data.Id = types.StringValue(strconv.Itoa(int(*sshKeyResponse.Id)))
+	if sshKeyResponse.Name != nil {
+		data.Name = types.StringValue(*sshKeyResponse.Name)
+	}
+	if sshKeyResponse.KeyType != nil {
+		data.KeyType = types.StringValue(*sshKeyResponse.KeyType)
+	}
+	data.Key = types.StringPointerValue(sshKeyResponse.Key)
+	data.Fingerprint = types.StringPointerValue(sshKeyResponse.Fingerprint)
+	data.PrivateKey = types.StringPointerValue(sshKeyResponse.PrivateKey)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
@@ -19,6 +20,8 @@ import (
 )
 
 var _ resource.Resource = &sshKeyResource{}
+var _ resource.ResourceWithModifyPlan = &sshKeyResource{}
+var _ resource.ResourceWithUpgradeState = &sshKeyResource{}
 
 func NewSshKeyResource() resource.Resource {
 	return &sshKeyResource{}
@@ -26,18 +29,23 @@ func NewSshKeyResource() resource.Resource {
 
 // sshKeyResource defines the resource implementation.
 type sshKeyResource struct {
-	apiClient *emmaSdk.APIClient
-	token     *emmaSdk.Token
+	apiClient          *emmaSdk.APIClient
+	token              *emmaSdk.Token
+	allowedSshKeyTypes []string
+	naming             namingPolicy
 }
 
 // sshKeyResourceModel describes the resource data model.
 type sshKeyResourceModel struct {
-	Id          types.String `tfsdk:"id"`
-	Name        types.String `tfsdk:"name"`
-	Key         types.String `tfsdk:"key"`
-	Fingerprint types.String `tfsdk:"fingerprint"`
-	KeyType     types.String `tfsdk:"key_type"`
-	PrivateKey  types.String `tfsdk:"private_key"`
+	Id              types.String `tfsdk:"id"`
+	Name            types.String `tfsdk:"name"`
+	FullName        types.String `tfsdk:"full_name"`
+	Key             types.String `tfsdk:"key"`
+	Fingerprint     types.String `tfsdk:"fingerprint"`
+	KeyType         types.String `tfsdk:"key_type"`
+	GenerateLocally types.Bool   `tfsdk:"generate_locally"`
+	PrivateKey      types.String `tfsdk:"private_key"`
+	PrivateKeyFile  types.String `tfsdk:"private_key_file"`
 }
 
 func (r *sshKeyResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -46,6 +54,7 @@ func (r *sshKeyResource) Metadata(ctx context.Context, req resource.MetadataRequ
 
 func (r *sshKeyResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Version: 1,
 		// This description is used by the documentation generator and the language server.
 		Description: "This method creates an SSH key that can be used for Linux compute instance creation. " +
 			"An SSH key can be created in two ways: generated by emma or imported by the user.\n\n" +
@@ -53,7 +62,13 @@ func (r *sshKeyResource) Schema(ctx context.Context, req resource.SchemaRequest,
 			"The key will be generated, and you will receive a private key in the response. The private key will be " +
 			"shown only once, so copy and save it to connect to the Linux compute instances.\n\n" +
 			"If you want to **import** an existing SSH key, specify two fields: name and key. In the key field, insert " +
-			"your public SSH key as a string. It will be imported.",
+			"your public SSH key as a string. It will be imported.\n\n" +
+			"Set generate_locally together with key_type to generate the key by the provider instead of emma, " +
+			"only the public key is imported to emma then.\n\n" +
+			"The fingerprint of an imported key is computed during plan and compared with the fingerprint returned by emma.\n\n" +
+			"The generated private key is stored in the Terraform state as a sensitive value. Set private_key_file " +
+			"to write the private key to a local file with 0600 permissions instead of keeping it in the state. " +
+			"Use the `emma_ssh_key_pair` ephemeral resource if the private key is needed only during the current run.",
 
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
@@ -66,19 +81,25 @@ func (r *sshKeyResource) Schema(ctx context.Context, req resource.SchemaRequest,
 				Computed:    false,
 				Required:    true,
 				Optional:    false,
-				Validators:  []validator.String{emma.NotBlankString{}, emma.SshKeyName{}},
+				Validators:  []validator.String{emma.NotBlankString{}, emma.ResourceName{Rule: emma.SshKeyNameRule}},
+			},
+			"full_name": schema.StringAttribute{
+				Description: "Name of the SSH key in emma, the name with the prefix and the random suffix of the provider naming policy",
+				Computed:    true,
 			},
 			"key": schema.StringAttribute{
-				Description:   "SSH public key, ssh key will be recreated after changing this value",
+				Description: "SSH public key in the authorized_keys format without options, the comment of the key is removed " +
+					"before it is imported, RSA keys must be at least 2048 bits, ssh key will be recreated after changing this value",
 				Computed:      false,
 				Required:      false,
 				Optional:      true,
 				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
-				Validators:    []validator.String{emma.NotEmptyString{}},
+				Validators:    []validator.String{emma.NotEmptyString{}, emma.SshPublicKey{}},
 			},
 			"fingerprint": schema.StringAttribute{
-				Description: "SSH key fingerprint",
-				Computed:    true,
+				Description:   "SSH key fingerprint, known during plan if the key is imported",
+				Computed:      true,
+				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
 			},
 			"key_type": schema.StringAttribute{
 				Description:   "SSH key type, available values: RSA or ED25519, ssh key will be recreated after changing this value",
@@ -88,14 +109,36 @@ func (r *sshKeyResource) Schema(ctx context.Context, req resource.SchemaRequest,
 				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
 				Validators:    []validator.String{emma.KeyType{}},
 			},
+			"generate_locally": schema.BoolAttribute{
+				Description: "Generate the key of key_type by the provider and import its public key to emma, " +
+					"ssh key will be recreated after changing this value",
+				Computed:      false,
+				Required:      false,
+				Optional:      true,
+				PlanModifiers: []planmodifier.Bool{boolplanmodifier.RequiresReplace()},
+			},
 			"private_key": schema.StringAttribute{
-				Description: "SSH private key",
+				Description: "SSH private key, empty if the key is imported or private_key_file is set",
 				Computed:    true,
+				Sensitive:   true,
+			},
+			"private_key_file": schema.StringAttribute{
+				Description: "Path of the local file the generated private key is written to with 0600 permissions " +
+					"instead of the Terraform state, ssh key will be recreated after changing this value",
+				Computed:      false,
+				Required:      false,
+				Optional:      true,
+				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
+				Validators:    []validator.String{emma.NotBlankString{}},
 			},
 		},
 	}
 }
 
+func (r *sshKeyResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return stateUpgraders()
+}
+
 func (r *sshKeyResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	// Prevent panic if the provider has not been configured.
 	if req.ProviderData == nil {
@@ -110,6 +153,8 @@ func (r *sshKeyResource) Configure(ctx context.Context, req resource.ConfigureRe
 	}
 	r.apiClient = client.apiClient
 	r.token = client.token
+	r.allowedSshKeyTypes = client.allowedSshKeyTypes
+	r.naming = client.naming
 }
 
 func (r *sshKeyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -124,6 +169,12 @@ func (r *sshKeyResource) Create(ctx context.Context, req resource.CreateRequest,
 	} else if (data.Key.IsUnknown() || data.Key.IsNull()) && (data.KeyType.IsUnknown() || data.KeyType.IsNull()) {
 		resp.Diagnostics.AddError("Validation Error",
 			fmt.Sprintf("Unable to create ssh key: key or key_type is required"))
+	} else if !data.PrivateKeyFile.IsNull() && (data.KeyType.IsUnknown() || data.KeyType.IsNull()) {
+		resp.Diagnostics.AddError("Validation Error",
+			fmt.Sprintf("Unable to create ssh key: private_key_file can be used only with key_type"))
+	} else if data.GenerateLocally.ValueBool() && (data.KeyType.IsUnknown() || data.KeyType.IsNull()) {
+		resp.Diagnostics.AddError("Validation Error",
+			fmt.Sprintf("Unable to create ssh key: generate_locally can be used only with key_type"))
 	}
 
 	if resp.Diagnostics.HasError() {
@@ -135,7 +186,22 @@ func (r *sshKeyResource) Create(ctx context.Context, req resource.CreateRequest,
 	// If applicable, this is a great opportunity to initialize any necessary
 	// provider client data and make a call using it.
 	var sshKeyCreateImportRequest emmaSdk.SshKeysCreateImportRequest
-	ConvertToSshKeyCreateImportRequest(data, &sshKeyCreateImportRequest)
+	var publicKey string
+	applyFullName(r.naming, data.Name, &data.FullName)
+	if data.GenerateLocally.ValueBool() {
+		privateKey, authorizedKey, err := tools.GenerateSshKeyPair(data.KeyType.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError("Client Error",
+				fmt.Sprintf("Unable to generate ssh key, got error: %s", err))
+			return
+		}
+		publicKey = authorizedKey
+		data.PrivateKey = types.StringValue(privateKey)
+		sshKeyCreateImportRequest.SshKeyImport = &emmaSdk.SshKeyImport{Name: data.FullName.ValueString(), Key: authorizedKey}
+	} else {
+		publicKey = data.Key.ValueString()
+		ConvertToSshKeyCreateImportRequest(data, &sshKeyCreateImportRequest)
+	}
 	auth := context.WithValue(ctx, emmaSdk.ContextAccessToken, *r.token.AccessToken)
 
 	sshKey, response, err := r.apiClient.SSHKeysAPI.SshKeysCreateImport(auth).SshKeysCreateImportRequest(sshKeyCreateImportRequest).Execute()
@@ -147,7 +213,30 @@ func (r *sshKeyResource) Create(ctx context.Context, req resource.CreateRequest,
 		return
 	}
 
-	ConvertSshKey201ResponseToResource(&data, sshKey)
+	ConvertSshKey201ResponseToResource(r.naming, &data, sshKey)
+
+	if publicKey != "" {
+		if !tools.SshKeyFingerprintMatches(publicKey, data.Fingerprint.ValueString()) {
+			resp.Diagnostics.AddError("Client Error",
+				fmt.Sprintf("Unable to create ssh key, got error: fingerprint %s returned by emma doesn't match the public key",
+					data.Fingerprint.ValueString()))
+		} else if !data.GenerateLocally.ValueBool() {
+			// keep the fingerprint computed during plan
+			data.Fingerprint = types.StringValue(mustSshKeyFingerprint(publicKey))
+		}
+	}
+
+	if !data.PrivateKeyFile.IsNull() && data.PrivateKey.ValueString() != "" {
+		err = tools.WriteSensitiveFile(data.PrivateKeyFile.ValueString(), data.PrivateKey.ValueString())
+		if err != nil {
+			// the private key is shown only once, keep it in the state so that it isn't lost
+			resp.Diagnostics.AddAttributeWarning(path.Root("private_key_file"), "Private Key File Error",
+				fmt.Sprintf("Unable to write private key to %s, the private key is kept in the state, got error: %s",
+					data.PrivateKeyFile.ValueString(), err))
+		} else {
+			data.PrivateKey = types.StringValue("")
+		}
+	}
 
 	// Save data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -177,7 +266,7 @@ func (r *sshKeyResource) Read(ctx context.Context, req resource.ReadRequest, res
 		return
 	}
 
-	ConvertSshKeyResponseToResource(&data, nil, sshKey)
+	ConvertSshKeyResponseToResource(r.naming, &data, nil, sshKey)
 
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -209,6 +298,7 @@ func (r *sshKeyResource) Update(ctx context.Context, req resource.UpdateRequest,
 	auth := context.WithValue(ctx, emmaSdk.ContextAccessToken, *r.token.AccessToken)
 
 	var sshKeyUpdateRequest emmaSdk.SshKeyUpdate
+	applyFullName(r.naming, planData.Name, &planData.FullName)
 	ConvertToSshKeyUpdateRequest(planData, &sshKeyUpdateRequest)
 	sshKey, response, err := r.apiClient.SSHKeysAPI.SshKeyUpdate(auth, tools.StringToInt32(stateData.Id.ValueString())).SshKeyUpdate(sshKeyUpdateRequest).Execute()
 
@@ -219,7 +309,7 @@ func (r *sshKeyResource) Update(ctx context.Context, req resource.UpdateRequest,
 		return
 	}
 
-	ConvertSshKeyResponseToResource(&stateData, &planData, sshKey)
+	ConvertSshKeyResponseToResource(r.naming, &stateData, &planData, sshKey)
 
 	// Save planData into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &stateData)...)
@@ -257,37 +347,73 @@ func Delete(ctx context.Context, r *sshKeyResource, stateData sshKeyResourceMode
 func (r *sshKeyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	tflog.Info(ctx, "Import ssh key")
 
-	// Retrieve import ID and save to id attribute
+	// Retrieve import ID and save to id attribute, the resource is read from the emma API after the import
 	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+func (r *sshKeyResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// the resource is destroyed
+	if req.Plan.Raw.IsNull() {
+		return
+	}
 
-	r.Read(ctx, resource.ReadRequest{State: resp.State, Private: resp.Private},
-		&resource.ReadResponse{State: resp.State, Private: resp.Private, Diagnostics: resp.Diagnostics})
+	planFullName(ctx, r.naming, emma.SshKeyNameRule, req, resp)
+
+	var planData sshKeyResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &planData)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// key types blocked by the allowed_ssh_key_types provider policy
+	if !planData.Key.IsUnknown() && !planData.Key.IsNull() {
+		keyType, err := emma.ParseSshPublicKey(planData.Key.ValueString())
+		if err == nil && !isSshKeyTypeAllowed(r.allowedSshKeyTypes, keyType) {
+			resp.Diagnostics.AddAttributeError(path.Root("key"), "Validation Error",
+				fmt.Sprintf("key of type %s is not allowed by the allowed_ssh_key_types provider policy", keyType))
+		}
+	}
+	if !planData.KeyType.IsUnknown() && !planData.KeyType.IsNull() &&
+		!isSshKeyTypeAllowed(r.allowedSshKeyTypes, planData.KeyType.ValueString()) {
+		resp.Diagnostics.AddAttributeError(path.Root("key_type"), "Validation Error",
+			fmt.Sprintf("key_type %s is not allowed by the allowed_ssh_key_types provider policy", planData.KeyType.ValueString()))
+	}
+
+	// the fingerprint of an imported key doesn't depend on emma and is known before apply
+	if planData.Fingerprint.IsUnknown() && !planData.Key.IsUnknown() && !planData.Key.IsNull() {
+		fingerprint, err := tools.SshKeyFingerprint(planData.Key.ValueString())
+		if err == nil {
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("fingerprint"), fingerprint)...)
+		}
+	}
 }
 
 func ConvertToSshKeyCreateImportRequest(data sshKeyResourceModel, sshKeyCreate *emmaSdk.SshKeysCreateImportRequest) {
 	if !data.KeyType.IsNull() {
 		sshKeyCreateRequest := emmaSdk.SshKeyCreate{}
-		sshKeyCreateRequest.Name = data.Name.ValueString()
+		sshKeyCreateRequest.Name = data.FullName.ValueString()
 		sshKeyCreateRequest.KeyType = data.KeyType.ValueString()
 		sshKeyCreate.SshKeyCreate = &sshKeyCreateRequest
 	} else if !data.Key.IsNull() {
 		sshKeyImportRequest := emmaSdk.SshKeyImport{}
-		sshKeyImportRequest.Name = data.Name.ValueString()
-		sshKeyImportRequest.Key = data.Key.ValueString()
+		sshKeyImportRequest.Name = data.FullName.ValueString()
+		// the emma API doesn't accept the comment of the key, like user@host of the generated .pub files
+		sshKeyImportRequest.Key = emma.StripSshPublicKeyComment(data.Key.ValueString())
 		sshKeyCreate.SshKeyImport = &sshKeyImportRequest
 	}
 }
 
 func ConvertToSshKeyUpdateRequest(data sshKeyResourceModel, sshKeyUpdate *emmaSdk.SshKeyUpdate) {
-	sshKeyUpdate.Name = data.Name.ValueString()
+	sshKeyUpdate.Name = data.FullName.ValueString()
 }
 
-func ConvertSshKey201ResponseToResource(data *sshKeyResourceModel, sshKeyResponse *emmaSdk.SshKeysCreateImport201Response) {
+func ConvertSshKey201ResponseToResource(naming namingPolicy, data *sshKeyResourceModel, sshKeyResponse *emmaSdk.SshKeysCreateImport201Response) {
 	if sshKeyResponse.SshKey != nil {
-		ConvertSshKeyResponseToResource(data, nil, sshKeyResponse.SshKey)
+		ConvertSshKeyResponseToResource(naming, data, nil, sshKeyResponse.SshKey)
 	} else if sshKeyResponse.SshKeyGenerated != nil {
 		data.Id = types.StringValue(strconv.Itoa(int(*sshKeyResponse.SshKeyGenerated.Id)))
-		data.Name = types.StringValue(*sshKeyResponse.SshKeyGenerated.Name)
+		data.Name, data.FullName = readName(naming, data.Name, data.FullName, *sshKeyResponse.SshKeyGenerated.Name)
 		if data.Key.IsUnknown() && data.Key.IsNull() {
 			data.Key = types.StringNull()
 		}
@@ -307,9 +433,13 @@ func ConvertSshKey201ResponseToResource(data *sshKeyResourceModel, sshKeyRespons
 	}
 }
 
-func ConvertSshKeyResponseToResource(stateData *sshKeyResourceModel, planData *sshKeyResourceModel, sshKeyResponse *emmaSdk.SshKey) {
+func ConvertSshKeyResponseToResource(naming namingPolicy, stateData *sshKeyResourceModel, planData *sshKeyResourceModel, sshKeyResponse *emmaSdk.SshKey) {
 	stateData.Id = types.StringValue(strconv.Itoa(int(*sshKeyResponse.Id)))
-	stateData.Name = types.StringValue(*sshKeyResponse.Name)
+	if planData != nil {
+		stateData.Name = planData.Name
+		stateData.FullName = planData.FullName
+	}
+	stateData.Name, stateData.FullName = readName(naming, stateData.Name, stateData.FullName, *sshKeyResponse.Name)
 	if planData != nil && !planData.Key.IsUnknown() && !planData.Key.IsNull() {
 		stateData.Key = planData.Key
 	} else if stateData.Key.IsUnknown() && stateData.Key.IsNull() {
@@ -322,7 +452,17 @@ func ConvertSshKeyResponseToResource(stateData *sshKeyResourceModel, planData *s
 		stateData.KeyType = types.StringNull()
 	}
 	stateData.Fingerprint = types.StringValue(*sshKeyResponse.Fingerprint)
+	if !stateData.Key.IsUnknown() && !stateData.Key.IsNull() &&
+		tools.SshKeyFingerprintMatches(stateData.Key.ValueString(), *sshKeyResponse.Fingerprint) {
+		stateData.Fingerprint = types.StringValue(mustSshKeyFingerprint(stateData.Key.ValueString()))
+	}
 	if stateData.PrivateKey.IsNull() || stateData.PrivateKey.IsUnknown() {
 		stateData.PrivateKey = types.StringValue("")
 	}
 }
+
+// mustSshKeyFingerprint returns the fingerprint of the public key that was already parsed successfully.
+func mustSshKeyFingerprint(authorizedKey string) string {
+	fingerprint, _ := tools.SshKeyFingerprint(authorizedKey)
+	return fingerprint
+}
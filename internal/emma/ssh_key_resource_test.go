@@ -0,0 +1,121 @@
+package emma
+
+import (
+	"fmt"
+	"github.com/emma-community/terraform-provider-emma/tools"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAccSshKeyResource(t *testing.T) {
+	providerConfig := testAccProviderConfig(t)
+	config := func(name string) string {
+		return providerConfig + fmt.Sprintf(`
+resource "emma_ssh_key" "test" {
+  name     = %q
+  key_type = "ED25519"
+}
+`, name)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing, the key is generated by emma
+			{
+				Config: config("generated"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("emma_ssh_key.test", "id", "3001"),
+					resource.TestCheckResourceAttr("emma_ssh_key.test", "key_type", "ED25519"),
+					resource.TestCheckResourceAttrSet("emma_ssh_key.test", "fingerprint"),
+					resource.TestCheckResourceAttrSet("emma_ssh_key.test", "private_key"),
+				),
+			},
+			// Update testing, the key is renamed in place
+			{
+				Config: config("renamed"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("emma_ssh_key.test", "id", "3001"),
+					resource.TestCheckResourceAttr("emma_ssh_key.test", "full_name", "renamed"),
+					resource.TestCheckResourceAttrSet("emma_ssh_key.test", "private_key"),
+				),
+			},
+			// ImportState testing, emma returns the private key only when it is generated
+			{
+				ResourceName:            "emma_ssh_key.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"key_type", "private_key"},
+			},
+		},
+	})
+}
+
+func TestAccSshKeyResourceImportedKey(t *testing.T) {
+	providerConfig := testAccProviderConfig(t)
+	_, publicKey, err := tools.GenerateSshKeyPair("ED25519")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fingerprint, err := tools.SshKeyFingerprint(publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + fmt.Sprintf(`
+resource "emma_ssh_key" "test" {
+  name = "imported"
+  key  = %q
+}
+`, publicKey),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("emma_ssh_key.test", "fingerprint", fingerprint),
+					resource.TestCheckResourceAttr("emma_ssh_key.test", "private_key", ""),
+					resource.TestCheckNoResourceAttr("emma_ssh_key.test", "key_type"),
+				),
+			},
+			{
+				ResourceName:            "emma_ssh_key.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"key"},
+			},
+		},
+	})
+}
+
+func TestAccSshKeyResourceGeneratedLocally(t *testing.T) {
+	providerConfig := testAccProviderConfig(t)
+	privateKeyFile := filepath.Join(t.TempDir(), "id_ed25519")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + fmt.Sprintf(`
+resource "emma_ssh_key" "test" {
+  name             = "local"
+  key_type         = "ED25519"
+  generate_locally = true
+  private_key_file = %q
+}
+`, privateKeyFile),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("emma_ssh_key.test", "fingerprint"),
+					resource.TestCheckResourceAttr("emma_ssh_key.test", "private_key", ""),
+					func(_ *terraform.State) error {
+						_, err := os.Stat(privateKeyFile)
+						return err
+					},
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,56 @@
+package emma
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// stateUpgraders returns the state upgraders of the resources from the prior schema versions:
+//   - version 0 to 1 normalises the id attribute of the resources to a string, the ID of the Kubernetes cluster was a number.
+func stateUpgraders() map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: {StateUpgrader: upgradeStateIdToString},
+	}
+}
+
+// upgradeStateIdToString upgrades the raw state of the schema version 0 to the current schema, the numeric id is
+// converted to a string. The attributes added to the schema since the state was saved are null in the upgraded state.
+func upgradeStateIdToString(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+	if req.RawState == nil || req.RawState.JSON == nil {
+		resp.Diagnostics.AddError("Unable to Upgrade Resource State", "The state of the schema version 0 isn't in the JSON format")
+		return
+	}
+
+	// the numbers are decoded as json.Number to keep the values of the other attributes exact
+	var rawState map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(req.RawState.JSON))
+	decoder.UseNumber()
+	if err := decoder.Decode(&rawState); err != nil {
+		resp.Diagnostics.AddError("Unable to Upgrade Resource State",
+			fmt.Sprintf("Unable to read the state of the schema version 0, got error: %s", err))
+		return
+	}
+	if id, ok := rawState["id"].(json.Number); ok {
+		rawState["id"] = id.String()
+	}
+
+	upgradedJson, err := json.Marshal(rawState)
+	if err != nil {
+		resp.Diagnostics.AddError("Unable to Upgrade Resource State",
+			fmt.Sprintf("Unable to write the state of the schema version 1, got error: %s", err))
+		return
+	}
+	upgradedState, err := tfprotov6.RawState{JSON: upgradedJson}.UnmarshalWithOpts(resp.State.Schema.Type().TerraformType(ctx),
+		tfprotov6.UnmarshalOpts{ValueFromJSONOpts: tftypes.ValueFromJSONOpts{IgnoreUndefinedAttributes: true}})
+	if err != nil {
+		resp.Diagnostics.AddError("Unable to Upgrade Resource State",
+			fmt.Sprintf("Unable to convert the state of the schema version 0, got error: %s", err))
+		return
+	}
+	resp.State.Raw = upgradedState
+}
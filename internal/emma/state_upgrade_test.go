@@ -0,0 +1,108 @@
+package emma
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// upgradeStateFixture upgrades the state of the schema version 0 in testdata/state_v0 to the current schema.
+func upgradeStateFixture(t *testing.T, r resource.Resource, fixture string) tfsdk.State {
+	ctx := context.Background()
+	rawState, err := os.ReadFile(filepath.Join("testdata", "state_v0", fixture))
+	require.NoError(t, err)
+
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	require.Equal(t, int64(1), schemaResp.Schema.Version)
+
+	upgrader, ok := r.(resource.ResourceWithUpgradeState).UpgradeState(ctx)[0]
+	require.True(t, ok)
+
+	resp := resource.UpgradeStateResponse{State: tfsdk.State{
+		Schema: schemaResp.Schema,
+		Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+	}}
+	upgrader.StateUpgrader(ctx, resource.UpgradeStateRequest{RawState: &tfprotov6.RawState{JSON: rawState}}, &resp)
+	require.False(t, resp.Diagnostics.HasError(), resp.Diagnostics)
+	return resp.State
+}
+
+func TestUpgradeStateV0(t *testing.T) {
+	tests := []struct {
+		resource resource.Resource
+		fixture  string
+		id       string
+	}{
+		{NewVmResource(), "vm.json", "1001"},
+		{NewSpotInstanceResource(), "spot_instance.json", "1002"},
+		{NewKubernetesResource(), "kubernetes_cluster.json", "4001"},
+		{NewSecurityGroupResource(), "security_group.json", "2002"},
+		{NewSecurityGroupAttachmentResource(), "security_group_attachment.json", "2002/1001"},
+		{NewSshKeyResource(), "ssh_key.json", "3001"},
+	}
+	for _, test := range tests {
+		t.Run(test.fixture, func(t *testing.T) {
+			state := upgradeStateFixture(t, test.resource, test.fixture)
+
+			var id types.String
+			assert.False(t, state.GetAttribute(context.Background(), path.Root("id"), &id).HasError())
+			assert.Equal(t, test.id, id.ValueString())
+		})
+	}
+}
+
+func TestUpgradeStateV0Vm(t *testing.T) {
+	state := upgradeStateFixture(t, NewVmResource(), "vm.json")
+
+	var data vmResourceModel
+	require.False(t, state.Get(context.Background(), &data).HasError())
+	assert.Equal(t, int64(16), data.VolumeGb.ValueInt64())
+	assert.Equal(t, int64(2002), data.SecurityGroupId.ValueInt64())
+	assert.Len(t, data.Disks.Elements(), 1)
+	// the attributes added after the state was saved are null
+	assert.True(t, data.Labels.IsNull())
+	assert.True(t, data.DeletionProtection.IsNull())
+}
+
+func TestUpgradeStateV0Kubernetes(t *testing.T) {
+	state := upgradeStateFixture(t, NewKubernetesResource(), "kubernetes_cluster.json")
+
+	var data kubernetesModel
+	require.False(t, state.Get(context.Background(), &data).HasError())
+	assert.Equal(t, "4001", data.Id.ValueString())
+	// the IDs of the worker nodes stay numbers like the IDs of the other nested objects
+	require.Len(t, data.WorkerNodes, 1)
+	assert.Equal(t, int64(7001), data.WorkerNodes[0].Id.ValueInt64())
+}
+
+func TestUpgradeStateV0SpotPrice(t *testing.T) {
+	state := upgradeStateFixture(t, NewSpotInstanceResource(), "spot_instance.json")
+
+	var price types.Float64
+	assert.False(t, state.GetAttribute(context.Background(), path.Root("price"), &price).HasError())
+	assert.Equal(t, 0.0105, price.ValueFloat64())
+}
+
+func TestUpgradeStateV0InvalidJson(t *testing.T) {
+	ctx := context.Background()
+	var schemaResp resource.SchemaResponse
+	NewVmResource().Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+
+	resp := resource.UpgradeStateResponse{State: tfsdk.State{Schema: schemaResp.Schema}}
+	upgradeStateIdToString(ctx, resource.UpgradeStateRequest{RawState: &tfprotov6.RawState{JSON: []byte("{")}}, &resp)
+	assert.True(t, resp.Diagnostics.HasError())
+
+	resp = resource.UpgradeStateResponse{State: tfsdk.State{Schema: schemaResp.Schema}}
+	upgradeStateIdToString(ctx, resource.UpgradeStateRequest{}, &resp)
+	assert.True(t, resp.Diagnostics.HasError())
+}
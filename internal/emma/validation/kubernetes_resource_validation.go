@@ -11,29 +11,6 @@ import (
 	"slices"
 )
 
-type KubernetesResourceName struct {
-	FieldName string
-}
-
-func (k KubernetesResourceName) Description(ctx context.Context) string {
-	return "The name must be less than 64 characters, start with a lowercase letter, end with a lowercase alphanumeric, and use only lowercase alphanumerics and hyphens in between."
-}
-
-func (k KubernetesResourceName) MarkdownDescription(ctx context.Context) string {
-	return "The name must be less than 64 characters, start with a lowercase letter, end with a lowercase alphanumeric, and use only lowercase alphanumerics and hyphens in between."
-}
-
-func (k KubernetesResourceName) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
-	if req.ConfigValue.IsUnknown() || req.ConfigValue.IsNull() {
-		resp.Diagnostics.AddError("Validation Error", fmt.Sprintf("%s is required", req.Path.String()))
-		return
-	}
-	matches, _ := regexp.MatchString(`^([a-z](?:[0-9a-z-]{0,62}[0-9a-z]))$`, req.ConfigValue.ValueString())
-	if !matches {
-		resp.Diagnostics.AddError("Validation Error", fmt.Sprintf("Action rejected: The %s must be less than 64 characters, start with a lowercase letter, end with a lowercase alphanumeric, and use only lowercase alphanumerics and hyphens in between.", k.FieldName))
-	}
-}
-
 type KubernetesResourceDomainName struct{}
 
 func (k KubernetesResourceDomainName) Description(ctx context.Context) string {
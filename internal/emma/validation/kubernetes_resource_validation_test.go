@@ -9,20 +9,6 @@ import (
 	"testing"
 )
 
-func TestKubernetesResourceName_ValidateString(t *testing.T) {
-	v := KubernetesResourceName{}
-	var resp validator.StringResponse
-	var req validator.StringRequest
-
-	req.ConfigValue = types.StringValue("valid-name")
-	v.ValidateString(context.Background(), req, &resp)
-	assert.False(t, resp.Diagnostics.HasError())
-
-	req.ConfigValue = types.StringValue("Invalid-Name")
-	v.ValidateString(context.Background(), req, &resp)
-	assert.True(t, resp.Diagnostics.HasError())
-}
-
 func TestKubernetesResourceDomainName_ValidateString(t *testing.T) {
 	v := KubernetesResourceDomainName{}
 	var resp validator.StringResponse
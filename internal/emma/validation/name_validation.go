@@ -0,0 +1,91 @@
+package emma
+
+import (
+	"context"
+	"errors"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"regexp"
+)
+
+// MaxResourceNameLength is the maximum length of the names of virtual machines, spot instances and security groups.
+const MaxResourceNameLength = 63
+
+// NameRule is the rule of the names of a resource type, the zero value is the rule of virtual machines,
+// spot instances and security groups.
+type NameRule int
+
+const (
+	// ComputeNameRule is the rule of the names of virtual machines, spot instances and security groups.
+	ComputeNameRule NameRule = iota
+	// SshKeyNameRule is the rule of the names of ssh keys.
+	SshKeyNameRule
+	// KubernetesNameRule is the rule of the names of kubernetes clusters, worker nodes and autoscaling groups.
+	KubernetesNameRule
+)
+
+var nameRuleRegexps = map[NameRule]*regexp.Regexp{
+	ComputeNameRule:    regexp.MustCompile(`^[a-z](?:[0-9a-z-]{0,61}[0-9a-z])$`),
+	SshKeyNameRule:     regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`),
+	KubernetesNameRule: regexp.MustCompile(`^[a-z](?:[0-9a-z-]{0,62}[0-9a-z])$`),
+}
+
+var nameRuleDescriptions = map[NameRule]string{
+	ComputeNameRule:    "must be at most 63 characters, start with a lowercase letter, end with a lowercase alphanumeric, and use only lowercase alphanumeric and hyphens in-between",
+	SshKeyNameRule:     "must be alphanumeric, hyphens, dots and underscores; length can be from 1 to 64 symbols",
+	KubernetesNameRule: "must be less than 65 characters, start with a lowercase letter, end with a lowercase alphanumeric, and use only lowercase alphanumerics and hyphens in between",
+}
+
+// Validate checks the name against the rule.
+func (r NameRule) Validate(name string) error {
+	if !nameRuleRegexps[r].MatchString(name) {
+		return errors.New(nameRuleDescriptions[r])
+	}
+	return nil
+}
+
+// ResourceName validates the name of a virtual machine, spot instance, security group, ssh key or
+// kubernetes cluster with the rule of its resource type.
+type ResourceName struct {
+	Rule NameRule
+}
+
+func (v ResourceName) Description(ctx context.Context) string {
+	return "name " + nameRuleDescriptions[v.Rule]
+}
+
+func (v ResourceName) MarkdownDescription(ctx context.Context) string {
+	return "name " + nameRuleDescriptions[v.Rule]
+}
+
+func (v ResourceName) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsUnknown() || req.ConfigValue.IsNull() {
+		return
+	}
+	if err := v.Rule.Validate(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddError("Validation Error", req.Path.String()+" "+err.Error())
+	}
+}
+
+var resourceNamePrefixRegexp = regexp.MustCompile(`^[a-z][0-9a-z-]*$`)
+
+// ResourceNamePrefix validates the prefix of the provider naming policy, the prefix is valid in the names of
+// all resource types.
+type ResourceNamePrefix struct {
+}
+
+func (v ResourceNamePrefix) Description(ctx context.Context) string {
+	return "prefix must be less than 63 characters, start with a lowercase letter and use only lowercase alphanumeric and hyphens"
+}
+
+func (v ResourceNamePrefix) MarkdownDescription(ctx context.Context) string {
+	return "prefix must be less than 63 characters, start with a lowercase letter and use only lowercase alphanumeric and hyphens"
+}
+
+func (v ResourceNamePrefix) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsUnknown() || req.ConfigValue.IsNull() {
+		return
+	}
+	if !resourceNamePrefixRegexp.MatchString(req.ConfigValue.ValueString()) || len(req.ConfigValue.ValueString()) >= MaxResourceNameLength {
+		resp.Diagnostics.AddError("Validation Error", req.Path.String()+" must be less than 63 characters, start with a lowercase letter and use only lowercase alphanumeric and hyphens")
+	}
+}
@@ -0,0 +1,108 @@
+package emma
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestResourceName_ValidateString_ValidValues(t *testing.T) {
+	validNames := map[NameRule][]string{
+		ComputeNameRule:    {"vm", "team-a-vm-1", "k8s", "a" + strings.Repeat("b", MaxResourceNameLength-1)},
+		SshKeyNameRule:     {"a", "Deploy.Key", "deploy_key", "1st-key", "a" + strings.Repeat("b", 63)},
+		KubernetesNameRule: {"k8s", "team-a-cluster-1", "a" + strings.Repeat("b", 63)},
+	}
+	for rule, names := range validNames {
+		for _, validName := range names {
+			v := ResourceName{Rule: rule}
+			var resp validator.StringResponse
+			var req validator.StringRequest
+
+			req.ConfigValue = types.StringValue(validName)
+			req.Path = path.Root("test")
+
+			v.ValidateString(context.Background(), req, &resp)
+
+			assert.False(t, resp.Diagnostics.HasError(), "Is invalid name value: "+validName)
+		}
+	}
+}
+
+func TestResourceName_ValidateString_InvalidValues(t *testing.T) {
+	invalidNames := map[NameRule][]string{
+		ComputeNameRule: {"", "a", "Example", "1vm", "-vm", "vm-", "vm_1", "vm.example", "vm 1",
+			"a" + strings.Repeat("b", MaxResourceNameLength)},
+		SshKeyNameRule:     {"", "-key", ".key", "deploy key", "key/1", "a" + strings.Repeat("b", 64)},
+		KubernetesNameRule: {"", "a", "Cluster", "cluster-", "cluster_1", "a" + strings.Repeat("b", 64)},
+	}
+	messages := map[NameRule]string{
+		ComputeNameRule:    "test must be at most 63 characters, start with a lowercase letter, end with a lowercase alphanumeric, and use only lowercase alphanumeric and hyphens in-between",
+		SshKeyNameRule:     "test must be alphanumeric, hyphens, dots and underscores; length can be from 1 to 64 symbols",
+		KubernetesNameRule: "test must be less than 65 characters, start with a lowercase letter, end with a lowercase alphanumeric, and use only lowercase alphanumerics and hyphens in between",
+	}
+	for rule, names := range invalidNames {
+		for _, invalidName := range names {
+			v := ResourceName{Rule: rule}
+			var resp validator.StringResponse
+			var req validator.StringRequest
+
+			req.ConfigValue = types.StringValue(invalidName)
+			req.Path = path.Root("test")
+
+			v.ValidateString(context.Background(), req, &resp)
+
+			assert.Equal(t, 1, resp.Diagnostics.ErrorsCount())
+			if resp.Diagnostics.HasError() {
+				actualMsg := resp.Diagnostics.Errors()[0].Detail()
+				assert.Equal(t, messages[rule], actualMsg)
+			} else {
+				assert.Fail(t, "Is valid name value: "+invalidName)
+			}
+		}
+	}
+}
+
+func TestResourceName_ValidateString_NullAndUnknown(t *testing.T) {
+	for _, value := range []types.String{types.StringNull(), types.StringUnknown()} {
+		v := ResourceName{}
+		var resp validator.StringResponse
+		var req validator.StringRequest
+
+		req.ConfigValue = value
+		req.Path = path.Root("test")
+
+		v.ValidateString(context.Background(), req, &resp)
+
+		assert.False(t, resp.Diagnostics.HasError())
+	}
+}
+
+func TestNameRule_Validate(t *testing.T) {
+	assert.NoError(t, ComputeNameRule.Validate("team-a-web-x1y2"))
+	assert.Error(t, ComputeNameRule.Validate("team-a-"+strings.Repeat("web", 20)+"-x1y2"))
+	assert.NoError(t, SshKeyNameRule.Validate("team-a-Deploy.Key-x1y2"))
+	assert.NoError(t, KubernetesNameRule.Validate("team-a-"+strings.Repeat("k", 52)+"-x1y2"))
+}
+
+func TestResourceNamePrefix_ValidateString(t *testing.T) {
+	for prefix, valid := range map[string]bool{"team-a-": true, "t": true, "team1": true, "": false, "-team": false, "Team-": false, "team_a": false} {
+		v := ResourceNamePrefix{}
+		var resp validator.StringResponse
+		var req validator.StringRequest
+
+		req.ConfigValue = types.StringValue(prefix)
+		req.Path = path.Root("test")
+
+		v.ValidateString(context.Background(), req, &resp)
+
+		if valid {
+			assert.False(t, resp.Diagnostics.HasError(), "Is invalid prefix value: "+prefix)
+		} else {
+			assert.Equal(t, 1, resp.Diagnostics.ErrorsCount(), "Is valid prefix value: "+prefix)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package emma
 
 import (
 	"context"
+	"fmt"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 )
 
@@ -25,6 +26,28 @@ func (v PositiveInt64) ValidateInt64(ctx context.Context, req validator.Int64Req
 	}
 }
 
+type Int64Between struct {
+	Min int64
+	Max int64
+}
+
+func (v Int64Between) Description(ctx context.Context) string {
+	return fmt.Sprintf("value must be between %d and %d", v.Min, v.Max)
+}
+
+func (v Int64Between) MarkdownDescription(ctx context.Context) string {
+	return fmt.Sprintf("value must be between %d and %d", v.Min, v.Max)
+}
+
+func (v Int64Between) ValidateInt64(ctx context.Context, req validator.Int64Request, resp *validator.Int64Response) {
+	if req.ConfigValue.IsUnknown() || req.ConfigValue.IsNull() {
+		return
+	}
+	if req.ConfigValue.ValueInt64() < v.Min || req.ConfigValue.ValueInt64() > v.Max {
+		resp.Diagnostics.AddError("Validation Error", fmt.Sprintf("%s must be between %d and %d", req.Path.String(), v.Min, v.Max))
+	}
+}
+
 type PositiveFloat64 struct {
 }
 
@@ -110,3 +110,23 @@ func TestPositiveFloat64_ValidateFloat64_PositiveValue(t *testing.T) {
 
 	assert.False(t, resp.Diagnostics.HasError())
 }
+
+func TestInt64Between_ValidateInt64(t *testing.T) {
+	for value, valid := range map[int64]bool{-1: false, 0: true, 4: true, 8: true, 9: false} {
+		v := Int64Between{Min: 0, Max: 8}
+		var resp validator.Int64Response
+		var req validator.Int64Request
+
+		req.ConfigValue = types.Int64Value(value)
+		req.Path = path.Root("test")
+
+		v.ValidateInt64(context.Background(), req, &resp)
+
+		if valid {
+			assert.False(t, resp.Diagnostics.HasError())
+		} else {
+			assert.Equal(t, 1, resp.Diagnostics.ErrorsCount())
+			assert.Equal(t, "test must be between 0 and 8", resp.Diagnostics.Errors()[0].Detail())
+		}
+	}
+}
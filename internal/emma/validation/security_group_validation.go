@@ -3,23 +3,49 @@ package emma
 import (
 	"context"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
-	"regexp"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"net/netip"
+	"slices"
 	"strconv"
 	"strings"
 )
 
 const (
-	Slash          = "/"
-	AnyPort        = "all"
-	AnyIp          = "0.0.0.0"
-	Ipv4Regex      = `^(\d{1,3})\.(\d{1,3})\.(\d{1,3})\.(\d{1,3})$`
-	Ipv4RangeRegex = `^(\d{1,3})\.(\d{1,3})\.(\d{1,3})\.(\d{1,3})/(\d{1,2})$`
+	Slash   = "/"
+	AnyPort = "all"
+	AnyIp   = "0.0.0.0"
 )
 
-var (
-	Ipv4Pattern      = regexp.MustCompile(Ipv4Regex)
-	Ipv4RangePattern = regexp.MustCompile(Ipv4RangeRegex)
-)
+// SecurityGroupService is a named preset of a security group rule protocol and ports.
+type SecurityGroupService struct {
+	Protocol string
+	Ports    string
+}
+
+// SecurityGroupServices contains the presets available in the service attribute of a security group rule.
+var SecurityGroupServices = map[string]SecurityGroupService{
+	"ssh":            {Protocol: "TCP", Ports: "22"},
+	"http":           {Protocol: "TCP", Ports: "80"},
+	"https":          {Protocol: "TCP", Ports: "443"},
+	"rdp":            {Protocol: "TCP", Ports: "3389"},
+	"dns":            {Protocol: "UDP", Ports: "53"},
+	"mysql":          {Protocol: "TCP", Ports: "3306"},
+	"postgres":       {Protocol: "TCP", Ports: "5432"},
+	"redis":          {Protocol: "TCP", Ports: "6379"},
+	"mongodb":        {Protocol: "TCP", Ports: "27017"},
+	"kubernetes-api": {Protocol: "TCP", Ports: "6443"},
+	"icmp":           {Protocol: "ICMP", Ports: AnyPort},
+}
+
+// SecurityGroupServiceNames returns the sorted names of the security group service presets.
+func SecurityGroupServiceNames() []string {
+	names := make([]string, 0, len(SecurityGroupServices))
+	for name := range SecurityGroupServices {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
 
 type PortRange struct {
 }
@@ -57,7 +83,7 @@ func (v IpRange) ValidateString(ctx context.Context, req validator.StringRequest
 		return
 	}
 	if !isValidIpRangeValue(req.ConfigValue.ValueString()) {
-		resp.Diagnostics.AddError("Validation Error", req.Path.String()+" is invalid, may contain next values: 0.0.0.0, 1.1.1.1 or 1.1.1.1/32")
+		resp.Diagnostics.AddError("Validation Error", req.Path.String()+" is invalid, may contain next values: 0.0.0.0/0, 1.1.1.1, 1.1.1.1/32, ::/0, 2001:db8::1 or 2001:db8::/32")
 	}
 }
 
@@ -104,45 +130,101 @@ func (v Protocol) ValidateString(ctx context.Context, req validator.StringReques
 	}
 }
 
-type SecurityGroupName struct {
+type Service struct {
 }
 
-func (v SecurityGroupName) Description(ctx context.Context) string {
-	return "name must be less than 63 characters, start with a lowercase letter, end with a lowercase alphanumeric, and use only lowercase alphanumeric and hyphens in-between"
+func (v Service) Description(ctx context.Context) string {
+	return "service can contain next values: " + strings.Join(SecurityGroupServiceNames(), ", ")
 }
 
-func (v SecurityGroupName) MarkdownDescription(ctx context.Context) string {
-	return "name must be less than 63 characters, start with a lowercase letter, end with a lowercase alphanumeric, and use only lowercase alphanumeric and hyphens in-between"
+func (v Service) MarkdownDescription(ctx context.Context) string {
+	return "service can contain next values: " + strings.Join(SecurityGroupServiceNames(), ", ")
 }
 
-func (v SecurityGroupName) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+func (v Service) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
 	if req.ConfigValue.IsUnknown() || req.ConfigValue.IsNull() {
 		return
 	}
-	matches, _ := regexp.MatchString(`^([a-z](?:[0-9a-z-]{0,61}[0-9a-z]))$`, req.ConfigValue.ValueString())
-	if !matches {
-		resp.Diagnostics.AddError("Validation Error", req.Path.String()+" must be less than 63 characters, start with a lowercase letter, end with a lowercase alphanumeric, and use only lowercase alphanumeric and hyphens in-between")
+	if _, ok := SecurityGroupServices[req.ConfigValue.ValueString()]; !ok {
+		resp.Diagnostics.AddError("Validation Error", req.Path.String()+" can contain next values: "+strings.Join(SecurityGroupServiceNames(), ", "))
 	}
 }
 
+type SecurityGroupRuleValidator struct {
+}
+
+func (v SecurityGroupRuleValidator) Description(ctx context.Context) string {
+	return "rule must contain either service or protocol and ports"
+}
+
+func (v SecurityGroupRuleValidator) MarkdownDescription(ctx context.Context) string {
+	return "rule must contain either service or protocol and ports"
+}
+
+func (v SecurityGroupRuleValidator) ValidateObject(ctx context.Context, req validator.ObjectRequest, resp *validator.ObjectResponse) {
+	if req.ConfigValue.IsUnknown() || req.ConfigValue.IsNull() {
+		return
+	}
+	attrMap := req.ConfigValue.Attributes()
+	service, _ := attrMap["service"].(types.String)
+	protocol, _ := attrMap["protocol"].(types.String)
+	ports, _ := attrMap["ports"].(types.String)
+
+	if service.IsNull() {
+		if protocol.IsNull() {
+			resp.Diagnostics.AddError("Validation Error", req.Path.AtName("protocol").String()+" is required when service is not set")
+		}
+		if ports.IsNull() {
+			resp.Diagnostics.AddError("Validation Error", req.Path.AtName("ports").String()+" is required when service is not set")
+		}
+		return
+	}
+	if !protocol.IsNull() || !ports.IsNull() {
+		resp.Diagnostics.AddError("Validation Error", req.Path.AtName("service").String()+" can't be combined with protocol and ports")
+		return
+	}
+	if service.IsUnknown() {
+		return
+	}
+
+	// the unknown services are reported by the Service validator
+	preset, ok := SecurityGroupServices[service.ValueString()]
+	if !ok {
+		return
+	}
+	protocolResp := validator.StringResponse{}
+	Protocol{}.ValidateString(ctx, validator.StringRequest{Path: req.Path.AtName("protocol"), ConfigValue: types.StringValue(preset.Protocol)}, &protocolResp)
+	resp.Diagnostics.Append(protocolResp.Diagnostics...)
+	portsResp := validator.StringResponse{}
+	PortRange{}.ValidateString(ctx, validator.StringRequest{Path: req.Path.AtName("ports"), ConfigValue: types.StringValue(preset.Ports)}, &portsResp)
+	resp.Diagnostics.Append(portsResp.Diagnostics...)
+}
+
 func isValidPortsValue(ports string) bool {
+	_, _, ok := ParsePortRange(ports)
+	return ok
+}
+
+// ParsePortRange returns the first and the last port of the ports of a security group rule: all, 3000 or 1-3010.
+// The all value is the whole range of 0-65535, ok is false if the ports value is invalid.
+func ParsePortRange(ports string) (from int, to int, ok bool) {
 	if ports == AnyPort {
-		return true
+		return 0, 65535, true
+	}
+	first, last, isRange := strings.Cut(ports, "-")
+	if !isRange {
+		last = first
+	}
+	if !isValidSinglePortValue(first) || !isValidSinglePortValue(last) {
+		return 0, 0, false
 	}
-	if strings.Contains(ports, "-") {
-		parts := strings.Split(ports, "-")
-		if !isValidSinglePortValue(parts[0]) || !isValidSinglePortValue(parts[1]) {
-			return false
-		}
 
-		start, _ := strconv.Atoi(parts[0])
-		end, _ := strconv.Atoi(parts[1])
-		if end < start {
-			return false
-		}
-		return true
+	from, _ = strconv.Atoi(first)
+	to, _ = strconv.Atoi(last)
+	if to < from {
+		return 0, 0, false
 	}
-	return isValidSinglePortValue(ports)
+	return from, to, true
 }
 
 func isValidSinglePortValue(port string) bool {
@@ -156,68 +238,56 @@ func isValidSinglePortValue(port string) bool {
 	return true
 }
 
+// IsValidIpRange returns true if the value is an IPv4 or IPv6 address or CIDR accepted by a security group rule.
+func IsValidIpRange(ipRange string) bool {
+	return isValidIpRangeValue(ipRange)
+}
+
 func isValidIpRangeValue(ipRange string) bool {
-	if !isValidIpRange(ipRange) && !isValidIp(ipRange) {
-		return false
+	if strings.Contains(ipRange, Slash) {
+		return isValidIpRange(ipRange)
 	}
-	if strings.HasPrefix(ipRange, AnyIp) && strings.Contains(ipRange, Slash) {
-		splitted := strings.Split(ipRange, Slash)
-		if len(splitted) < 2 {
-			return false
-		}
-		_, err := strconv.Atoi(splitted[1])
-		if err != nil {
-			return false
-		}
-	}
-	return true
+	return isValidIp(ipRange)
 }
 
 func isValidIp(ip string) bool {
-	if !Ipv4Pattern.MatchString(ip) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
 		return false
 	}
-	octets := strings.Split(ip, ".")
-	for _, octetStr := range octets {
-		octet, err := strconv.Atoi(octetStr)
-		if err != nil {
-			return false
-		}
-		if !isValidOctetValue(octet) {
-			return false
-		}
-	}
-	return true
+	return addr.Zone() == ""
 }
 
 func isValidIpRange(ipRange string) bool {
-	if !Ipv4RangePattern.MatchString(ipRange) {
+	prefix, err := netip.ParsePrefix(ipRange)
+	if err != nil || prefix.Addr().Zone() != "" {
 		return false
 	}
-	ip := strings.Split(ipRange, "/")[0]
-	if !isValidIp(ip) {
-		return false
-	}
-	prefix, err := strconv.Atoi(strings.Split(ipRange, "/")[1])
-	if err != nil {
-		return false
-	}
-	if !isValidPrefixLength(prefix) {
-		return false
+	// a zero prefix length is only allowed for all addresses: 0.0.0.0/0 or ::/0
+	if prefix.Bits() == 0 {
+		return prefix.Addr().IsUnspecified()
 	}
 	return true
 }
 
-func isValidOctetValue(octetValue int) bool {
-	if octetValue < 0 || octetValue > 255 {
-		return false
+// NormalizeIpRange returns the canonical form of an IPv4 or IPv6 address or CIDR, so that equal ranges written
+// differently can be compared: host bits of a CIDR are cleared, a single address CIDR (/32 or /128) becomes
+// the address itself and IPv6 addresses are compressed. Values that can't be parsed are returned unchanged.
+func NormalizeIpRange(ipRange string) string {
+	if strings.Contains(ipRange, Slash) {
+		prefix, err := netip.ParsePrefix(ipRange)
+		if err != nil {
+			return ipRange
+		}
+		prefix = prefix.Masked()
+		if prefix.IsSingleIP() {
+			return prefix.Addr().String()
+		}
+		return prefix.String()
 	}
-	return true
-}
-
-func isValidPrefixLength(prefixLength int) bool {
-	if prefixLength < 1 || prefixLength > 32 {
-		return false
+	addr, err := netip.ParseAddr(ipRange)
+	if err != nil {
+		return ipRange
 	}
-	return true
+	return addr.String()
 }
@@ -2,6 +2,7 @@ package emma
 
 import (
 	"context"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -22,6 +23,7 @@ var invalidPortValues = []struct {
 	{"1--123"},
 	{"-1--123"},
 	{"3008-300"},
+	{"1-2-3"},
 }
 
 var validPortValues = []struct {
@@ -52,6 +54,14 @@ var invalidIpValues = []struct {
 	{"1.1.1.1/-1"},
 	{"1.1.1.1\\32"},
 	{"a"},
+	{"2001:db8::g"},
+	{"2001:db8:::1"},
+	{"2001:db8::1/129"},
+	{"2001:db8::1/0"},
+	{"2001:db8::1/"},
+	{"2001:0db8:0000:0000:0000:0000:0000:0001:1"},
+	{"fe80::1%eth0"},
+	{"::1/-1"},
 }
 
 var validIpValues = []struct {
@@ -64,6 +74,32 @@ var validIpValues = []struct {
 	{"255.255.255.255/32"},
 	{"255.255.255.255/1"},
 	{"255.255.255.255"},
+	{"0.0.0.0/0"},
+	{"::"},
+	{"::/0"},
+	{"::1"},
+	{"2001:db8::1"},
+	{"2001:db8::1/128"},
+	{"2001:db8::/32"},
+	{"2001:0db8:0000:0000:0000:0000:0000:0001"},
+	{"2001:0db8:0000:0000:0000:0000:0000:0000/64"},
+	{"::ffff:1.1.1.1"},
+}
+
+var normalizedIpValues = []struct {
+	in       string
+	expected string
+}{
+	{"1.1.1.1", "1.1.1.1"},
+	{"1.1.1.1/32", "1.1.1.1"},
+	{"1.1.1.1/24", "1.1.1.0/24"},
+	{"0.0.0.0/0", "0.0.0.0/0"},
+	{"::/0", "::/0"},
+	{"2001:db8::1/128", "2001:db8::1"},
+	{"2001:0db8:0000:0000:0000:0000:0000:0001", "2001:db8::1"},
+	{"2001:0DB8:0000:0000:0000:0000:0000:0000/64", "2001:db8::/64"},
+	{"2001:db8::1/64", "2001:db8::/64"},
+	{"a", "a"},
 }
 
 func TestPortRange_ValidateString_InvalidValues(t *testing.T) {
@@ -102,6 +138,29 @@ func TestPortRange_ValidateString_ValidValues(t *testing.T) {
 	}
 }
 
+func TestParsePortRange(t *testing.T) {
+	tests := []struct {
+		in   string
+		from int
+		to   int
+	}{
+		{"all", 0, 65535},
+		{"22", 22, 22},
+		{"3000-3008", 3000, 3008},
+		{"0-65535", 0, 65535},
+	}
+	for _, test := range tests {
+		from, to, ok := ParsePortRange(test.in)
+		assert.True(t, ok, "Is invalid port value: "+test.in)
+		assert.Equal(t, test.from, from, "Invalid first port of: "+test.in)
+		assert.Equal(t, test.to, to, "Invalid last port of: "+test.in)
+	}
+	for _, invalidPortValue := range invalidPortValues {
+		_, _, ok := ParsePortRange(invalidPortValue.in)
+		assert.False(t, ok, "Is valid port value: "+invalidPortValue.in)
+	}
+}
+
 func TestIpRange_ValidateString_InvalidValues(t *testing.T) {
 	for _, invalidIpValue := range invalidIpValues {
 		v := IpRange{}
@@ -116,7 +175,7 @@ func TestIpRange_ValidateString_InvalidValues(t *testing.T) {
 		assert.Equal(t, 1, resp.Diagnostics.ErrorsCount())
 		if resp.Diagnostics.HasError() {
 			actualMsg := resp.Diagnostics.Errors()[0].Detail()
-			assert.Equal(t, "test is invalid, may contain next values: 0.0.0.0, 1.1.1.1 or 1.1.1.1/32", actualMsg)
+			assert.Equal(t, "test is invalid, may contain next values: 0.0.0.0/0, 1.1.1.1, 1.1.1.1/32, ::/0, 2001:db8::1 or 2001:db8::/32", actualMsg)
 		} else {
 			assert.Fail(t, "Is valid ip value: "+invalidIpValue.in)
 		}
@@ -138,6 +197,12 @@ func TestIpRange_ValidateString_ValidValues(t *testing.T) {
 	}
 }
 
+func TestNormalizeIpRange(t *testing.T) {
+	for _, normalizedIpValue := range normalizedIpValues {
+		assert.Equal(t, normalizedIpValue.expected, NormalizeIpRange(normalizedIpValue.in), "Invalid normalization of: "+normalizedIpValue.in)
+	}
+}
+
 func TestDirection_ValidateString_InvalidValue(t *testing.T) {
 	v := Direction{}
 	var resp validator.StringResponse
@@ -205,3 +270,87 @@ func TestProtocol_ValidateString_ValidValues(t *testing.T) {
 		assert.False(t, resp.Diagnostics.HasError(), "Is invalid protocol value: "+validDirectionValue)
 	}
 }
+
+func TestService_ValidateString_InvalidValue(t *testing.T) {
+	v := Service{}
+	var resp validator.StringResponse
+	var req validator.StringRequest
+
+	req.ConfigValue = types.StringValue("test")
+	req.Path = path.Root("test")
+
+	v.ValidateString(context.Background(), req, &resp)
+
+	assert.Equal(t, 1, resp.Diagnostics.ErrorsCount())
+	if resp.Diagnostics.HasError() {
+		actualMsg := resp.Diagnostics.Errors()[0].Detail()
+		assert.Equal(t, "test can contain next values: dns, http, https, icmp, kubernetes-api, mongodb, mysql, postgres, rdp, redis, ssh", actualMsg)
+	} else {
+		assert.Fail(t, "Is valid service value: test")
+	}
+}
+
+func TestService_ValidateString_ValidValues(t *testing.T) {
+	for _, validServiceValue := range []string{"ssh", "https", "postgres"} {
+		v := Service{}
+		var resp validator.StringResponse
+		var req validator.StringRequest
+
+		req.ConfigValue = types.StringValue(validServiceValue)
+		req.Path = path.Root("test")
+
+		v.ValidateString(context.Background(), req, &resp)
+
+		assert.False(t, resp.Diagnostics.HasError(), "Is invalid service value: "+validServiceValue)
+	}
+}
+
+func TestSecurityGroupServices_AreValid(t *testing.T) {
+	for name, service := range SecurityGroupServices {
+		var protocolResp validator.StringResponse
+		Protocol{}.ValidateString(context.Background(), validator.StringRequest{Path: path.Root("protocol"),
+			ConfigValue: types.StringValue(service.Protocol)}, &protocolResp)
+		assert.False(t, protocolResp.Diagnostics.HasError(), "Is invalid protocol of service: "+name)
+
+		var portsResp validator.StringResponse
+		PortRange{}.ValidateString(context.Background(), validator.StringRequest{Path: path.Root("ports"),
+			ConfigValue: types.StringValue(service.Ports)}, &portsResp)
+		assert.False(t, portsResp.Diagnostics.HasError(), "Is invalid ports of service: "+name)
+	}
+}
+
+func securityGroupRuleObject(service types.String, protocol types.String, ports types.String) types.Object {
+	attrTypes := map[string]attr.Type{"service": types.StringType, "protocol": types.StringType, "ports": types.StringType}
+	return types.ObjectValueMust(attrTypes, map[string]attr.Value{"service": service, "protocol": protocol, "ports": ports})
+}
+
+func TestSecurityGroupRuleValidator_ValidateObject(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		object   types.Object
+		expected []string
+	}{
+		{"service", securityGroupRuleObject(types.StringValue("ssh"), types.StringNull(), types.StringNull()), nil},
+		{"protocol and ports", securityGroupRuleObject(types.StringNull(), types.StringValue("TCP"), types.StringValue("22")), nil},
+		{"unknown service", securityGroupRuleObject(types.StringUnknown(), types.StringNull(), types.StringNull()), nil},
+		{"missing protocol", securityGroupRuleObject(types.StringNull(), types.StringNull(), types.StringValue("22")),
+			[]string{"test.protocol is required when service is not set"}},
+		{"missing protocol and ports", securityGroupRuleObject(types.StringNull(), types.StringNull(), types.StringNull()),
+			[]string{"test.protocol is required when service is not set", "test.ports is required when service is not set"}},
+		{"service and ports", securityGroupRuleObject(types.StringValue("ssh"), types.StringNull(), types.StringValue("22")),
+			[]string{"test.service can't be combined with protocol and ports"}},
+	}
+	for _, testCase := range testCases {
+		v := SecurityGroupRuleValidator{}
+		var resp validator.ObjectResponse
+		req := validator.ObjectRequest{Path: path.Root("test"), ConfigValue: testCase.object}
+
+		v.ValidateObject(context.Background(), req, &resp)
+
+		var actualMsgs []string
+		for _, err := range resp.Diagnostics.Errors() {
+			actualMsgs = append(actualMsgs, err.Detail())
+		}
+		assert.Equal(t, testCase.expected, actualMsgs, testCase.name)
+	}
+}
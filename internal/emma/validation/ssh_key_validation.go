@@ -2,10 +2,88 @@ package emma
 
 import (
 	"context"
+	"crypto/rsa"
+	"fmt"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
-	"regexp"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"golang.org/x/crypto/ssh"
+	"slices"
+	"strconv"
+	"strings"
 )
 
+// MinRsaKeySize is the minimum size in bits of the imported RSA keys.
+const MinRsaKeySize = 2048
+
+// SshKeyTypes contains the SSH public key algorithms by the key type names used in the allowed_ssh_key_types policy.
+var SshKeyTypes = map[string][]string{
+	"RSA":        {ssh.KeyAlgoRSA},
+	"ED25519":    {ssh.KeyAlgoED25519},
+	"ECDSA":      {ssh.KeyAlgoECDSA256, ssh.KeyAlgoECDSA384, ssh.KeyAlgoECDSA521},
+	"DSA":        {ssh.KeyAlgoDSA},
+	"ED25519_SK": {ssh.KeyAlgoSKED25519},
+	"ECDSA_SK":   {ssh.KeyAlgoSKECDSA256},
+}
+
+// SshKeyTypeNames returns the sorted key type names of the allowed_ssh_key_types policy.
+func SshKeyTypeNames() []string {
+	names := make([]string, 0, len(SshKeyTypes))
+	for name := range SshKeyTypes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// ParseSshPublicKey parses a single public key in the authorized_keys format and returns its key type name.
+// The comment of the key, like user@host of the generated .pub files, is accepted. Keys with options or
+// a trailing content, unknown key types and RSA keys shorter than MinRsaKeySize bits are rejected.
+func ParseSshPublicKey(authorizedKey string) (string, error) {
+	publicKey, _, options, rest, err := ssh.ParseAuthorizedKey([]byte(authorizedKey))
+	if err != nil {
+		return "", fmt.Errorf("is not a valid public key in the authorized_keys format")
+	}
+	if len(options) > 0 {
+		return "", fmt.Errorf("must not contain options")
+	}
+	if strings.TrimSpace(string(rest)) != "" {
+		return "", fmt.Errorf("must contain a single public key")
+	}
+
+	keyType := ""
+	for name, algorithms := range SshKeyTypes {
+		if slices.Contains(algorithms, publicKey.Type()) {
+			keyType = name
+			break
+		}
+	}
+	if keyType == "" {
+		return "", fmt.Errorf("has unsupported key type %s", publicKey.Type())
+	}
+
+	if keyType == "RSA" {
+		cryptoPublicKey, ok := publicKey.(ssh.CryptoPublicKey)
+		if !ok {
+			return "", fmt.Errorf("is not a valid RSA public key")
+		}
+		rsaPublicKey, ok := cryptoPublicKey.CryptoPublicKey().(*rsa.PublicKey)
+		if !ok || rsaPublicKey.N.BitLen() < MinRsaKeySize {
+			return "", fmt.Errorf("must be an RSA key of at least %d bits", MinRsaKeySize)
+		}
+	}
+	return keyType, nil
+}
+
+// StripSshPublicKeyComment returns the public key in the authorized_keys format without the comment, the value
+// is returned unchanged if it isn't a valid public key.
+func StripSshPublicKeyComment(authorizedKey string) string {
+	publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(authorizedKey))
+	if err != nil {
+		return authorizedKey
+	}
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(publicKey)))
+}
+
 type KeyType struct {
 }
 
@@ -26,23 +104,54 @@ func (v KeyType) ValidateString(ctx context.Context, req validator.StringRequest
 	}
 }
 
-type SshKeyName struct {
+type SshPublicKey struct {
+}
+
+func (v SshPublicKey) Description(ctx context.Context) string {
+	return "key must be a single public key in the authorized_keys format without options, RSA keys must be at least " + strconv.Itoa(MinRsaKeySize) + " bits"
+}
+
+func (v SshPublicKey) MarkdownDescription(ctx context.Context) string {
+	return "key must be a single public key in the authorized_keys format without options, RSA keys must be at least " + strconv.Itoa(MinRsaKeySize) + " bits"
+}
+
+func (v SshPublicKey) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsUnknown() || req.ConfigValue.IsNull() {
+		return
+	}
+	if _, err := ParseSshPublicKey(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddError("Validation Error", req.Path.String()+" "+err.Error())
+	}
+}
+
+type SshKeyTypeList struct {
 }
 
-func (v SshKeyName) Description(ctx context.Context) string {
-	return "name must be alphanumeric, hyphens, dots and underscores; length can be from 1 to 64 symbols"
+func (v SshKeyTypeList) Description(ctx context.Context) string {
+	return "ssh key types can contain next values: " + strings.Join(SshKeyTypeNames(), ", ")
 }
 
-func (v SshKeyName) MarkdownDescription(ctx context.Context) string {
-	return "name must be alphanumeric, hyphens, dots and underscores; length can be from 1 to 64 symbols"
+func (v SshKeyTypeList) MarkdownDescription(ctx context.Context) string {
+	return "ssh key types can contain next values: " + strings.Join(SshKeyTypeNames(), ", ")
 }
 
-func (v SshKeyName) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+func (v SshKeyTypeList) ValidateList(ctx context.Context, req validator.ListRequest, resp *validator.ListResponse) {
 	if req.ConfigValue.IsUnknown() || req.ConfigValue.IsNull() {
 		return
 	}
-	matches, _ := regexp.MatchString(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`, req.ConfigValue.ValueString())
-	if !matches {
-		resp.Diagnostics.AddError("Validation Error", req.Path.String()+" must be alphanumeric, hyphens, dots and underscores; length can be from 1 to 64 symbols")
+	// an empty list would block all ssh key types, the attribute is omitted to allow them
+	if len(req.ConfigValue.Elements()) == 0 {
+		resp.Diagnostics.AddError("Validation Error", req.Path.String()+" must contain at least one ssh key type, "+
+			"omit it to allow all ssh key types")
+		return
+	}
+	for i, element := range req.ConfigValue.Elements() {
+		keyType, ok := element.(types.String)
+		if !ok || keyType.IsUnknown() {
+			continue
+		}
+		if _, ok = SshKeyTypes[keyType.ValueString()]; !ok {
+			resp.Diagnostics.AddError("Validation Error", req.Path.AtListIndex(i).String()+" can contain next values: "+strings.Join(SshKeyTypeNames(), ", "))
+		}
 	}
 }
@@ -2,13 +2,28 @@ package emma
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+	"strings"
 	"testing"
 )
 
+const ed25519PublicKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl"
+
+func rsaPublicKey(t *testing.T, bits int) string {
+	privateKey, err := rsa.GenerateKey(rand.Reader, bits)
+	assert.NoError(t, err)
+	publicKey, err := ssh.NewPublicKey(&privateKey.PublicKey)
+	assert.NoError(t, err)
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(publicKey)))
+}
+
 func TestKeyType_ValidateString_InvalidValue(t *testing.T) {
 	v := KeyType{}
 	var resp validator.StringResponse
@@ -42,3 +57,103 @@ func TestKeyType_ValidateString_ValidValues(t *testing.T) {
 		assert.False(t, resp.Diagnostics.HasError(), "Is invalid key_type value: "+validKeyTypeValue)
 	}
 }
+
+func TestSshPublicKey_ValidateString_ValidValues(t *testing.T) {
+	for _, validKey := range []string{ed25519PublicKey, ed25519PublicKey + "\n", rsaPublicKey(t, MinRsaKeySize)} {
+		v := SshPublicKey{}
+		var resp validator.StringResponse
+		var req validator.StringRequest
+
+		req.ConfigValue = types.StringValue(validKey)
+		req.Path = path.Root("test")
+
+		v.ValidateString(context.Background(), req, &resp)
+
+		assert.False(t, resp.Diagnostics.HasError(), "Is invalid key value: "+validKey)
+	}
+}
+
+func TestSshPublicKey_ValidateString_InvalidValues(t *testing.T) {
+	tests := []struct {
+		key     string
+		message string
+	}{
+		{"ssh-ed25519 invalid", "test is not a valid public key in the authorized_keys format"},
+		{"no-pty " + ed25519PublicKey, "test must not contain options"},
+		{ed25519PublicKey + "\n" + ed25519PublicKey, "test must contain a single public key"},
+		{rsaPublicKey(t, 1024), "test must be an RSA key of at least 2048 bits"},
+	}
+	for _, test := range tests {
+		v := SshPublicKey{}
+		var resp validator.StringResponse
+		var req validator.StringRequest
+
+		req.ConfigValue = types.StringValue(test.key)
+		req.Path = path.Root("test")
+
+		v.ValidateString(context.Background(), req, &resp)
+
+		assert.Equal(t, 1, resp.Diagnostics.ErrorsCount())
+		if resp.Diagnostics.HasError() {
+			assert.Equal(t, test.message, resp.Diagnostics.Errors()[0].Detail())
+		} else {
+			assert.Fail(t, "Is valid key value: "+test.key)
+		}
+	}
+}
+
+func TestParseSshPublicKey_Comment(t *testing.T) {
+	keyType, err := ParseSshPublicKey(ed25519PublicKey + " user@host\n")
+	assert.NoError(t, err)
+	assert.Equal(t, "ED25519", keyType)
+
+	assert.Equal(t, ed25519PublicKey, StripSshPublicKeyComment(ed25519PublicKey+" user@host\n"))
+	assert.Equal(t, ed25519PublicKey, StripSshPublicKeyComment(ed25519PublicKey))
+	assert.Equal(t, "invalid", StripSshPublicKeyComment("invalid"))
+}
+
+func TestParseSshPublicKey_KeyType(t *testing.T) {
+	keyType, err := ParseSshPublicKey(ed25519PublicKey)
+	assert.NoError(t, err)
+	assert.Equal(t, "ED25519", keyType)
+
+	keyType, err = ParseSshPublicKey(rsaPublicKey(t, 3072))
+	assert.NoError(t, err)
+	assert.Equal(t, "RSA", keyType)
+}
+
+func TestSshKeyTypeList_ValidateList(t *testing.T) {
+	v := SshKeyTypeList{}
+	var resp validator.ListResponse
+	var req validator.ListRequest
+
+	req.ConfigValue = types.ListValueMust(types.StringType, []attr.Value{
+		types.StringValue("RSA"), types.StringValue("ED25519"), types.StringValue("PGP"),
+	})
+	req.Path = path.Root("test")
+
+	v.ValidateList(context.Background(), req, &resp)
+
+	assert.Equal(t, 1, resp.Diagnostics.ErrorsCount())
+	if resp.Diagnostics.HasError() {
+		assert.Equal(t, "test[2] can contain next values: DSA, ECDSA, ECDSA_SK, ED25519, ED25519_SK, RSA",
+			resp.Diagnostics.Errors()[0].Detail())
+	}
+}
+
+func TestSshKeyTypeList_ValidateList_Empty(t *testing.T) {
+	v := SshKeyTypeList{}
+	var resp validator.ListResponse
+	var req validator.ListRequest
+
+	req.ConfigValue = types.ListValueMust(types.StringType, []attr.Value{})
+	req.Path = path.Root("test")
+
+	v.ValidateList(context.Background(), req, &resp)
+
+	assert.Equal(t, 1, resp.Diagnostics.ErrorsCount())
+	if resp.Diagnostics.HasError() {
+		assert.Equal(t, "test must contain at least one ssh key type, omit it to allow all ssh key types",
+			resp.Diagnostics.Errors()[0].Detail())
+	}
+}
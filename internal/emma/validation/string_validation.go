@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"strings"
+	"time"
 	"unicode"
 )
 
@@ -44,6 +45,26 @@ func (v NotEmptyString) ValidateString(ctx context.Context, req validator.String
 	}
 }
 
+type PositiveDuration struct{}
+
+func (v PositiveDuration) Description(ctx context.Context) string {
+	return "value must be a positive duration, for example 30m or 24h"
+}
+
+func (v PositiveDuration) MarkdownDescription(ctx context.Context) string {
+	return "value must be a positive duration, for example `30m` or `24h`"
+}
+
+func (v PositiveDuration) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsUnknown() || req.ConfigValue.IsNull() {
+		return
+	}
+	duration, err := time.ParseDuration(req.ConfigValue.ValueString())
+	if err != nil || duration <= 0 {
+		resp.Diagnostics.AddError("Validation Error", req.Path.String()+" must be a positive duration, for example 30m or 24h")
+	}
+}
+
 type UserPassword struct{}
 
 func (v UserPassword) Description(ctx context.Context) string {
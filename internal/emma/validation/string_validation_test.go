@@ -252,3 +252,30 @@ func TestUserPassword_ValidateString_ValidValues(t *testing.T) {
 		}
 	}
 }
+
+func TestPositiveDuration_ValidateString(t *testing.T) {
+	v := PositiveDuration{}
+	for value, valid := range map[string]bool{
+		"30m":   true,
+		"24h":   true,
+		"1h30m": true,
+		"0s":    false,
+		"-1h":   false,
+		"1d":    false,
+		"":      false,
+	} {
+		var resp validator.StringResponse
+		var req validator.StringRequest
+		req.ConfigValue = types.StringValue(value)
+		req.Path = path.Root("ttl")
+
+		v.ValidateString(context.Background(), req, &resp)
+
+		if valid {
+			assert.False(t, resp.Diagnostics.HasError(), value)
+		} else {
+			assert.Equal(t, 1, resp.Diagnostics.ErrorsCount(), value)
+			assert.Equal(t, "ttl must be a positive duration, for example 30m or 24h", resp.Diagnostics.Errors()[0].Detail())
+		}
+	}
+}
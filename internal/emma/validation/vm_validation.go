@@ -3,7 +3,6 @@ package emma
 import (
 	"context"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
-	"regexp"
 )
 
 type CloudNetworkType struct {
@@ -68,23 +67,23 @@ func (v VolumeType) ValidateString(ctx context.Context, req validator.StringRequ
 	}
 }
 
-type VmName struct {
+type OnInterruption struct {
 }
 
-func (v VmName) Description(ctx context.Context) string {
-	return "name must be less than 63 characters, start with a lowercase letter, end with a lowercase alphanumeric, and use only lowercase alphanumeric and hyphens in-between"
+func (v OnInterruption) Description(ctx context.Context) string {
+	return "on_interruption can contain recreate, fallback_on_demand or ignore"
 }
 
-func (v VmName) MarkdownDescription(ctx context.Context) string {
-	return "name must be less than 63 characters, start with a lowercase letter, end with a lowercase alphanumeric, and use only lowercase alphanumeric and hyphens in-between"
+func (v OnInterruption) MarkdownDescription(ctx context.Context) string {
+	return "on_interruption can contain recreate, fallback_on_demand or ignore"
 }
 
-func (v VmName) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+func (v OnInterruption) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
 	if req.ConfigValue.IsUnknown() || req.ConfigValue.IsNull() {
 		return
 	}
-	matches, _ := regexp.MatchString(`^([a-z](?:[0-9a-z-]{0,61}[0-9a-z]))$`, req.ConfigValue.ValueString())
-	if !matches {
-		resp.Diagnostics.AddError("Validation Error", req.Path.String()+" must be less than 63 characters, start with a lowercase letter, end with a lowercase alphanumeric, and use only lowercase alphanumeric and hyphens in-between")
+	if req.ConfigValue.ValueString() != "recreate" && req.ConfigValue.ValueString() != "fallback_on_demand" &&
+		req.ConfigValue.ValueString() != "ignore" {
+		resp.Diagnostics.AddError("Validation Error", req.Path.String()+" can contain recreate, fallback_on_demand or ignore")
 	}
 }
@@ -110,3 +110,37 @@ func TestVolumeType_ValidateString_ValidValues(t *testing.T) {
 		assert.False(t, resp.Diagnostics.HasError(), "Is invalid volume_type value: "+validVolumeTypeValue)
 	}
 }
+
+func TestOnInterruption_ValidateString_InvalidValue(t *testing.T) {
+	v := OnInterruption{}
+	var resp validator.StringResponse
+	var req validator.StringRequest
+
+	req.ConfigValue = types.StringValue("test")
+	req.Path = path.Root("test")
+
+	v.ValidateString(context.Background(), req, &resp)
+
+	assert.Equal(t, 1, resp.Diagnostics.ErrorsCount())
+	if resp.Diagnostics.HasError() {
+		actualMsg := resp.Diagnostics.Errors()[0].Detail()
+		assert.Equal(t, "test can contain recreate, fallback_on_demand or ignore", actualMsg)
+	} else {
+		assert.Fail(t, "Is valid on_interruption value: test")
+	}
+}
+
+func TestOnInterruption_ValidateString_ValidValues(t *testing.T) {
+	for _, validOnInterruptionValue := range []string{"recreate", "fallback_on_demand", "ignore"} {
+		v := OnInterruption{}
+		var resp validator.StringResponse
+		var req validator.StringRequest
+
+		req.ConfigValue = types.StringValue(validOnInterruptionValue)
+		req.Path = path.Root("test")
+
+		v.ValidateString(context.Background(), req, &resp)
+
+		assert.False(t, resp.Diagnostics.HasError(), "Is invalid on_interruption value: "+validOnInterruptionValue)
+	}
+}
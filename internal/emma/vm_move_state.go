@@ -0,0 +1,86 @@
+package emma
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"strings"
+)
+
+// movedSpotInstanceKey is the private state key of the virtual machine moved from a spot instance. The emma API
+// doesn't convert spot instances to on-demand virtual machines, so the moved spot instance is read and deleted by the
+// spot instance API until it is replaced by an on-demand virtual machine. The reclaimed spot instance that emma
+// already removed is created as an on-demand virtual machine.
+const movedSpotInstanceKey = "moved_spot_instance"
+
+// privateState is the private state of the resource in the requests of the framework.
+type privateState interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+}
+
+// isMovedSpotInstance returns true if the virtual machine was moved from a running spot instance.
+func isMovedSpotInstance(ctx context.Context, private privateState, diags *diag.Diagnostics) bool {
+	value, getDiags := private.GetKey(ctx, movedSpotInstanceKey)
+	diags.Append(getDiags...)
+	return string(value) == "true"
+}
+
+func (r *vmResource) MoveState(ctx context.Context) []resource.StateMover {
+	var spotInstanceSchema resource.SchemaResponse
+	NewSpotInstanceResource().Schema(ctx, resource.SchemaRequest{}, &spotInstanceSchema)
+
+	return []resource.StateMover{
+		{
+			SourceSchema: &spotInstanceSchema.Schema,
+			StateMover:   moveStateFromSpotInstance,
+		},
+	}
+}
+
+// moveStateFromSpotInstance maps the state of the emma_spot_instance resource onto the emma_vm resource, both resources
+// share the compute model. The on-demand virtual machine that replaced the interrupted spot instance is moved as is,
+// the running spot instance is replaced by an on-demand virtual machine of the same configuration by the next apply.
+func moveStateFromSpotInstance(ctx context.Context, req resource.MoveStateRequest, resp *resource.MoveStateResponse) {
+	if req.SourceTypeName != "emma_spot_instance" || !strings.HasSuffix(req.SourceProviderAddress, "/emma") {
+		return
+	}
+	if req.SourceState == nil {
+		resp.Diagnostics.AddError("Unable to Move Resource State",
+			fmt.Sprintf("Unable to read the state of emma_spot_instance with the schema version %d", req.SourceSchemaVersion))
+		return
+	}
+
+	var spotInstanceData spotInstanceResourceModel
+	resp.Diagnostics.Append(req.SourceState.Get(ctx, &spotInstanceData)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	vmData := vmResourceModel{computeResourceModel: spotInstanceData.computeResourceModel}
+	resp.Diagnostics.Append(resp.TargetState.Set(ctx, &vmData)...)
+	if spotInstanceData.OnDemand.ValueBool() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.TargetPrivate.SetKey(ctx, movedSpotInstanceKey, []byte("true"))...)
+	resp.Diagnostics.AddWarning("Spot Instance Moved",
+		fmt.Sprintf("The emma API doesn't convert spot instances to on-demand virtual machines, the spot instance %s "+
+			"will be replaced by an on-demand virtual machine of the same configuration", spotInstanceData.Id.ValueString()))
+}
+
+// planMovedSpotInstance replaces the spot instance moved to the emma_vm resource by an on-demand virtual machine.
+func planMovedSpotInstance(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// the resource is created or destroyed
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+	if !isMovedSpotInstance(ctx, req.Private, &resp.Diagnostics) {
+		return
+	}
+
+	resp.RequiresReplace = append(resp.RequiresReplace, path.Root("status"))
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("status"), types.StringUnknown())...)
+}
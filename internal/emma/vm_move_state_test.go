@@ -0,0 +1,213 @@
+package emma
+
+import (
+	"context"
+	"github.com/emma-community/terraform-provider-emma/internal/mock"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	testResource "github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// moveSpotInstanceState moves the spot instance state in JSON to emma_vm through the provider server.
+func moveSpotInstanceState(t *testing.T, sourceProviderAddress string, spotInstanceState []byte) (*tfprotov6.MoveResourceStateResponse, vmResourceModel) {
+	ctx := context.Background()
+	server, err := testAccProtoV6ProviderFactories["emma"]()
+	require.NoError(t, err)
+
+	resp, err := server.MoveResourceState(ctx, &tfprotov6.MoveResourceStateRequest{
+		SourceProviderAddress: sourceProviderAddress,
+		SourceTypeName:        "emma_spot_instance",
+		SourceSchemaVersion:   1,
+		SourceState:           &tfprotov6.RawState{JSON: spotInstanceState},
+		TargetTypeName:        "emma_vm",
+	})
+	require.NoError(t, err)
+
+	var vmData vmResourceModel
+	if resp.TargetState == nil {
+		return resp, vmData
+	}
+	var vmSchema resource.SchemaResponse
+	NewVmResource().Schema(ctx, resource.SchemaRequest{}, &vmSchema)
+	targetState, err := resp.TargetState.Unmarshal(vmSchema.Schema.Type().TerraformType(ctx))
+	require.NoError(t, err)
+	require.False(t, tfsdk.State{Schema: vmSchema.Schema, Raw: targetState}.Get(ctx, &vmData).HasError())
+	return resp, vmData
+}
+
+func TestMoveStateFromSpotInstance(t *testing.T) {
+	spotInstanceState, err := os.ReadFile(filepath.Join("testdata", "state_v0", "spot_instance.json"))
+	require.NoError(t, err)
+
+	resp, vmData := moveSpotInstanceState(t, "registry.terraform.io/emma-community/emma", spotInstanceState)
+	require.Len(t, resp.Diagnostics, 1)
+	assert.Equal(t, tfprotov6.DiagnosticSeverityWarning, resp.Diagnostics[0].Severity)
+	assert.Equal(t, "1002", vmData.Id.ValueString())
+	assert.Equal(t, int64(16), vmData.VolumeGb.ValueInt64())
+	assert.Equal(t, int64(2002), vmData.SecurityGroupId.ValueInt64())
+	// the running spot instance is marked to be replaced by an on-demand virtual machine
+	assert.Contains(t, string(resp.TargetPrivate), movedSpotInstanceKey)
+}
+
+// configuredProviderServer returns the provider server configured with the stub of the emma API.
+func configuredProviderServer(t *testing.T) tfprotov6.ProviderServer {
+	ctx := context.Background()
+	mockServer, err := mock.NewServer("")
+	require.NoError(t, err)
+	server := httptest.NewServer(mockServer)
+	t.Cleanup(server.Close)
+
+	var providerSchema provider.SchemaResponse
+	(&Provider{}).Schema(ctx, provider.SchemaRequest{}, &providerSchema)
+	config := tfsdk.State{Schema: providerSchema.Schema}
+	require.False(t, config.Set(ctx, &providerModel{
+		Host:                  types.StringValue(server.URL),
+		ClientId:              types.StringValue("client-id"),
+		ClientSecret:          types.StringValue("client-secret"),
+		AllowedSshKeyTypes:    types.ListNull(types.StringType),
+		DefaultLabels:         types.MapNull(types.StringType),
+		MaxConcurrentRequests: types.Int64Null(),
+		MaxConcurrentCreates:  types.Int64Null(),
+	}).HasError())
+	configValue, err := tfprotov6.NewDynamicValue(providerSchema.Schema.Type().TerraformType(ctx), config.Raw)
+	require.NoError(t, err)
+
+	providerServer, err := testAccProtoV6ProviderFactories["emma"]()
+	require.NoError(t, err)
+	resp, err := providerServer.ConfigureProvider(ctx, &tfprotov6.ConfigureProviderRequest{Config: &configValue})
+	require.NoError(t, err)
+	require.Empty(t, resp.Diagnostics)
+	return providerServer
+}
+
+func TestMoveStateFromReclaimedSpotInstance(t *testing.T) {
+	ctx := context.Background()
+	spotInstanceState, err := os.ReadFile(filepath.Join("testdata", "state_v0", "spot_instance.json"))
+	require.NoError(t, err)
+	spotInstanceState = []byte(strings.Replace(string(spotInstanceState), `"status": "ACTIVE"`, `"status": "DELETED"`, 1))
+	moveResp, vmData := moveSpotInstanceState(t, "registry.terraform.io/emma-community/emma", spotInstanceState)
+	require.Contains(t, string(moveResp.TargetPrivate), movedSpotInstanceKey)
+
+	var vmSchema resource.SchemaResponse
+	NewVmResource().Schema(ctx, resource.SchemaRequest{}, &vmSchema)
+	objectType := vmSchema.Schema.Type().TerraformType(ctx)
+	state := tfsdk.State{Schema: vmSchema.Schema}
+	require.False(t, state.Set(ctx, &vmData).HasError())
+	stateValue, err := tfprotov6.NewDynamicValue(objectType, state.Raw)
+	require.NoError(t, err)
+	nullValue, err := tfprotov6.NewDynamicValue(objectType, tftypes.NewValue(objectType, nil))
+	require.NoError(t, err)
+
+	// the spot instance removed by the cloud provider isn't found, it is removed from the state
+	server := configuredProviderServer(t)
+	readResp, err := server.ReadResource(ctx, &tfprotov6.ReadResourceRequest{
+		TypeName:     "emma_vm",
+		CurrentState: &stateValue,
+		Private:      moveResp.TargetPrivate,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, readResp.Diagnostics)
+	newState, err := readResp.NewState.Unmarshal(objectType)
+	require.NoError(t, err)
+	assert.True(t, newState.IsNull())
+
+	applyResp, err := server.ApplyResourceChange(ctx, &tfprotov6.ApplyResourceChangeRequest{
+		TypeName:       "emma_vm",
+		PriorState:     &stateValue,
+		PlannedState:   &nullValue,
+		Config:         &nullValue,
+		PlannedPrivate: moveResp.TargetPrivate,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, applyResp.Diagnostics)
+}
+
+func TestMoveStateFromSpotInstanceOnDemand(t *testing.T) {
+	spotInstanceState, err := os.ReadFile(filepath.Join("testdata", "state_v0", "spot_instance.json"))
+	require.NoError(t, err)
+	spotInstanceState = []byte(strings.Replace(string(spotInstanceState), `"id": "1002",`, `"id": "1002", "on_demand": true,`, 1))
+
+	resp, vmData := moveSpotInstanceState(t, "registry.terraform.io/emma-community/emma", spotInstanceState)
+	assert.Empty(t, resp.Diagnostics)
+	assert.Equal(t, "1002", vmData.Id.ValueString())
+	assert.NotContains(t, string(resp.TargetPrivate), movedSpotInstanceKey)
+}
+
+func TestMoveStateFromOtherProvider(t *testing.T) {
+	resp, _ := moveSpotInstanceState(t, "registry.terraform.io/example/other", []byte(`{"id": "1002"}`))
+	require.Len(t, resp.Diagnostics, 1)
+	assert.Equal(t, tfprotov6.DiagnosticSeverityError, resp.Diagnostics[0].Severity)
+	assert.Nil(t, resp.TargetState)
+}
+
+func testAccVmResourceMovedConfig(spotInstanceConfig string) string {
+	return strings.Replace(strings.Replace(spotInstanceConfig, `resource "emma_spot_instance" "test"`, `resource "emma_vm" "test"`, 1),
+		"  price              = 0.05\n", "", 1) + `
+moved {
+  from = emma_spot_instance.test
+  to   = emma_vm.test
+}
+`
+}
+
+func TestAccVmResourceMoveFromSpotInstance(t *testing.T) {
+	providerConfig := testAccProviderConfig(t)
+
+	testResource.Test(t, testResource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		TerraformVersionChecks:   []tfversion.TerraformVersionCheck{tfversion.SkipBelow(tfversion.Version1_8_0)},
+		Steps: []testResource.TestStep{
+			{
+				Config: testAccSpotInstanceResourceConfig(providerConfig, "null"),
+				Check:  testResource.TestCheckResourceAttr("emma_spot_instance.test", "id", "1001"),
+			},
+			// The running spot instance is replaced by an on-demand virtual machine
+			{
+				Config: testAccVmResourceMovedConfig(testAccSpotInstanceResourceConfig(providerConfig, "null")),
+				Check: testResource.ComposeAggregateTestCheckFunc(
+					testResource.TestCheckResourceAttr("emma_vm.test", "id", "1002"),
+					testResource.TestCheckResourceAttr("emma_vm.test", "vcpu", "2"),
+					testResource.TestCheckResourceAttr("emma_vm.test", "disks.0.size_gb", "16"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVmResourceMoveFromSpotInstanceOnDemand(t *testing.T) {
+	providerConfig, server := testAccProviderConfigWithServer(t)
+	spotInstanceConfig := testAccSpotInstanceResourceOnInterruptionConfig(providerConfig, "fallback_on_demand")
+
+	testResource.Test(t, testResource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		TerraformVersionChecks:   []tfversion.TerraformVersionCheck{tfversion.SkipBelow(tfversion.Version1_8_0)},
+		Steps: []testResource.TestStep{
+			{
+				Config: spotInstanceConfig,
+				Check:  testResource.TestCheckResourceAttr("emma_spot_instance.test", "id", "1001"),
+			},
+			{
+				PreConfig: func() { server.InterruptSpot(1001) },
+				Config:    spotInstanceConfig,
+				Check:     testResource.TestCheckResourceAttr("emma_spot_instance.test", "on_demand", "true"),
+			},
+			// The on-demand virtual machine that replaced the spot instance is moved as is
+			{
+				Config: testAccVmResourceMovedConfig(strings.Replace(spotInstanceConfig, "  on_interruption    = \"fallback_on_demand\"\n", "", 1)),
+				Check:  testResource.TestCheckResourceAttr("emma_vm.test", "id", "1002"),
+			},
+		},
+	})
+}
@@ -6,22 +6,20 @@ import (
 	emmaSdk "github.com/emma-community/emma-go-sdk"
 	emma "github.com/emma-community/terraform-provider-emma/internal/emma/validation"
 	"github.com/emma-community/terraform-provider-emma/tools"
-	"github.com/hashicorp/terraform-plugin-framework/attr"
-	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
-	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
-	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
-	"strconv"
-	"strings"
+	"net/http"
 )
 
 var _ resource.Resource = &vmResource{}
+var _ resource.ResourceWithModifyPlan = &vmResource{}
+var _ resource.ResourceWithUpgradeState = &vmResource{}
+var _ resource.ResourceWithMoveState = &vmResource{}
 
 func NewVmResource() resource.Resource {
 	return &vmResource{}
@@ -29,50 +27,16 @@ func NewVmResource() resource.Resource {
 
 // vmResource defines the resource implementation.
 type vmResource struct {
-	apiClient *emmaSdk.APIClient
-	token     *emmaSdk.Token
+	apiClient     *emmaSdk.APIClient
+	token         *emmaSdk.Token
+	naming        namingPolicy
+	defaultLabels map[string]string
+	operations    *operationLimiter
 }
 
 // vmResourceModel describes the resource data model.
 type vmResourceModel struct {
-	Id               types.String `tfsdk:"id"`
-	Name             types.String `tfsdk:"name"`
-	DataCenterId     types.String `tfsdk:"data_center_id"`
-	OsId             types.Int64  `tfsdk:"os_id"`
-	CloudNetworkType types.String `tfsdk:"cloud_network_type"`
-	VCpuType         types.String `tfsdk:"vcpu_type"`
-	VCpu             types.Int64  `tfsdk:"vcpu"`
-	RamGb            types.Int64  `tfsdk:"ram_gb"`
-	VolumeType       types.String `tfsdk:"volume_type"`
-	VolumeGb         types.Int64  `tfsdk:"volume_gb"`
-	SshKeyId         types.Int64  `tfsdk:"ssh_key_id"`
-	UserPassword     types.String `tfsdk:"user_password"`
-	SecurityGroupId  types.Int64  `tfsdk:"security_group_id"`
-	Status           types.String `tfsdk:"status"`
-	Disks            types.List   `tfsdk:"disks"`
-	Networks         types.List   `tfsdk:"networks"`
-	Cost             types.Object `tfsdk:"cost"`
-}
-
-type VmResourceDiskModel struct {
-	Id         types.Int64  `tfsdk:"id"`
-	SizeGb     types.Int64  `tfsdk:"size_gb"`
-	TypeId     types.Int64  `tfsdk:"type_id"`
-	Type_      types.String `tfsdk:"type"`
-	IsBootable types.Bool   `tfsdk:"is_bootable"`
-}
-
-type vmResourceNetworkModel struct {
-	Id            types.Int64  `tfsdk:"id"`
-	Ip            types.String `tfsdk:"ip"`
-	NetworkTypeId types.Int64  `tfsdk:"network_type_id"`
-	NetworkType   types.String `tfsdk:"network_type"`
-}
-
-type vmResourceCostModel struct {
-	Unit     types.String  `tfsdk:"unit"`
-	Currency types.String  `tfsdk:"currency"`
-	Price    types.Float64 `tfsdk:"price"`
+	computeResourceModel
 }
 
 func (r *vmResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -80,62 +44,28 @@ func (r *vmResource) Metadata(ctx context.Context, req resource.MetadataRequest,
 }
 
 func (r *vmResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
-	resp.Schema = schema.Schema{
-		Description: "This resource creates a virtual machine according to the specified parameters.\n\n" +
-			"To create a virtual machine, follow these steps:\n\n" +
-			"1. Select a data center using the `emma_data_center` data source. The data center determines the provider " +
-			"and location of the virtual machine.\n\n" +
-			"2. Select an available hardware configuration for the virtual machine.\n\n" +
-			"3. Select or create an SSH key for the virtual machine using the `emma_ssh_key` resource.\n\n" +
-			"4. Select an operating system using the `emma_operating_system` data source.\n\n" +
-			"5. Choose one of the cloud network types: multi-cloud, isolated or default. Choose the multi-cloud " +
-			"network type if you need to connect compute instances from different providers.\n\n" +
-			"6. Select or create an security group for the virtual machine using the `emma_security_group` resource. " +
-			"You may choose not to specify a security group. In this case, the virtual machine will be added to the default security group.",
-		Attributes: map[string]schema.Attribute{
-			"id": schema.StringAttribute{
-				Description:   "ID of the virtual machine",
-				Computed:      true,
-				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
-			},
-			"name": schema.StringAttribute{
-				Description:   "Name of the virtual machine, virtual machine will be recreated after changing this value",
-				Computed:      false,
-				Required:      true,
-				Optional:      false,
-				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
-				Validators:    []validator.String{emma.NotEmptyString{}, emma.VmName{}},
-			},
-			"data_center_id": schema.StringAttribute{
-				Description:   "Data center ID of the virtual machine, virtual machine will be recreated after changing this value",
-				Computed:      false,
-				Required:      true,
-				Optional:      false,
-				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
-				Validators:    []validator.String{emma.NotEmptyString{}},
-			},
-			"os_id": schema.Int64Attribute{
-				Description:   "Operating system ID of the virtual machine, virtual machine will be recreated after changing this value",
-				Computed:      false,
-				Required:      true,
-				Optional:      false,
-				PlanModifiers: []planmodifier.Int64{int64planmodifier.RequiresReplace()},
-				Validators:    []validator.Int64{emma.PositiveInt64{}},
-			},
-			"cloud_network_type": schema.StringAttribute{
-				Description:   "Cloud network type, available values: multi-cloud, isolated or default, virtual machine will be recreated after changing this value",
-				Computed:      false,
-				Required:      true,
-				Optional:      false,
-				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
-				Validators:    []validator.String{emma.CloudNetworkType{}},
-			},
+	resp.Schema = computeSchema("virtual machine", "This resource creates a virtual machine according to the specified parameters.\n\n"+
+		"To create a virtual machine, follow these steps:\n\n"+
+		"1. Select a data center using the `emma_data_center` data source. The data center determines the provider "+
+		"and location of the virtual machine.\n\n"+
+		"2. Select an available hardware configuration for the virtual machine.\n\n"+
+		"3. Select or create an SSH key for the virtual machine using the `emma_ssh_key` resource.\n\n"+
+		"4. Select an operating system using the `emma_operating_system` data source.\n\n"+
+		"5. Choose one of the cloud network types: multi-cloud, isolated or default. Choose the multi-cloud "+
+		"network type if you need to connect compute instances from different providers.\n\n"+
+		"6. Select or create an security group for the virtual machine using the `emma_security_group` resource. "+
+		"You may choose not to specify a security group. In this case, the virtual machine will be added to the default security group.\n\n"+
+		"A spot instance can be moved to this resource with a `moved` block in Terraform 1.8 and later. The emma API doesn't convert "+
+		"spot instances to on-demand virtual machines, so the running spot instance is replaced by an on-demand virtual machine of the "+
+		"same configuration, the on-demand virtual machine that replaced the interrupted spot instance is moved as is.",
+		map[string]schema.Attribute{
 			"vcpu_type": schema.StringAttribute{
-				Description: "Type of virtual Central Processing Units (vCPUs), available values: shared, standard or hpc, virtual machine will be recreated after changing this value",
-				Computed:    false,
-				Required:    true,
-				Optional:    false,
-				Validators:  []validator.String{emma.VCpuType{}},
+				Description: "Type of virtual Central Processing Units (vCPUs), available values: shared, standard or hpc, the process of edit hardware " +
+					"will start after changing this value on the clouds that change the vCPU type in place, otherwise virtual machine will be recreated",
+				Computed:   false,
+				Required:   true,
+				Optional:   false,
+				Validators: []validator.String{emma.VCpuType{}},
 			},
 			"vcpu": schema.Int64Attribute{
 				Description: "Number of virtual Central Processing Units (vCPUs), the process of edit hardware will start after changing this value",
@@ -158,104 +88,17 @@ func (r *vmResource) Schema(ctx context.Context, req resource.SchemaRequest, res
 				Validators:    []validator.String{emma.VolumeType{}},
 			},
 			"volume_gb": schema.Int64Attribute{
-				Description: "Volume size in gigabytes, the process of edit hardware will start after changing this value",
-				Required:    true,
-				Optional:    false,
-				Validators:  []validator.Int64{emma.PositiveInt64{}},
-			},
-			"ssh_key_id": schema.Int64Attribute{
-				Description:   "Ssh key ID of the virtual machine, virtual machine will be recreated after changing this value",
-				Optional:      true,
-				PlanModifiers: []planmodifier.Int64{int64planmodifier.RequiresReplace()},
-				Validators:    []validator.Int64{emma.PositiveInt64{}},
-			},
-			"user_password": schema.StringAttribute{
-				Description:   "User password of the virtual machine, virtual machine will be recreated after changing this value",
-				Optional:      true,
-				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
-				Validators:    []validator.String{emma.UserPassword{}},
-			},
-			"security_group_id": schema.Int64Attribute{
-				Description: "Security group ID of the virtual machine, the process of changing the security group will start after changing this value",
-				Computed:    false,
-				Required:    false,
-				Optional:    true,
-				Validators:  []validator.Int64{emma.PositiveInt64{}},
+				Description: "Volume size in gigabytes, the volume is resized after changing this value, before the hardware is edited if both are changed, " +
+					"on the clouds that don't resize the volume in place virtual machine will be recreated",
+				Required:   true,
+				Optional:   false,
+				Validators: []validator.Int64{emma.PositiveInt64{}},
 			},
+		})
+}
 
-			"status": schema.StringAttribute{
-				Description: "Status of the virtual machine",
-				Computed:    true,
-			},
-			"disks": schema.ListNestedAttribute{
-				Computed: true,
-				NestedObject: schema.NestedAttributeObject{
-					Attributes: map[string]schema.Attribute{
-						"id": schema.Int64Attribute{
-							Description: "Volume ID",
-							Computed:    true,
-						},
-						"size_gb": schema.Int64Attribute{
-							Description: "Volume size in gigabytes",
-							Computed:    true,
-						},
-						"type_id": schema.Int64Attribute{
-							Description: "ID of the volume type",
-							Computed:    true,
-						},
-						"type": schema.StringAttribute{
-							Description: "Volume type",
-							Computed:    true,
-						},
-						"is_bootable": schema.BoolAttribute{
-							Description: "Indicates whether the volume is bootable or not",
-							Computed:    true,
-						},
-					},
-				},
-			},
-			"networks": schema.ListNestedAttribute{
-				Computed: true,
-				NestedObject: schema.NestedAttributeObject{
-					Attributes: map[string]schema.Attribute{
-						"id": schema.Int64Attribute{
-							Description: "Network ID",
-							Computed:    true,
-						},
-						"ip": schema.StringAttribute{
-							Description: "Network IP",
-							Computed:    true,
-						},
-						"network_type_id": schema.Int64Attribute{
-							Description: "ID of the network type",
-							Computed:    true,
-						},
-						"network_type": schema.StringAttribute{
-							Description: "Network type",
-							Computed:    true,
-						},
-					},
-				},
-			},
-			"cost": schema.SingleNestedAttribute{
-				Computed: true,
-				Attributes: map[string]schema.Attribute{
-					"unit": schema.StringAttribute{
-						Description: "Cost period",
-						Computed:    true,
-					},
-					"currency": schema.StringAttribute{
-						Description: "Currency of cost",
-						Computed:    true,
-					},
-					"price": schema.Float64Attribute{
-						Description: "Cost of the virtual machine for the period",
-						Computed:    true,
-					},
-				},
-			},
-		},
-	}
+func (r *vmResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return stateUpgraders()
 }
 
 func (r *vmResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
@@ -272,6 +115,47 @@ func (r *vmResource) Configure(ctx context.Context, req resource.ConfigureReques
 	}
 	r.apiClient = client.apiClient
 	r.token = client.token
+	r.naming = client.naming
+	r.defaultLabels = client.defaultLabels
+	r.operations = client.operations
+}
+
+func (r *vmResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planFullName(ctx, r.naming, emma.ComputeNameRule, req, resp)
+	planEffectiveLabels(ctx, r.defaultLabels, req, resp)
+	planVmHardwareChanges(ctx, req, resp)
+	planVolumeShrink(ctx, "virtual machine", req, resp)
+	planMovedSpotInstance(ctx, req, resp)
+	checkDeletionProtection(ctx, "virtual machine", computeReplacedAttributes, req, resp)
+}
+
+// planVmHardwareChanges plans the changes of the vCPU type and the volume with the capabilities of the cloud provider
+// of the virtual machine, the virtual machine is replaced if they can't be changed in place.
+func planVmHardwareChanges(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// the resource is created or destroyed
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var planData, stateData vmResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &planData)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &stateData)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// the virtual machine is recreated in the other data center anyway
+	if !planData.DataCenterId.Equal(stateData.DataCenterId) {
+		return
+	}
+
+	capabilities := dataCenterCapabilities(stateData.DataCenterId.ValueString())
+	if !planData.VCpuType.Equal(stateData.VCpuType) && !capabilities.editVCpuType {
+		resp.RequiresReplace = append(resp.RequiresReplace, path.Root("vcpu_type"))
+	}
+	if !planData.VolumeGb.Equal(stateData.VolumeGb) && !capabilities.resizeVolumeInPlace {
+		resp.RequiresReplace = append(resp.RequiresReplace, path.Root("volume_gb"))
+	}
 }
 
 func (r *vmResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -289,8 +173,16 @@ func (r *vmResource) Create(ctx context.Context, req resource.CreateRequest, res
 	// If applicable, this is a great opportunity to initialize any necessary
 	// provider client data and make a call using it.
 	var vmCreateRequest emmaSdk.VmCreate
-	ConvertToVmCreateRequest(data, &vmCreateRequest)
+	applyFullName(r.naming, data.Name, &data.FullName)
+	applyEffectiveLabels(ctx, r.defaultLabels, data.Labels, &data.EffectiveLabels, &resp.Diagnostics)
+	ConvertToVmCreateRequest(data.computeResourceModel, &vmCreateRequest)
 	auth := context.WithValue(ctx, emmaSdk.ContextAccessToken, *r.token.AccessToken)
+	release, err := r.operations.acquire(ctx, "vm create")
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create virtual machine, got error: %s", err))
+		return
+	}
+	defer release()
 	vm, response, err := r.apiClient.VirtualMachinesAPI.VmCreate(auth).VmCreate(vmCreateRequest).Execute()
 
 	if err != nil {
@@ -300,7 +192,8 @@ func (r *vmResource) Create(ctx context.Context, req resource.CreateRequest, res
 		return
 	}
 
-	ConvertVmResponseToResource(ctx, &data, nil, vm, resp.Diagnostics)
+	// the created virtual machine is saved even if it isn't converted, so it's tainted instead of lost
+	ConvertComputeResponseToResource(ctx, r.naming, &data.computeResourceModel, nil, vm, &resp.Diagnostics)
 
 	// Save data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -321,104 +214,37 @@ func (r *vmResource) Read(ctx context.Context, req resource.ReadRequest, resp *r
 	// If applicable, this is a great opportunity to initialize any necessary
 	// provider client data and make a call using it.
 	auth := context.WithValue(ctx, emmaSdk.ContextAccessToken, *r.token.AccessToken)
-	vm, response, err := r.apiClient.VirtualMachinesAPI.GetVm(auth, tools.StringToInt32(data.Id.ValueString())).Execute()
-
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error",
-			fmt.Sprintf("Unable to read virtual machine, got error: %s",
-				tools.ExtractErrorMessage(response)))
-		return
-	}
-
-	ConvertVmResponseToResource(ctx, &data, nil, vm, resp.Diagnostics)
-
-	// Save updated data into Terraform state
-	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
-}
-
-func GetVolumesAsList(ctx context.Context, stateData *vmResourceModel, diagnostics diag.Diagnostics) []VmResourceDiskModel {
-	var disks []VmResourceDiskModel
-	diskDiagnostics := stateData.Disks.ElementsAs(ctx, &disks, false)
-	if diskDiagnostics.HasError() {
-		diagnostics.Append(diskDiagnostics...)
-		return nil
-	}
-	return disks
-}
-
-func GetBootableDisk(ctx context.Context, stateData *vmResourceModel, diagnostics diag.Diagnostics) *VmResourceDiskModel {
-	disks := GetVolumesAsList(ctx, stateData, diagnostics)
-	if disks == nil {
-		return nil
-	}
-
-	for _, disk := range disks {
-		if disk.IsBootable.ValueBool() {
-			return &disk
-		}
-	}
-
-	return nil
-}
-
-func ResizeVolume(ctx context.Context, stateData *vmResourceModel, resp *resource.UpdateResponse, r *vmResource, volumeId int32) {
-	bootableDisk := GetBootableDisk(ctx, stateData, resp.Diagnostics)
-	if bootableDisk == nil {
-		resp.Diagnostics.AddError("Validation Error", "Bootable disk not found")
+	var vm *emmaSdk.Vm
+	var response *http.Response
+	var err error
+	movedSpotInstance := isMovedSpotInstance(ctx, req.Private, &resp.Diagnostics)
+	if movedSpotInstance {
+		vm, response, err = r.apiClient.SpotInstancesAPI.GetSpot(auth, tools.StringToInt32(data.Id.ValueString())).Execute()
+	} else {
+		vm, response, err = r.apiClient.VirtualMachinesAPI.GetVm(auth, tools.StringToInt32(data.Id.ValueString())).Execute()
+	}
+
+	// the moved spot instance was reclaimed and removed by the cloud provider, the next apply creates the virtual machine
+	if err != nil && movedSpotInstance && response != nil && response.StatusCode == http.StatusNotFound {
+		resp.State.RemoveResource(ctx)
 		return
 	}
-	volumeEdit := emmaSdk.VolumeActionsRequest{VolumeEdit: emmaSdk.NewVolumeEdit("edit", volumeId)}
-	volume, response, err := r.apiClient.VolumesAPI.VolumeActions(ctx, int32(bootableDisk.Id.ValueInt64())).VolumeActionsRequest(volumeEdit).Execute()
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error",
-			fmt.Sprintf("Unable to resize volume, got error: %s",
+			fmt.Sprintf("Unable to read virtual machine, got error: %s",
 				tools.ExtractErrorMessage(response)))
 		return
 	}
 
-	var updatedDisks []VmResourceDiskModel
-	disks := GetVolumesAsList(ctx, stateData, resp.Diagnostics)
-	if disks != nil {
-		for _, disk := range disks {
-			if disk.Id.ValueInt64() == int64(*volume.Id) {
-				updatedDisk := VmResourceDiskModel{
-					Id:         types.Int64Value(int64(*volume.Id)),
-					SizeGb:     types.Int64Value(int64(*volume.SizeGb)),
-					TypeId:     types.Int64Value(disk.TypeId.ValueInt64()),
-					Type_:      types.StringValue(*volume.Type),
-					IsBootable: types.BoolValue(*volume.IsSystem),
-				}
-				updatedDisks = append(updatedDisks, updatedDisk)
-			} else {
-				updatedDisks = append(updatedDisks, disk)
-			}
-		}
-	}
-
-	stateData.VolumeGb = types.Int64Value(int64(*volume.SizeGb))
-	disksListValue, disksDiagnostic := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: VmResourceDiskModel{}.attrTypes()}, updatedDisks)
-	stateData.Disks = disksListValue
-	resp.Diagnostics.Append(disksDiagnostic...)
-}
-
-func EditHardware(ctx context.Context, stateData *vmResourceModel, resp *resource.UpdateResponse, r *vmResource, planData *vmResourceModel) {
-	vmActionEditHardwareRequest := emmaSdk.VmActionsRequest{}
-
-	vmEditHardware := emmaSdk.NewVmEditHardware("edithardware", int32(planData.VCpu.ValueInt64()),
-		int32(planData.RamGb.ValueInt64()), int32(planData.VolumeGb.ValueInt64()))
-	vmEditHardware.VCpuType = planData.VCpuType.ValueStringPointer()
-	vmActionEditHardwareRequest.VmEditHardware = vmEditHardware
-	vm, response, err := r.apiClient.VirtualMachinesAPI.VmActions(ctx,
-		tools.StringToInt32(stateData.Id.ValueString())).VmActionsRequest(vmActionEditHardwareRequest).Execute()
-
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error",
-			fmt.Sprintf("Unable to edit hardware of the virtual machine, got error: %s",
-				tools.ExtractErrorMessage(response)))
+	ConvertComputeResponseToResource(ctx, r.naming, &data.computeResourceModel, nil, vm, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
 		return
 	}
+	// the tags set in emma are compared with the labels to detect the drift
+	resp.Diagnostics.Append(tagsDrift(ctx, "virtual machine", data.Id.ValueString(), data.EffectiveLabels, vm.Tags)...)
 
-	ConvertEditVmHardwareResponseToResource(ctx, stateData, planData, vm, resp.Diagnostics)
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 func (r *vmResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
@@ -433,51 +259,14 @@ func (r *vmResource) Update(ctx context.Context, req resource.UpdateRequest, res
 		return
 	}
 
-	// data_center_id is string like "digitalocean-sgp1", "gcp-europe-west8-a" etc...
-	isDigitalOcean := strings.HasPrefix(strings.ToLower(strings.Trim(planData.DataCenterId.String(), "\"")), "digitalocean")
-	hardwareChanged := !planData.RamGb.Equal(stateData.RamGb) || !planData.VCpu.Equal(stateData.VCpu) || !planData.VCpuType.Equal(stateData.VCpuType)
-	volumeChanged := !planData.VolumeGb.Equal(stateData.VolumeGb)
-
-	if !isDigitalOcean && volumeChanged && hardwareChanged {
-		resp.Diagnostics.AddError("Validation Error", "Can't change volume and hardware at the same time")
-		return
-	}
-
-	if planData.VolumeGb.ValueInt64() < stateData.VolumeGb.ValueInt64() {
-		resp.Diagnostics.AddError("Validation Error", "Volume size cannot be decreased")
-		return
-	}
-
 	tflog.Info(ctx, "Update vm")
 
 	// If applicable, this is a great opportunity to initialize any necessary
 	// provider client data and make a call using it.
 	auth := context.WithValue(ctx, emmaSdk.ContextAccessToken, *r.token.AccessToken)
-
-	if !planData.SecurityGroupId.Equal(stateData.SecurityGroupId) {
-		if planData.SecurityGroupId.IsUnknown() || planData.SecurityGroupId.IsNull() {
-			stateData.SecurityGroupId = types.Int64Null()
-		} else {
-			vmId := tools.StringToInt32(stateData.Id.ValueString())
-			securityGroupInstanceAdd := emmaSdk.SecurityGroupInstanceAdd{InstanceId: &vmId}
-			vm, response, err := r.apiClient.SecurityGroupsAPI.SecurityGroupInstanceAdd(auth,
-				int32(planData.SecurityGroupId.ValueInt64())).SecurityGroupInstanceAdd(securityGroupInstanceAdd).Execute()
-			if err != nil {
-				resp.Diagnostics.AddError("Client Error",
-					fmt.Sprintf("Unable to add virtual machine to security group, got error: %s",
-						tools.ExtractErrorMessage(response)))
-				return
-			}
-			ConvertVmResponseToResource(ctx, &stateData, &planData, vm, resp.Diagnostics)
-		}
-	}
-
-	if !isDigitalOcean && volumeChanged {
-		ResizeVolume(auth, &stateData, resp, r, int32(planData.VolumeGb.ValueInt64()))
-	}
-
-	if hardwareChanged || (isDigitalOcean && volumeChanged) {
-		EditHardware(auth, &stateData, resp, r, &planData)
+	updater := computeUpdater{apiClient: r.apiClient, resourceName: "virtual machine", defaultLabels: r.defaultLabels, naming: r.naming}
+	if !updater.update(auth, &stateData, &stateData.computeResourceModel, &planData.computeResourceModel, resp) {
+		return
 	}
 
 	// Save updated data into Terraform state
@@ -494,10 +283,27 @@ func (r *vmResource) Delete(ctx context.Context, req resource.DeleteRequest, res
 		return
 	}
 
+	if data.DeletionProtection.ValueBool() {
+		addDeletionProtectionError("virtual machine", "destroy", &resp.Diagnostics)
+		return
+	}
+
 	tflog.Info(ctx, "Delete vm")
 
 	auth := context.WithValue(ctx, emmaSdk.ContextAccessToken, *r.token.AccessToken)
-	_, response, err := r.apiClient.VirtualMachinesAPI.VmDelete(auth, tools.StringToInt32(data.Id.ValueString())).Execute()
+	var response *http.Response
+	var err error
+	movedSpotInstance := isMovedSpotInstance(ctx, req.Private, &resp.Diagnostics)
+	if movedSpotInstance {
+		_, response, err = r.apiClient.SpotInstancesAPI.SpotDelete(auth, tools.StringToInt32(data.Id.ValueString())).Execute()
+	} else {
+		_, response, err = r.apiClient.VirtualMachinesAPI.VmDelete(auth, tools.StringToInt32(data.Id.ValueString())).Execute()
+	}
+
+	// the moved spot instance was already removed by the cloud provider
+	if err != nil && movedSpotInstance && response != nil && response.StatusCode == http.StatusNotFound {
+		return
+	}
 
 	// If applicable, this is a great opportunity to initialize any necessary
 	// provider client data and make a call using it.
@@ -512,179 +318,6 @@ func (r *vmResource) Delete(ctx context.Context, req resource.DeleteRequest, res
 func (r *vmResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	tflog.Info(ctx, "Import vm")
 
-	// Retrieve import ID and save to id attribute
+	// Retrieve import ID and save to id attribute, the resource is read from the emma API after the import
 	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
-
-	r.Read(ctx, resource.ReadRequest{State: resp.State, Private: resp.Private},
-		&resource.ReadResponse{State: resp.State, Private: resp.Private, Diagnostics: resp.Diagnostics})
-}
-
-func ConvertToVmCreateRequest(data vmResourceModel, vmCreate *emmaSdk.VmCreate) {
-	vmCreate.Name = data.Name.ValueString()
-	vmCreate.DataCenterId = data.DataCenterId.ValueString()
-	vmCreate.OsId = int32(data.OsId.ValueInt64())
-	vmCreate.CloudNetworkType = data.CloudNetworkType.ValueString()
-	vmCreate.VCpuType = data.VCpuType.ValueString()
-	vmCreate.VCpu = int32(data.VCpu.ValueInt64())
-	vmCreate.RamGb = int32(data.RamGb.ValueInt64())
-	vmCreate.VolumeType = data.VolumeType.ValueString()
-	vmCreate.VolumeGb = int32(data.VolumeGb.ValueInt64())
-
-	if !data.SecurityGroupId.IsUnknown() && !data.SecurityGroupId.IsNull() {
-		vmCreate.SecurityGroupId = tools.ToPointer(int32(data.SecurityGroupId.ValueInt64()))
-	}
-	if !data.UserPassword.IsUnknown() && !data.UserPassword.IsNull() {
-		vmCreate.UserPassword = tools.ToPointer(data.UserPassword.ValueString())
-	}
-	if !data.SshKeyId.IsUnknown() && !data.SshKeyId.IsNull() {
-		vmCreate.SshKeyId = tools.ToInt32PointerOrNil(data.SshKeyId)
-	}
-}
-
-func ConvertEditVmHardwareResponseToResource(ctx context.Context, stateData *vmResourceModel, planData *vmResourceModel, vm *emmaSdk.Vm, diags diag.Diagnostics) {
-	stateData.Status = types.StringValue(*vm.Status)
-
-	vmResourceCost := vmResourceCostModel{
-		Price:    types.Float64Value(float64(*vm.Cost.Price)),
-		Currency: types.StringValue(*vm.Cost.Currency),
-		Unit:     types.StringValue(*vm.Cost.Unit),
-	}
-
-	costObjectValue, costDiagnostic := types.ObjectValueFrom(ctx, vmResourceCostModel{}.attrTypes(), vmResourceCost)
-	stateData.Cost = costObjectValue
-	diags.Append(costDiagnostic...)
-
-	var disks []VmResourceDiskModel
-	for _, responseDisk := range vm.Disks {
-		disk := VmResourceDiskModel{
-			Id:         types.Int64Value(int64(*responseDisk.Id)),
-			Type_:      types.StringValue(*responseDisk.Type),
-			TypeId:     types.Int64Value(int64(*responseDisk.TypeId)),
-			SizeGb:     types.Int64Value(int64(*responseDisk.SizeGb)),
-			IsBootable: types.BoolValue(*responseDisk.IsBootable),
-		}
-		disks = append(disks, disk)
-	}
-	disksListValue, disksDiagnostic := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: VmResourceDiskModel{}.attrTypes()}, disks)
-	stateData.Disks = disksListValue
-	diags.Append(disksDiagnostic...)
-
-	var networks []vmResourceNetworkModel
-	for _, responseNetwork := range vm.Networks {
-		network := vmResourceNetworkModel{
-			Id:            types.Int64Value(int64(*responseNetwork.Id)),
-			Ip:            types.StringPointerValue(responseNetwork.Ip),
-			NetworkTypeId: types.Int64Value(int64(*responseNetwork.NetworkTypeId)),
-			NetworkType:   types.StringValue(*responseNetwork.NetworkType),
-		}
-		networks = append(networks, network)
-	}
-	networksListValue, networksDiagnostic := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: vmResourceNetworkModel{}.attrTypes()}, networks)
-	stateData.Networks = networksListValue
-	diags.Append(networksDiagnostic...)
-
-	stateData.VCpu = planData.VCpu
-	stateData.VCpuType = planData.VCpuType
-	stateData.VolumeGb = planData.VolumeGb
-	stateData.RamGb = planData.RamGb
-}
-
-func ConvertVmResponseToResource(ctx context.Context, stateData *vmResourceModel, planData *vmResourceModel, vm *emmaSdk.Vm, diags diag.Diagnostics) {
-	stateData.Id = types.StringValue(strconv.Itoa(int(*vm.Id)))
-	stateData.Status = types.StringValue(*vm.Status)
-	stateData.Name = types.StringValue(*vm.Name)
-
-	vmResourceCost := vmResourceCostModel{
-		Price:    types.Float64Value(float64(*vm.Cost.Price)),
-		Currency: types.StringValue(*vm.Cost.Currency),
-		Unit:     types.StringValue(*vm.Cost.Unit),
-	}
-
-	costObjectValue, costDiagnostic := types.ObjectValueFrom(ctx, vmResourceCostModel{}.attrTypes(), vmResourceCost)
-	stateData.Cost = costObjectValue
-	diags.Append(costDiagnostic...)
-
-	var disks []VmResourceDiskModel
-	for _, responseDisk := range vm.Disks {
-		if *responseDisk.IsBootable {
-			stateData.VolumeGb = types.Int64Value(int64(*responseDisk.SizeGb))
-			stateData.VolumeType = types.StringValue(*responseDisk.Type)
-		}
-		disk := VmResourceDiskModel{
-			Id:         types.Int64Value(int64(*responseDisk.Id)),
-			Type_:      types.StringValue(*responseDisk.Type),
-			TypeId:     types.Int64Value(int64(*responseDisk.TypeId)),
-			SizeGb:     types.Int64Value(int64(*responseDisk.SizeGb)),
-			IsBootable: types.BoolValue(*responseDisk.IsBootable),
-		}
-		disks = append(disks, disk)
-	}
-	disksListValue, disksDiagnostic := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: VmResourceDiskModel{}.attrTypes()}, disks)
-	stateData.Disks = disksListValue
-	diags.Append(disksDiagnostic...)
-
-	var networks []vmResourceNetworkModel
-	for _, responseNetwork := range vm.Networks {
-		network := vmResourceNetworkModel{
-			Id:            types.Int64Value(int64(*responseNetwork.Id)),
-			Ip:            types.StringPointerValue(responseNetwork.Ip),
-			NetworkTypeId: types.Int64Value(int64(*responseNetwork.NetworkTypeId)),
-			NetworkType:   types.StringValue(*responseNetwork.NetworkType),
-		}
-		networks = append(networks, network)
-	}
-	networksListValue, networksDiagnostic := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: vmResourceNetworkModel{}.attrTypes()}, networks)
-	stateData.Networks = networksListValue
-	diags.Append(networksDiagnostic...)
-	stateData.VCpu = types.Int64Value(int64(*vm.VCpu))
-	stateData.VCpuType = types.StringValue(*vm.VCpuType)
-
-	if vm.CloudNetworkType != nil {
-		stateData.CloudNetworkType = types.StringValue(*vm.CloudNetworkType)
-	}
-
-	if planData != nil && !planData.SecurityGroupId.IsUnknown() && !planData.SecurityGroupId.IsNull() {
-		stateData.SecurityGroupId = planData.SecurityGroupId
-	} else if !stateData.SecurityGroupId.IsUnknown() && !stateData.SecurityGroupId.IsNull() {
-		stateData.SecurityGroupId = types.Int64Value(int64(*vm.SecurityGroup.Id))
-	}
-
-	stateData.RamGb = types.Int64Value(int64(*vm.RamGb))
-	stateData.OsId = types.Int64Value(int64(*vm.Os.Id))
-	if vm.DataCenter != nil {
-		stateData.DataCenterId = types.StringValue(*vm.DataCenter.Id)
-	}
-	if vm.UserPassword != nil {
-		stateData.UserPassword = types.StringValue(*vm.UserPassword)
-	}
-	if vm.SshKeyId != nil {
-		stateData.SshKeyId = types.Int64Value(int64(*vm.SshKeyId))
-	}
-}
-
-func (o vmResourceCostModel) attrTypes() map[string]attr.Type {
-	return map[string]attr.Type{
-		"unit":     types.StringType,
-		"currency": types.StringType,
-		"price":    types.Float64Type,
-	}
-}
-
-func (o VmResourceDiskModel) attrTypes() map[string]attr.Type {
-	return map[string]attr.Type{
-		"id":          types.Int64Type,
-		"size_gb":     types.Int64Type,
-		"type_id":     types.Int64Type,
-		"type":        types.StringType,
-		"is_bootable": types.BoolType,
-	}
-}
-
-func (o vmResourceNetworkModel) attrTypes() map[string]attr.Type {
-	return map[string]attr.Type{
-		"id":              types.Int64Type,
-		"ip":              types.StringType,
-		"network_type_id": types.Int64Type,
-		"network_type":    types.StringType,
-	}
 }
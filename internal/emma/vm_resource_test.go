@@ -0,0 +1,209 @@
+package emma
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func testAccVmResourceConfig(providerConfig string, dataCenterId string, vCpu int, ramGb int, volumeGb int) string {
+	return providerConfig + fmt.Sprintf(`
+resource "emma_ssh_key" "test" {
+  name     = "vm-key"
+  key_type = "ED25519"
+}
+
+resource "emma_vm" "test" {
+  name               = "vm"
+  data_center_id     = %q
+  os_id              = 2
+  cloud_network_type = "multi-cloud"
+  vcpu_type          = "shared"
+  vcpu               = %d
+  ram_gb             = %d
+  volume_type        = "ssd"
+  volume_gb          = %d
+  ssh_key_id         = emma_ssh_key.test.id
+}
+`, dataCenterId, vCpu, ramGb, volumeGb)
+}
+
+func TestAccVmResource(t *testing.T) {
+	providerConfig := testAccProviderConfig(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: testAccVmResourceConfig(providerConfig, "aws-eu-north-1", 2, 4, 16),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("emma_vm.test", "id", "1001"),
+					resource.TestCheckResourceAttr("emma_vm.test", "full_name", "vm"),
+					resource.TestCheckResourceAttr("emma_vm.test", "vcpu", "2"),
+					resource.TestCheckResourceAttr("emma_vm.test", "disks.#", "1"),
+					resource.TestCheckResourceAttr("emma_vm.test", "disks.0.size_gb", "16"),
+					resource.TestCheckResourceAttr("emma_vm.test", "networks.#", "2"),
+					resource.TestCheckResourceAttr("emma_vm.test", "cost.currency", "EUR"),
+					resource.TestCheckResourceAttrPair("emma_vm.test", "ssh_key_id", "emma_ssh_key.test", "id"),
+				),
+			},
+			// Update hardware testing
+			{
+				Config: testAccVmResourceConfig(providerConfig, "aws-eu-north-1", 4, 8, 16),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("emma_vm.test", "id", "1001"),
+					resource.TestCheckResourceAttr("emma_vm.test", "vcpu", "4"),
+					resource.TestCheckResourceAttr("emma_vm.test", "ram_gb", "8"),
+				),
+			},
+			// Resize volume testing
+			{
+				Config: testAccVmResourceConfig(providerConfig, "aws-eu-north-1", 4, 8, 32),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("emma_vm.test", "id", "1001"),
+					resource.TestCheckResourceAttr("emma_vm.test", "volume_gb", "32"),
+					resource.TestCheckResourceAttr("emma_vm.test", "disks.0.size_gb", "32"),
+				),
+			},
+			// Volume and hardware are changed in sequence outside DigitalOcean
+			{
+				Config: testAccVmResourceConfig(providerConfig, "aws-eu-north-1", 8, 16, 64),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("emma_vm.test", "id", "1001"),
+					resource.TestCheckResourceAttr("emma_vm.test", "vcpu", "8"),
+					resource.TestCheckResourceAttr("emma_vm.test", "ram_gb", "16"),
+					resource.TestCheckResourceAttr("emma_vm.test", "volume_gb", "64"),
+					resource.TestCheckResourceAttr("emma_vm.test", "disks.0.size_gb", "64"),
+				),
+			},
+			// ImportState testing
+			{
+				Config:                  testAccVmResourceConfig(providerConfig, "aws-eu-north-1", 8, 16, 64),
+				ResourceName:            "emma_vm.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"status"},
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestAccVmResourceDigitalOcean(t *testing.T) {
+	providerConfig := testAccProviderConfig(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVmResourceConfig(providerConfig, "digitalocean-ams3", 2, 4, 16),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("emma_vm.test", "data_center_id", "digitalocean-ams3"),
+					resource.TestCheckResourceAttr("emma_vm.test", "disks.0.size_gb", "16"),
+				),
+			},
+			// DigitalOcean changes the volume together with the hardware
+			{
+				Config: testAccVmResourceConfig(providerConfig, "digitalocean-ams3", 4, 8, 32),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("emma_vm.test", "id", "1001"),
+					resource.TestCheckResourceAttr("emma_vm.test", "vcpu", "4"),
+					resource.TestCheckResourceAttr("emma_vm.test", "ram_gb", "8"),
+					resource.TestCheckResourceAttr("emma_vm.test", "volume_gb", "32"),
+					resource.TestCheckResourceAttr("emma_vm.test", "disks.0.size_gb", "32"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVmResourceVolumeShrink(t *testing.T) {
+	providerConfig := testAccProviderConfig(t)
+	allowVolumeShrinkByReplace := func(config string) string {
+		return strings.Replace(config, `  volume_gb          = 16`, `  volume_gb          = 16
+  allow_volume_shrink_by_replace = true`, 1)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVmResourceConfig(providerConfig, "aws-eu-north-1", 2, 4, 32),
+				Check:  resource.TestCheckResourceAttr("emma_vm.test", "id", "1001"),
+			},
+			// The volume can't be decreased in place, the plan fails before anything is changed
+			{
+				Config:      testAccVmResourceConfig(providerConfig, "aws-eu-north-1", 2, 4, 16),
+				ExpectError: regexp.MustCompile("cannot be decreased from 32 to 16 GB"),
+			},
+			// The virtual machine with the decreased volume replaces the existing one
+			{
+				Config: allowVolumeShrinkByReplace(testAccVmResourceConfig(providerConfig, "aws-eu-north-1", 2, 4, 16)),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("emma_vm.test", "id", "1002"),
+					resource.TestCheckResourceAttr("emma_vm.test", "volume_gb", "16"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVmResourceVCpuType(t *testing.T) {
+	providerConfig := testAccProviderConfig(t)
+	vCpuType := func(config string, vCpuType string) string {
+		return strings.Replace(config, `vcpu_type          = "shared"`, fmt.Sprintf(`vcpu_type          = %q`, vCpuType), 1)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVmResourceConfig(providerConfig, "aws-eu-north-1", 2, 4, 16),
+				Check:  resource.TestCheckResourceAttr("emma_vm.test", "vcpu_type", "shared"),
+			},
+			// Amazon EC2 changes the vCPU type by the edit hardware action
+			{
+				Config: vCpuType(testAccVmResourceConfig(providerConfig, "aws-eu-north-1", 2, 4, 16), "standard"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{plancheck.ExpectResourceAction("emma_vm.test", plancheck.ResourceActionUpdate)},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("emma_vm.test", "id", "1001"),
+					resource.TestCheckResourceAttr("emma_vm.test", "vcpu_type", "standard"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccVmResourceVCpuTypeDigitalOcean(t *testing.T) {
+	providerConfig := testAccProviderConfig(t)
+	vCpuType := func(config string, vCpuType string) string {
+		return strings.Replace(config, `vcpu_type          = "shared"`, fmt.Sprintf(`vcpu_type          = %q`, vCpuType), 1)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccVmResourceConfig(providerConfig, "digitalocean-ams3", 2, 4, 16),
+				Check:  resource.TestCheckResourceAttr("emma_vm.test", "id", "1001"),
+			},
+			// DigitalOcean can't change the vCPU type, the virtual machine is replaced
+			{
+				Config: vCpuType(testAccVmResourceConfig(providerConfig, "digitalocean-ams3", 2, 4, 16), "standard"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{plancheck.ExpectResourceAction("emma_vm.test", plancheck.ResourceActionReplace)},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("emma_vm.test", "id", "1002"),
+					resource.TestCheckResourceAttr("emma_vm.test", "vcpu_type", "standard"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,52 @@
+package emma
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// allowVolumeShrinkByReplaceDescription is the description of the allow_volume_shrink_by_replace attribute.
+const allowVolumeShrinkByReplaceDescription = "Whether decreasing volume_gb replaces the %s. The volumes can't be decreased in place, " +
+	"so by default the plan that decreases volume_gb fails"
+
+// planVolumeShrink fails the plan that decreases the volume of the compute instance, unless the cloud provider
+// decreases the volume in place or allow_volume_shrink_by_replace is true, then the compute instance is replaced.
+func planVolumeShrink(ctx context.Context, resourceName string, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// the resource is created or destroyed
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var planVolumeGb, stateVolumeGb types.Int64
+	var dataCenterId types.String
+	var allowVolumeShrinkByReplace types.Bool
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("volume_gb"), &planVolumeGb)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("volume_gb"), &stateVolumeGb)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("data_center_id"), &dataCenterId)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("allow_volume_shrink_by_replace"), &allowVolumeShrinkByReplace)...)
+	if resp.Diagnostics.HasError() || planVolumeGb.IsUnknown() || planVolumeGb.IsNull() ||
+		planVolumeGb.ValueInt64() >= stateVolumeGb.ValueInt64() {
+		return
+	}
+
+	if dataCenterCapabilities(dataCenterId.ValueString()).shrinkVolume {
+		return
+	}
+	if allowVolumeShrinkByReplace.ValueBool() {
+		if !resp.RequiresReplace.Contains(path.Root("volume_gb")) {
+			resp.RequiresReplace = append(resp.RequiresReplace, path.Root("volume_gb"))
+		}
+		return
+	}
+	addVolumeShrinkError(path.Root("volume_gb"), resourceName, stateVolumeGb.ValueInt64(), planVolumeGb.ValueInt64(), &resp.Diagnostics)
+}
+
+func addVolumeShrinkError(attributePath path.Path, resourceName string, stateVolumeGb int64, planVolumeGb int64, diags *diag.Diagnostics) {
+	diags.AddAttributeError(attributePath, "Validation Error",
+		fmt.Sprintf("Volume size of the %s cannot be decreased from %d to %d GB, "+
+			"set allow_volume_shrink_by_replace to true to replace the %s instead", resourceName, stateVolumeGb, planVolumeGb, resourceName))
+}
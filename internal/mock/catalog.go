@@ -0,0 +1,180 @@
+package mock
+
+import (
+	"fmt"
+	emmaSdk "github.com/emma-community/emma-go-sdk"
+	"net/http"
+	"strconv"
+)
+
+var providers = []emmaSdk.Provider{
+	{Id: emmaSdk.PtrInt32(1), Name: emmaSdk.PtrString("Amazon EC2")},
+	{Id: emmaSdk.PtrInt32(2), Name: emmaSdk.PtrString("Microsoft Azure")},
+	{Id: emmaSdk.PtrInt32(3), Name: emmaSdk.PtrString("Google Cloud Platform")},
+	{Id: emmaSdk.PtrInt32(4), Name: emmaSdk.PtrString("DigitalOcean")},
+}
+
+var locations = []emmaSdk.Location{
+	newLocation(1, "Stockholm", "Europe", "Northern Europe", 59.33, 18.07),
+	newLocation(2, "Frankfurt", "Europe", "Western Europe", 50.11, 8.68),
+	newLocation(3, "Amsterdam", "Europe", "Western Europe", 52.37, 4.90),
+	newLocation(4, "Milan", "Europe", "Southern Europe", 45.46, 9.19),
+	newLocation(5, "Singapore", "Asia", "South-eastern Asia", 1.35, 103.82),
+}
+
+var dataCenters = []emmaSdk.DataCenter{
+	newDataCenter("aws-eu-north-1", "eu-north-1", 1, 1),
+	newDataCenter("aws-eu-central-1", "eu-central-1", 1, 2),
+	newDataCenter("azure-westeurope", "westeurope", 2, 3),
+	newDataCenter("gcp-europe-west8-a", "europe-west8-a", 3, 4),
+	newDataCenter("digitalocean-ams3", "ams3", 4, 3),
+	newDataCenter("digitalocean-sgp1", "sgp1", 4, 5),
+}
+
+var operatingSystems = []emmaSdk.OperatingSystem{
+	newOperatingSystem(1, "Ubuntu", "x86-64", "20.04"),
+	newOperatingSystem(2, "Ubuntu", "x86-64", "22.04"),
+	newOperatingSystem(3, "Ubuntu", "x86-64", "24.04"),
+	newOperatingSystem(4, "Debian", "x86-64", "12"),
+	newOperatingSystem(5, "CentOS", "x86-64", "9"),
+}
+
+func newLocation(id int32, name string, continent string, region string, latitude float64, longitude float64) emmaSdk.Location {
+	return emmaSdk.Location{Id: emmaSdk.PtrInt32(id), Name: emmaSdk.PtrString(name), Continent: emmaSdk.PtrString(continent),
+		Region: emmaSdk.PtrString(region), Latitude: emmaSdk.PtrFloat64(latitude), Longitude: emmaSdk.PtrFloat64(longitude)}
+}
+
+func newDataCenter(id string, name string, providerId int32, locationId int32) emmaSdk.DataCenter {
+	return emmaSdk.DataCenter{Id: emmaSdk.PtrString(id), Name: emmaSdk.PtrString(name),
+		ProviderId: emmaSdk.PtrInt32(providerId), ProviderName: providers[providerId-1].Name,
+		LocationId: emmaSdk.PtrInt32(locationId), LocationName: locations[locationId-1].Name}
+}
+
+func newOperatingSystem(id int32, osType string, architecture string, version string) emmaSdk.OperatingSystem {
+	return emmaSdk.OperatingSystem{Id: emmaSdk.PtrInt32(id), Family: emmaSdk.PtrString("Linux"), Type: emmaSdk.PtrString(osType),
+		Architecture: emmaSdk.PtrString(architecture), Version: emmaSdk.PtrString(version)}
+}
+
+func findDataCenter(id string) *emmaSdk.DataCenter {
+	for i := range dataCenters {
+		if *dataCenters[i].Id == id {
+			return &dataCenters[i]
+		}
+	}
+	return nil
+}
+
+func findOperatingSystem(id int32) *emmaSdk.OperatingSystem {
+	for i := range operatingSystems {
+		if *operatingSystems[i].Id == id {
+			return &operatingSystems[i]
+		}
+	}
+	return nil
+}
+
+// matchesQuery reports whether the value matches the query parameter, a missing or empty parameter matches any value.
+func matchesQuery(req *http.Request, name string, value string) bool {
+	query := req.URL.Query().Get(name)
+	return query == "" || query == value
+}
+
+func (s *Server) getDataCenters(w http.ResponseWriter, req *http.Request) error {
+	result := make([]emmaSdk.DataCenter, 0)
+	for _, dataCenter := range dataCenters {
+		if matchesQuery(req, "dataCenterName", *dataCenter.Name) && matchesQuery(req, "providerName", *dataCenter.ProviderName) &&
+			matchesQuery(req, "locationId", strconv.Itoa(int(*dataCenter.LocationId))) {
+			result = append(result, dataCenter)
+		}
+	}
+	writeJSON(w, http.StatusOK, result)
+	return nil
+}
+
+func (s *Server) getDataCenter(w http.ResponseWriter, req *http.Request) error {
+	dataCenter := findDataCenter(req.PathValue("dataCenterId"))
+	if dataCenter == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Data center %s not found", req.PathValue("dataCenterId")))
+		return nil
+	}
+	writeJSON(w, http.StatusOK, dataCenter)
+	return nil
+}
+
+func (s *Server) getLocations(w http.ResponseWriter, req *http.Request) error {
+	result := make([]emmaSdk.Location, 0)
+	for _, location := range locations {
+		if matchesQuery(req, "name", *location.Name) {
+			result = append(result, location)
+		}
+	}
+	writeJSON(w, http.StatusOK, result)
+	return nil
+}
+
+func (s *Server) getLocation(w http.ResponseWriter, req *http.Request) error {
+	id, ok := pathId(w, req, "locationId")
+	if !ok {
+		return nil
+	}
+	for _, location := range locations {
+		if *location.Id == id {
+			writeJSON(w, http.StatusOK, location)
+			return nil
+		}
+	}
+	writeError(w, http.StatusNotFound, fmt.Sprintf("Location %d not found", id))
+	return nil
+}
+
+func (s *Server) getOperatingSystems(w http.ResponseWriter, req *http.Request) error {
+	result := make([]emmaSdk.OperatingSystem, 0)
+	for _, operatingSystem := range operatingSystems {
+		if matchesQuery(req, "type", *operatingSystem.Type) && matchesQuery(req, "architecture", *operatingSystem.Architecture) &&
+			matchesQuery(req, "version", *operatingSystem.Version) {
+			result = append(result, operatingSystem)
+		}
+	}
+	writeJSON(w, http.StatusOK, result)
+	return nil
+}
+
+func (s *Server) getOperatingSystem(w http.ResponseWriter, req *http.Request) error {
+	id, ok := pathId(w, req, "operatingSystemId")
+	if !ok {
+		return nil
+	}
+	operatingSystem := findOperatingSystem(id)
+	if operatingSystem == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Operating system %d not found", id))
+		return nil
+	}
+	writeJSON(w, http.StatusOK, operatingSystem)
+	return nil
+}
+
+func (s *Server) getProviders(w http.ResponseWriter, req *http.Request) error {
+	result := make([]emmaSdk.Provider, 0)
+	for _, provider := range providers {
+		if matchesQuery(req, "providerName", *provider.Name) {
+			result = append(result, provider)
+		}
+	}
+	writeJSON(w, http.StatusOK, result)
+	return nil
+}
+
+func (s *Server) getProvider(w http.ResponseWriter, req *http.Request) error {
+	id, ok := pathId(w, req, "providerId")
+	if !ok {
+		return nil
+	}
+	for _, provider := range providers {
+		if *provider.Id == id {
+			writeJSON(w, http.StatusOK, provider)
+			return nil
+		}
+	}
+	writeError(w, http.StatusNotFound, fmt.Sprintf("Provider %d not found", id))
+	return nil
+}
@@ -0,0 +1,376 @@
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	emmaSdk "github.com/emma-community/emma-go-sdk"
+	"net/http"
+)
+
+// createdAt is the creation time of all resources, it is fixed to keep the responses deterministic.
+const createdAt = "2024-01-01T00:00:00Z"
+
+// computeInstance is the request of a virtual machine or a spot instance.
+type computeInstance struct {
+	Name             string
+	DataCenterId     string
+	OsId             int32
+	CloudNetworkType string
+	VCpuType         string
+	VCpu             int32
+	RamGb            int32
+	VolumeType       string
+	VolumeGb         int32
+	SshKeyId         *int32
+	UserPassword     *string
+	SecurityGroupId  *int32
+	Price            float32
+}
+
+// computeAction is the action of a virtual machine or a spot instance, the fields are used by the actions
+// of the action type.
+type computeAction struct {
+	Action   string  `json:"action"`
+	VCpu     *int32  `json:"vCpu"`
+	VCpuType *string `json:"vCpuType"`
+	RamGb    *int32  `json:"ramGb"`
+	VolumeGb *int32  `json:"volumeGb"`
+}
+
+// newComputeInstance validates the request and returns the new virtual machine or spot instance.
+func (s *Server) newComputeInstance(w http.ResponseWriter, request computeInstance) *emmaSdk.Vm {
+	dataCenter := findDataCenter(request.DataCenterId)
+	if dataCenter == nil {
+		writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("Data center %s not found", request.DataCenterId))
+		return nil
+	}
+	operatingSystem := findOperatingSystem(request.OsId)
+	if operatingSystem == nil {
+		writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("Operating system %d not found", request.OsId))
+		return nil
+	}
+	if request.SshKeyId == nil && request.UserPassword == nil {
+		writeError(w, http.StatusUnprocessableEntity, "SSH key or user password is required")
+		return nil
+	}
+	if request.SshKeyId != nil && s.state.SshKeys[*request.SshKeyId] == nil {
+		writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("SSH key %d not found", *request.SshKeyId))
+		return nil
+	}
+	securityGroup := s.defaultSecurityGroup()
+	if request.SecurityGroupId != nil {
+		securityGroup = s.state.SecurityGroups[*request.SecurityGroupId]
+		if securityGroup == nil {
+			writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("Security group %d not found", *request.SecurityGroupId))
+			return nil
+		}
+	}
+
+	id := s.state.nextId("vm")
+	location := locations[*dataCenter.LocationId-1]
+	price := request.Price
+	if price == 0 {
+		price = computePrice(request.VCpu, request.RamGb, request.VolumeGb)
+	}
+	return &emmaSdk.Vm{
+		Id:               emmaSdk.PtrInt32(id),
+		CreatedAt:        emmaSdk.PtrString(createdAt),
+		Name:             emmaSdk.PtrString(request.Name),
+		Status:           emmaSdk.PtrString(statusBusy),
+		Provider:         &emmaSdk.VmProvider{Id: dataCenter.ProviderId, Name: dataCenter.ProviderName},
+		Location:         &emmaSdk.VmLocation{Id: location.Id, Name: location.Name, Continent: location.Continent, Region: location.Region, Latitude: location.Latitude, Longitude: location.Longitude},
+		DataCenter:       &emmaSdk.VmDataCenter{Id: dataCenter.Id, Name: dataCenter.Name, ProviderId: dataCenter.ProviderId, ProviderName: dataCenter.ProviderName, LocationId: dataCenter.LocationId, LocationName: dataCenter.LocationName},
+		Os:               &emmaSdk.VmOs{Id: operatingSystem.Id, Family: operatingSystem.Family, Architecture: operatingSystem.Architecture, Type: operatingSystem.Type, Version: operatingSystem.Version},
+		VCpu:             emmaSdk.PtrInt32(request.VCpu),
+		VCpuType:         emmaSdk.PtrString(request.VCpuType),
+		CloudNetworkType: emmaSdk.PtrString(request.CloudNetworkType),
+		RamGb:            emmaSdk.PtrInt32(request.RamGb),
+		Disks: []emmaSdk.KubernetesNodeGroupsInnerNodesInnerDisksInner{{
+			Id:         emmaSdk.PtrInt32(s.state.nextId("volume")),
+			SizeGb:     emmaSdk.PtrInt32(request.VolumeGb),
+			TypeId:     emmaSdk.PtrInt32(volumeTypeId(request.VolumeType)),
+			Type:       emmaSdk.PtrString(request.VolumeType),
+			IsBootable: emmaSdk.PtrBool(true),
+		}},
+		Networks:      s.newNetworks(id),
+		SecurityGroup: &emmaSdk.VmSecurityGroup{Id: securityGroup.Id, Name: securityGroup.Name},
+		SshKeyId:      request.SshKeyId,
+		UserName:      emmaSdk.PtrString("ubuntu"),
+		UserPassword:  request.UserPassword,
+		Cost:          &emmaSdk.VmCost{Unit: emmaSdk.PtrString("HOURS"), Currency: emmaSdk.PtrString("EUR"), Price: emmaSdk.PtrFloat32(price)},
+	}
+}
+
+// newNetworks returns the private and the public network of the instance, the addresses are derived from the id.
+func (s *Server) newNetworks(instanceId int32) []emmaSdk.KubernetesNodeGroupsInnerNodesInnerNetworksInner {
+	return []emmaSdk.KubernetesNodeGroupsInnerNodesInnerNetworksInner{
+		{
+			Id:            emmaSdk.PtrInt32(s.state.nextId("network")),
+			Ip:            emmaSdk.PtrString(fmt.Sprintf("10.0.%d.%d", instanceId/250%250, instanceId%250+2)),
+			NetworkTypeId: emmaSdk.PtrInt32(1),
+			NetworkType:   emmaSdk.PtrString("private"),
+		},
+		{
+			Id:            emmaSdk.PtrInt32(s.state.nextId("network")),
+			Ip:            emmaSdk.PtrString(fmt.Sprintf("203.0.113.%d", instanceId%250+2)),
+			NetworkTypeId: emmaSdk.PtrInt32(2),
+			NetworkType:   emmaSdk.PtrString("public"),
+		},
+	}
+}
+
+// computePrice returns the hourly price of the instance configuration.
+func computePrice(vCpu int32, ramGb int32, volumeGb int32) float32 {
+	return float32(vCpu)*0.02 + float32(ramGb)*0.005 + float32(volumeGb)*0.0002
+}
+
+func volumeTypeId(volumeType string) int32 {
+	if volumeType == "ssd-plus" {
+		return 2
+	}
+	return 1
+}
+
+// settle changes the transitional status of the instance to the final status.
+func settle(instance *emmaSdk.Vm) {
+	if *instance.Status == statusBusy {
+		instance.Status = emmaSdk.PtrString(statusRunning)
+	}
+}
+
+// bootableDisk returns the bootable disk of the instance.
+func bootableDisk(instance *emmaSdk.Vm) *emmaSdk.KubernetesNodeGroupsInnerNodesInnerDisksInner {
+	for i := range instance.Disks {
+		if instance.Disks[i].IsBootable != nil && *instance.Disks[i].IsBootable {
+			return &instance.Disks[i]
+		}
+	}
+	return nil
+}
+
+func (s *Server) getVms(w http.ResponseWriter, req *http.Request) error {
+	writeJSON(w, http.StatusOK, sortedValues(s.state.Vms, settle))
+	return nil
+}
+
+func (s *Server) createVm(w http.ResponseWriter, req *http.Request) error {
+	var request emmaSdk.VmCreate
+	if !readJSON(w, req, &request) {
+		return nil
+	}
+	vm := s.newComputeInstance(w, computeInstance{Name: request.Name, DataCenterId: request.DataCenterId, OsId: request.OsId,
+		CloudNetworkType: request.CloudNetworkType, VCpuType: request.VCpuType, VCpu: request.VCpu, RamGb: request.RamGb,
+		VolumeType: request.VolumeType, VolumeGb: request.VolumeGb, SshKeyId: request.SshKeyId,
+		UserPassword: request.UserPassword, SecurityGroupId: request.SecurityGroupId})
+	if vm == nil {
+		return nil
+	}
+	s.state.Vms[*vm.Id] = vm
+	writeJSON(w, http.StatusCreated, vm)
+	return nil
+}
+
+func (s *Server) getVm(w http.ResponseWriter, req *http.Request) error {
+	vm := s.findInstance(w, req, s.state.Vms, "vmId", "Virtual machine")
+	if vm == nil {
+		return nil
+	}
+	settle(vm)
+	writeJSON(w, http.StatusOK, vm)
+	return nil
+}
+
+func (s *Server) deleteVm(w http.ResponseWriter, req *http.Request) error {
+	vm := s.findInstance(w, req, s.state.Vms, "vmId", "Virtual machine")
+	if vm == nil {
+		return nil
+	}
+	delete(s.state.Vms, *vm.Id)
+	vm.Status = emmaSdk.PtrString("DELETED")
+	writeJSON(w, http.StatusOK, vm)
+	return nil
+}
+
+func (s *Server) vmActions(w http.ResponseWriter, req *http.Request) error {
+	vm := s.findInstance(w, req, s.state.Vms, "vmId", "Virtual machine")
+	if vm == nil {
+		return nil
+	}
+	var action computeAction
+	if !readJSON(w, req, &action) {
+		return nil
+	}
+
+	switch action.Action {
+	case "edithardware", "resizecompute":
+		if action.VCpu == nil || action.RamGb == nil {
+			writeError(w, http.StatusBadRequest, "vCpu and ramGb are required")
+			return nil
+		}
+		// the hardware of the instance can't be changed until the previous change is finished
+		if *vm.Status == statusBusy {
+			writeError(w, http.StatusConflict, "Virtual machine is busy, wait until the previous action is finished")
+			return nil
+		}
+		if action.Action == "edithardware" && action.VolumeGb != nil {
+			disk := bootableDisk(vm)
+			if *action.VolumeGb < *disk.SizeGb {
+				writeError(w, http.StatusUnprocessableEntity, "Volume size cannot be decreased")
+				return nil
+			}
+			// only DigitalOcean changes the volume together with the hardware, other providers resize the volume separately
+			if *action.VolumeGb != *disk.SizeGb && *vm.Provider.Name != "DigitalOcean" {
+				writeError(w, http.StatusUnprocessableEntity, "Volume can't be changed with the hardware, resize the volume instead")
+				return nil
+			}
+			disk.SizeGb = action.VolumeGb
+		}
+		// DigitalOcean doesn't change the vCPU type of the droplet
+		if action.VCpuType != nil && *action.VCpuType != *vm.VCpuType && *vm.Provider.Name == "DigitalOcean" {
+			writeError(w, http.StatusUnprocessableEntity, "vCPU type can't be changed, recreate the virtual machine instead")
+			return nil
+		}
+		vm.VCpu = action.VCpu
+		vm.RamGb = action.RamGb
+		if action.VCpuType != nil {
+			vm.VCpuType = action.VCpuType
+		}
+		vm.Cost.Price = emmaSdk.PtrFloat32(computePrice(*vm.VCpu, *vm.RamGb, *bootableDisk(vm).SizeGb))
+		vm.Status = emmaSdk.PtrString(statusBusy)
+	case "shutdown":
+		vm.Status = emmaSdk.PtrString(statusStopped)
+	case "start", "reboot":
+		vm.Status = emmaSdk.PtrString(statusBusy)
+	default:
+		writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("Action %q isn't supported", action.Action))
+		return nil
+	}
+	vm.ModifiedAt = emmaSdk.PtrString(createdAt)
+	writeJSON(w, http.StatusOK, vm)
+	return nil
+}
+
+func (s *Server) getSpots(w http.ResponseWriter, req *http.Request) error {
+	writeJSON(w, http.StatusOK, sortedValues(s.state.Spots, settle))
+	return nil
+}
+
+func (s *Server) createSpot(w http.ResponseWriter, req *http.Request) error {
+	var request emmaSdk.SpotCreate
+	if !readJSON(w, req, &request) {
+		return nil
+	}
+	if request.Price <= 0 {
+		writeError(w, http.StatusUnprocessableEntity, "Price must be greater than 0")
+		return nil
+	}
+	spot := s.newComputeInstance(w, computeInstance{Name: request.Name, DataCenterId: request.DataCenterId, OsId: request.OsId,
+		CloudNetworkType: request.CloudNetworkType, VCpuType: request.VCpuType, VCpu: request.VCpu, RamGb: request.RamGb,
+		VolumeType: request.VolumeType, VolumeGb: request.VolumeGb, SshKeyId: request.SshKeyId,
+		UserPassword: request.UserPassword, SecurityGroupId: request.SecurityGroupId, Price: request.Price})
+	if spot == nil {
+		return nil
+	}
+	s.state.Spots[*spot.Id] = spot
+	writeJSON(w, http.StatusCreated, spot)
+	return nil
+}
+
+func (s *Server) getSpot(w http.ResponseWriter, req *http.Request) error {
+	spot := s.findInstance(w, req, s.state.Spots, "spotInstanceId", "Spot instance")
+	if spot == nil {
+		return nil
+	}
+	settle(spot)
+	writeJSON(w, http.StatusOK, spot)
+	return nil
+}
+
+func (s *Server) deleteSpot(w http.ResponseWriter, req *http.Request) error {
+	spot := s.findInstance(w, req, s.state.Spots, "spotInstanceId", "Spot instance")
+	if spot == nil {
+		return nil
+	}
+	delete(s.state.Spots, *spot.Id)
+	spot.Status = emmaSdk.PtrString("DELETED")
+	writeJSON(w, http.StatusOK, spot)
+	return nil
+}
+
+// InterruptSpot changes the status of the spot instance to FAILED as if the cloud provider reclaimed it,
+// it returns false if the spot instance doesn't exist.
+func (s *Server) InterruptSpot(id int32) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	spot := s.state.Spots[id]
+	if spot == nil {
+		return false
+	}
+	spot.Status = emmaSdk.PtrString(statusFailed)
+	return s.save() == nil
+}
+
+func (s *Server) spotActions(w http.ResponseWriter, req *http.Request) error {
+	spot := s.findInstance(w, req, s.state.Spots, "spotInstanceId", "Spot instance")
+	if spot == nil {
+		return nil
+	}
+	var action computeAction
+	if !readJSON(w, req, &action) {
+		return nil
+	}
+	if action.Action != "reboot" {
+		writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("Action %q isn't supported", action.Action))
+		return nil
+	}
+	spot.Status = emmaSdk.PtrString(statusBusy)
+	writeJSON(w, http.StatusOK, spot)
+	return nil
+}
+
+func (s *Server) volumeActions(w http.ResponseWriter, req *http.Request) error {
+	id, ok := pathId(w, req, "volumeId")
+	if !ok {
+		return nil
+	}
+	var action emmaSdk.VolumeEdit
+	if err := json.NewDecoder(req.Body).Decode(&action); err != nil || action.Action != "edit" {
+		writeError(w, http.StatusBadRequest, "Only the edit action is supported")
+		return nil
+	}
+
+	for _, instances := range []map[int32]*emmaSdk.Vm{s.state.Vms, s.state.Spots} {
+		for _, instance := range instances {
+			for i := range instance.Disks {
+				disk := &instance.Disks[i]
+				if *disk.Id != id {
+					continue
+				}
+				if action.VolumeGb < *disk.SizeGb {
+					writeError(w, http.StatusUnprocessableEntity, "Volume size cannot be decreased")
+					return nil
+				}
+				disk.SizeGb = emmaSdk.PtrInt32(action.VolumeGb)
+				instance.Status = emmaSdk.PtrString(statusBusy)
+				writeJSON(w, http.StatusOK, emmaSdk.Volume{Id: disk.Id, AttachedToId: instance.Id, SizeGb: disk.SizeGb,
+					Type: disk.Type, IsSystem: disk.IsBootable})
+				return nil
+			}
+		}
+	}
+	writeError(w, http.StatusNotFound, fmt.Sprintf("Volume %d not found", id))
+	return nil
+}
+
+// findInstance returns the instance of the path parameter, the not found error is written if it doesn't exist.
+func (s *Server) findInstance(w http.ResponseWriter, req *http.Request, instances map[int32]*emmaSdk.Vm, name string, kind string) *emmaSdk.Vm {
+	id, ok := pathId(w, req, name)
+	if !ok {
+		return nil
+	}
+	instance := instances[id]
+	if instance == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("%s %d not found", kind, id))
+	}
+	return instance
+}
@@ -0,0 +1,251 @@
+package mock
+
+import (
+	"fmt"
+	emmaSdk "github.com/emma-community/emma-go-sdk"
+	"net/http"
+)
+
+// workerNode is the worker node of the cluster request, the id is set for the existing nodes on update.
+type workerNode struct {
+	Id           *int32
+	Name         string
+	DataCenterId string
+	VCpuType     string
+	VCpu         int32
+	RamGb        int32
+	VolumeType   string
+	VolumeGb     int32
+}
+
+// settleKubernetes changes the transitional status of the cluster and its nodes to the final status.
+func settleKubernetes(kubernetes *emmaSdk.Kubernetes) {
+	kubernetes.Status = emmaSdk.PtrString(statusRunning)
+	kubernetes.ControlPlaneStatus = emmaSdk.PtrString(statusRunning)
+	for _, nodeGroup := range kubernetes.NodeGroups {
+		for i := range nodeGroup.Nodes {
+			nodeGroup.Nodes[i].Status = emmaSdk.PtrString(statusRunning)
+		}
+	}
+}
+
+// findKubernetes returns the cluster of the path parameter, the not found error is written if it doesn't exist.
+func (s *Server) findKubernetes(w http.ResponseWriter, req *http.Request) *emmaSdk.Kubernetes {
+	id, ok := pathId(w, req, "kubernetesId")
+	if !ok {
+		return nil
+	}
+	kubernetes := s.state.Kubernetes[id]
+	if kubernetes == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Kubernetes cluster %d not found", id))
+	}
+	return kubernetes
+}
+
+// newNodes returns the nodes of the cluster in the order of the request. The existing nodes are kept, the nodes
+// without an id are created.
+func (s *Server) newNodes(w http.ResponseWriter, kubernetes *emmaSdk.Kubernetes, workerNodes []workerNode) ([]emmaSdk.KubernetesNodeGroupsInnerNodesInner, bool) {
+	existingNodes := make(map[int32]emmaSdk.KubernetesNodeGroupsInnerNodesInner)
+	for _, nodeGroup := range kubernetes.NodeGroups {
+		for _, node := range nodeGroup.Nodes {
+			existingNodes[*node.Id] = node
+		}
+	}
+
+	nodes := make([]emmaSdk.KubernetesNodeGroupsInnerNodesInner, 0, len(workerNodes))
+	for _, workerNode := range workerNodes {
+		if workerNode.Id != nil {
+			node, exists := existingNodes[*workerNode.Id]
+			if !exists {
+				writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("Worker node %d not found", *workerNode.Id))
+				return nil, false
+			}
+			nodes = append(nodes, node)
+			continue
+		}
+
+		dataCenter := findDataCenter(workerNode.DataCenterId)
+		if dataCenter == nil {
+			writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("Data center %s not found", workerNode.DataCenterId))
+			return nil, false
+		}
+		id := s.state.nextId("node")
+		location := locations[*dataCenter.LocationId-1]
+		nodes = append(nodes, emmaSdk.KubernetesNodeGroupsInnerNodesInner{
+			Id:               emmaSdk.PtrInt32(id),
+			CreatedAt:        emmaSdk.PtrString(createdAt),
+			Name:             emmaSdk.PtrString(fmt.Sprintf("%s-%s-%d", *kubernetes.Name, workerNode.Name, id)),
+			Status:           emmaSdk.PtrString(statusCreating),
+			Provider:         &emmaSdk.KubernetesNodeGroupsInnerNodesInnerProvider{Id: dataCenter.ProviderId, Name: dataCenter.ProviderName},
+			Location:         &emmaSdk.KubernetesNodeGroupsInnerNodesInnerLocation{Id: location.Id, Name: location.Name, Continent: location.Continent, Region: location.Region, Latitude: location.Latitude, Longitude: location.Longitude},
+			DataCenter:       &emmaSdk.KubernetesNodeGroupsInnerNodesInnerDataCenter{Id: dataCenter.Id, Name: dataCenter.Name, ProviderId: dataCenter.ProviderId, ProviderName: dataCenter.ProviderName, LocationId: dataCenter.LocationId, LocationName: dataCenter.LocationName},
+			Os:               &emmaSdk.KubernetesNodeGroupsInnerNodesInnerOs{Id: operatingSystems[2].Id, Family: operatingSystems[2].Family, Architecture: operatingSystems[2].Architecture, Type: operatingSystems[2].Type, Version: operatingSystems[2].Version},
+			VCpu:             emmaSdk.PtrInt32(workerNode.VCpu),
+			VCpuType:         emmaSdk.PtrString(workerNode.VCpuType),
+			CloudNetworkType: emmaSdk.PtrString("multi-cloud"),
+			RamGb:            emmaSdk.PtrInt32(workerNode.RamGb),
+			Disks: []emmaSdk.KubernetesNodeGroupsInnerNodesInnerDisksInner{{
+				Id:         emmaSdk.PtrInt32(s.state.nextId("volume")),
+				SizeGb:     emmaSdk.PtrInt32(workerNode.VolumeGb),
+				TypeId:     emmaSdk.PtrInt32(volumeTypeId(workerNode.VolumeType)),
+				Type:       emmaSdk.PtrString(workerNode.VolumeType),
+				IsBootable: emmaSdk.PtrBool(true),
+			}},
+			Networks: s.newNetworks(id),
+			Cost: &emmaSdk.KubernetesNodeGroupsInnerNodesInnerCost{Unit: emmaSdk.PtrString("HOURS"), Currency: emmaSdk.PtrString("EUR"),
+				Price: emmaSdk.PtrFloat32(computePrice(workerNode.VCpu, workerNode.RamGb, workerNode.VolumeGb))},
+		})
+	}
+	return nodes, true
+}
+
+// newAutoscalingConfigs returns the autoscaling configurations of the request, the omitted limits are filled
+// with the defaults of the emma API.
+func newAutoscalingConfigs(requestConfigs []emmaSdk.KubernetesCreateAutoscalingConfigsInner) []emmaSdk.KubernetesAutoscalingConfigsInner {
+	configs := make([]emmaSdk.KubernetesAutoscalingConfigsInner, 0, len(requestConfigs))
+	for _, requestConfig := range requestConfigs {
+		config := emmaSdk.KubernetesAutoscalingConfigsInner{
+			GroupName:                          emmaSdk.PtrString(requestConfig.GroupName),
+			DataCenterId:                       emmaSdk.PtrString(requestConfig.DataCenterId),
+			MinimumNodes:                       requestConfig.MinimumNodes,
+			MaximumNodes:                       requestConfig.MaximumNodes,
+			TargetNodes:                        requestConfig.TargetNodes,
+			MinimumVCpus:                       requestConfig.MinimumVCpus,
+			MaximumVCpus:                       requestConfig.MaximumVCpus,
+			TargetVCpus:                        requestConfig.TargetVCpus,
+			NodeGroupPriceLimit:                requestConfig.NodeGroupPriceLimit,
+			UseOnDemandInstancesInsteadOfSpots: emmaSdk.PtrBool(requestConfig.UseOnDemandInstancesInsteadOfSpots),
+			SpotPercent:                        requestConfig.SpotPercent,
+			SpotMarkup:                         requestConfig.SpotMarkup,
+			ConfigurationPriorities:            make([]emmaSdk.KubernetesAutoscalingConfigsInnerConfigurationPrioritiesInner, 0, len(requestConfig.ConfigurationPriorities)),
+		}
+		if config.SpotPercent == nil {
+			config.SpotPercent = emmaSdk.PtrInt32(0)
+		}
+		if config.SpotMarkup == nil {
+			config.SpotMarkup = emmaSdk.PtrFloat32(0.2)
+		}
+		for _, priority := range requestConfig.ConfigurationPriorities {
+			config.ConfigurationPriorities = append(config.ConfigurationPriorities, emmaSdk.KubernetesAutoscalingConfigsInnerConfigurationPrioritiesInner{
+				VCpuType:   priority.VCpuType,
+				VCpu:       priority.VCpu,
+				RamGb:      priority.RamGb,
+				VolumeGb:   priority.VolumeGb,
+				VolumeType: priority.VolumeType,
+				Priority:   priority.Priority,
+			})
+		}
+		configs = append(configs, config)
+	}
+	return configs
+}
+
+// kubernetesCost returns the sum of the node prices.
+func kubernetesCost(nodes []emmaSdk.KubernetesNodeGroupsInnerNodesInner) *emmaSdk.KubernetesCost {
+	var price float32
+	for _, node := range nodes {
+		price += *node.Cost.Price
+	}
+	return &emmaSdk.KubernetesCost{Unit: emmaSdk.PtrString("HOURS"), Currency: emmaSdk.PtrString("EUR"), Price: emmaSdk.PtrFloat32(price)}
+}
+
+func (s *Server) getKubernetesClusters(w http.ResponseWriter, req *http.Request) error {
+	writeJSON(w, http.StatusOK, sortedValues(s.state.Kubernetes, settleKubernetes))
+	return nil
+}
+
+func (s *Server) createKubernetes(w http.ResponseWriter, req *http.Request) error {
+	var request emmaSdk.KubernetesCreate
+	if !readJSON(w, req, &request) {
+		return nil
+	}
+	if request.Name == "" || len(request.WorkerNodes) == 0 {
+		writeError(w, http.StatusBadRequest, "name and workerNodes are required")
+		return nil
+	}
+	if findDataCenter(request.DeploymentLocation) == nil {
+		writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("Deployment location %s not found", request.DeploymentLocation))
+		return nil
+	}
+
+	kubernetes := &emmaSdk.Kubernetes{
+		Name:               emmaSdk.PtrString(request.Name),
+		CreatedAt:          emmaSdk.PtrString(createdAt),
+		Version:            emmaSdk.PtrString("1.30"),
+		DeploymentLocation: emmaSdk.PtrString(request.DeploymentLocation),
+		Status:             emmaSdk.PtrString(statusCreating),
+		ControlPlaneStatus: emmaSdk.PtrString(statusCreating),
+		AutoscalingConfigs: newAutoscalingConfigs(request.AutoscalingConfigs),
+	}
+	workerNodes := make([]workerNode, 0, len(request.WorkerNodes))
+	for _, node := range request.WorkerNodes {
+		workerNodes = append(workerNodes, workerNode{Name: node.Name, DataCenterId: node.DataCenterId, VCpuType: node.VCpuType,
+			VCpu: node.VCpu, RamGb: node.RamGb, VolumeType: node.VolumeType, VolumeGb: node.VolumeGb})
+	}
+	nodes, ok := s.newNodes(w, kubernetes, workerNodes)
+	if !ok {
+		return nil
+	}
+
+	id := s.state.nextId("kubernetes")
+	kubernetes.Id = emmaSdk.PtrInt32(id)
+	kubernetes.NodeGroups = []emmaSdk.KubernetesNodeGroupsInner{{Name: emmaSdk.PtrString("default"), Nodes: nodes}}
+	kubernetes.Cost = kubernetesCost(nodes)
+	s.state.Kubernetes[id] = kubernetes
+	writeJSON(w, http.StatusCreated, kubernetes)
+	return nil
+}
+
+func (s *Server) getKubernetes(w http.ResponseWriter, req *http.Request) error {
+	kubernetes := s.findKubernetes(w, req)
+	if kubernetes == nil {
+		return nil
+	}
+	settleKubernetes(kubernetes)
+	writeJSON(w, http.StatusOK, kubernetes)
+	return nil
+}
+
+func (s *Server) updateKubernetes(w http.ResponseWriter, req *http.Request) error {
+	kubernetes := s.findKubernetes(w, req)
+	if kubernetes == nil {
+		return nil
+	}
+	var request emmaSdk.KubernetesUpdate
+	if !readJSON(w, req, &request) {
+		return nil
+	}
+	if len(request.WorkerNodes) == 0 {
+		writeError(w, http.StatusBadRequest, "workerNodes are required")
+		return nil
+	}
+
+	workerNodes := make([]workerNode, 0, len(request.WorkerNodes))
+	for _, node := range request.WorkerNodes {
+		workerNodes = append(workerNodes, workerNode{Id: node.Id, Name: node.Name, DataCenterId: node.DataCenterId,
+			VCpuType: node.VCpuType, VCpu: node.VCpu, RamGb: node.RamGb, VolumeType: node.VolumeType, VolumeGb: node.VolumeGb})
+	}
+	// the nodes that aren't in the request are deleted
+	nodes, ok := s.newNodes(w, kubernetes, workerNodes)
+	if !ok {
+		return nil
+	}
+
+	kubernetes.NodeGroups = []emmaSdk.KubernetesNodeGroupsInner{{Name: emmaSdk.PtrString("default"), Nodes: nodes}}
+	kubernetes.AutoscalingConfigs = newAutoscalingConfigs(request.AutoscalingConfigs)
+	kubernetes.Cost = kubernetesCost(nodes)
+	kubernetes.Status = emmaSdk.PtrString(statusUpdating)
+	kubernetes.ModifiedAt = emmaSdk.PtrString(createdAt)
+	writeJSON(w, http.StatusOK, kubernetes)
+	return nil
+}
+
+func (s *Server) deleteKubernetes(w http.ResponseWriter, req *http.Request) error {
+	kubernetes := s.findKubernetes(w, req)
+	if kubernetes == nil {
+		return nil
+	}
+	delete(s.state.Kubernetes, *kubernetes.Id)
+	writeJSON(w, http.StatusOK, kubernetes)
+	return nil
+}
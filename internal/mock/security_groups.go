@@ -0,0 +1,204 @@
+package mock
+
+import (
+	"fmt"
+	emmaSdk "github.com/emma-community/emma-go-sdk"
+	"net/http"
+	"strings"
+)
+
+// defaultSecurityGroupRules returns the rules that are added to every security group and can't be changed.
+func defaultSecurityGroupRules() []emmaSdk.SecurityGroupRule {
+	return []emmaSdk.SecurityGroupRule{
+		newSecurityGroupRule("INBOUND", "TCP", "22", "0.0.0.0/0", false),
+		newSecurityGroupRule("OUTBOUND", "all", "all", "0.0.0.0/0", false),
+	}
+}
+
+func newSecurityGroupRule(direction string, protocol string, ports string, ipRange string, isMutable bool) emmaSdk.SecurityGroupRule {
+	ruleType := "DEFAULT"
+	if isMutable {
+		ruleType = "CUSTOM"
+	}
+	return emmaSdk.SecurityGroupRule{Type: emmaSdk.PtrString(ruleType), Policy: emmaSdk.PtrString("ACCEPT"),
+		Direction: emmaSdk.PtrString(direction), Protocol: emmaSdk.PtrString(protocol), Ports: emmaSdk.PtrString(ports),
+		IpRange: emmaSdk.PtrString(ipRange), IsMutable: emmaSdk.PtrBool(isMutable)}
+}
+
+// securityGroupRules returns the default rules and the requested rules, the requested rules that are equal to
+// a default rule aren't duplicated.
+func securityGroupRules(requestRules []emmaSdk.SecurityGroupRuleRequest) []emmaSdk.SecurityGroupRule {
+	rules := defaultSecurityGroupRules()
+	for _, requestRule := range requestRules {
+		rule := newSecurityGroupRule(requestRule.Direction, requestRule.Protocol, requestRule.Ports, requestRule.IpRange, true)
+		duplicated := false
+		for _, defaultRule := range rules[:len(defaultSecurityGroupRules())] {
+			if strings.EqualFold(*defaultRule.Direction, *rule.Direction) && strings.EqualFold(*defaultRule.Protocol, *rule.Protocol) &&
+				strings.EqualFold(*defaultRule.Ports, *rule.Ports) && *defaultRule.IpRange == *rule.IpRange {
+				duplicated = true
+			}
+		}
+		if !duplicated {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// settleSecurityGroup changes the transitional statuses of the security group to the final statuses.
+func settleSecurityGroup(securityGroup *emmaSdk.SecurityGroup) {
+	securityGroup.SynchronizationStatus = emmaSdk.PtrString(statusSynchronized)
+	securityGroup.RecomposingStatus = emmaSdk.PtrString(statusRecomposed)
+}
+
+// synchronize marks the security group as changed, it is synchronized on the next read.
+func synchronize(securityGroup *emmaSdk.SecurityGroup) {
+	securityGroup.SynchronizationStatus = emmaSdk.PtrString(statusSynchronizing)
+	securityGroup.RecomposingStatus = emmaSdk.PtrString(statusRecomposing)
+	securityGroup.ModifiedAt = emmaSdk.PtrString(createdAt)
+}
+
+func (s *Server) defaultSecurityGroup() *emmaSdk.SecurityGroup {
+	for _, securityGroup := range s.state.SecurityGroups {
+		if *securityGroup.Name == defaultSecurityGroup {
+			return securityGroup
+		}
+	}
+	return nil
+}
+
+// findSecurityGroup returns the security group of the path parameter, the not found error is written if it doesn't exist.
+func (s *Server) findSecurityGroup(w http.ResponseWriter, req *http.Request) *emmaSdk.SecurityGroup {
+	id, ok := pathId(w, req, "securityGroupId")
+	if !ok {
+		return nil
+	}
+	securityGroup := s.state.SecurityGroups[id]
+	if securityGroup == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Security group %d not found", id))
+	}
+	return securityGroup
+}
+
+// securityGroupInstances returns the virtual machines and spot instances of the security group.
+func (s *Server) securityGroupInstances(securityGroupId int32) []*emmaSdk.Vm {
+	instances := make([]*emmaSdk.Vm, 0)
+	for _, instance := range append(sortedValues(s.state.Vms, settle), sortedValues(s.state.Spots, settle)...) {
+		if instance.SecurityGroup != nil && *instance.SecurityGroup.Id == securityGroupId {
+			instances = append(instances, instance)
+		}
+	}
+	return instances
+}
+
+func (s *Server) getSecurityGroups(w http.ResponseWriter, req *http.Request) error {
+	writeJSON(w, http.StatusOK, sortedValues(s.state.SecurityGroups, settleSecurityGroup))
+	return nil
+}
+
+func (s *Server) createSecurityGroup(w http.ResponseWriter, req *http.Request) error {
+	var request emmaSdk.SecurityGroupRequest
+	if !readJSON(w, req, &request) {
+		return nil
+	}
+	for _, securityGroup := range s.state.SecurityGroups {
+		if *securityGroup.Name == request.Name {
+			writeError(w, http.StatusConflict, fmt.Sprintf("Security group %s already exists", request.Name))
+			return nil
+		}
+	}
+	id := s.state.nextId("securityGroup")
+	securityGroup := &emmaSdk.SecurityGroup{
+		Id:        emmaSdk.PtrInt32(id),
+		Name:      emmaSdk.PtrString(request.Name),
+		CreatedAt: emmaSdk.PtrString(createdAt),
+		Rules:     securityGroupRules(request.Rules),
+	}
+	synchronize(securityGroup)
+	s.state.SecurityGroups[id] = securityGroup
+	writeJSON(w, http.StatusCreated, securityGroup)
+	return nil
+}
+
+func (s *Server) getSecurityGroup(w http.ResponseWriter, req *http.Request) error {
+	securityGroup := s.findSecurityGroup(w, req)
+	if securityGroup == nil {
+		return nil
+	}
+	settleSecurityGroup(securityGroup)
+	writeJSON(w, http.StatusOK, securityGroup)
+	return nil
+}
+
+func (s *Server) updateSecurityGroup(w http.ResponseWriter, req *http.Request) error {
+	securityGroup := s.findSecurityGroup(w, req)
+	if securityGroup == nil {
+		return nil
+	}
+	var request emmaSdk.SecurityGroupRequest
+	if !readJSON(w, req, &request) {
+		return nil
+	}
+	securityGroup.Name = emmaSdk.PtrString(request.Name)
+	securityGroup.Rules = securityGroupRules(request.Rules)
+	synchronize(securityGroup)
+	writeJSON(w, http.StatusOK, securityGroup)
+	return nil
+}
+
+func (s *Server) deleteSecurityGroup(w http.ResponseWriter, req *http.Request) error {
+	securityGroup := s.findSecurityGroup(w, req)
+	if securityGroup == nil {
+		return nil
+	}
+	if *securityGroup.Name == defaultSecurityGroup {
+		writeError(w, http.StatusUnprocessableEntity, "The default security group can't be deleted")
+		return nil
+	}
+	if len(s.securityGroupInstances(*securityGroup.Id)) > 0 {
+		writeError(w, http.StatusConflict, "Security group has instances, move them to another security group first")
+		return nil
+	}
+	delete(s.state.SecurityGroups, *securityGroup.Id)
+	writeJSON(w, http.StatusOK, securityGroup)
+	return nil
+}
+
+func (s *Server) getSecurityGroupInstances(w http.ResponseWriter, req *http.Request) error {
+	securityGroup := s.findSecurityGroup(w, req)
+	if securityGroup == nil {
+		return nil
+	}
+	writeJSON(w, http.StatusOK, s.securityGroupInstances(*securityGroup.Id))
+	return nil
+}
+
+func (s *Server) addSecurityGroupInstance(w http.ResponseWriter, req *http.Request) error {
+	securityGroup := s.findSecurityGroup(w, req)
+	if securityGroup == nil {
+		return nil
+	}
+	var request emmaSdk.SecurityGroupInstanceAdd
+	if !readJSON(w, req, &request) {
+		return nil
+	}
+	if request.InstanceId == nil {
+		writeError(w, http.StatusBadRequest, "instanceId is required")
+		return nil
+	}
+	instance := s.state.Vms[*request.InstanceId]
+	if instance == nil {
+		instance = s.state.Spots[*request.InstanceId]
+	}
+	if instance == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Instance %d not found", *request.InstanceId))
+		return nil
+	}
+	if previous := s.state.SecurityGroups[*instance.SecurityGroup.Id]; previous != nil {
+		synchronize(previous)
+	}
+	instance.SecurityGroup = &emmaSdk.VmSecurityGroup{Id: securityGroup.Id, Name: securityGroup.Name}
+	synchronize(securityGroup)
+	writeJSON(w, http.StatusOK, instance)
+	return nil
+}
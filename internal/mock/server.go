@@ -0,0 +1,269 @@
+// Package mock implements an in-process fake of the emma API. It serves the endpoints used by the provider with
+// deterministic ids and state transitions, so Terraform configurations can be planned and applied without
+// credentials and network.
+package mock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	emmaSdk "github.com/emma-community/emma-go-sdk"
+	"github.com/emma-community/terraform-provider-emma/tools"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// AccessToken is the access token issued by the fake emma API for any credentials.
+const AccessToken = "mock-access-token"
+
+// The first ids of the resources created in the fake emma API.
+const (
+	firstVmId            = 1001
+	firstSecurityGroupId = 2001
+	firstSshKeyId        = 3001
+	firstKubernetesId    = 4001
+	firstVolumeId        = 5001
+	firstNetworkId       = 6001
+	firstNodeId          = 7001
+)
+
+// The states of the resources, the transitional state changes to the final state on the next read.
+const (
+	statusBusy           = "BUSY"
+	statusRunning        = "RUNNING"
+	statusStopped        = "STOPPED"
+	statusFailed         = "FAILED"
+	statusCreating       = "CREATING"
+	statusUpdating       = "UPDATING"
+	statusSynchronizing  = "SYNCHRONIZING"
+	statusSynchronized   = "SYNCHRONIZED"
+	statusRecomposing    = "RECOMPOSING"
+	statusRecomposed     = "RECOMPOSED"
+	defaultSecurityGroup = "default"
+)
+
+// state is the data of the fake emma API, it is stored in the state file as JSON.
+type state struct {
+	NextIds        map[string]int32                 `json:"nextIds"`
+	Vms            map[int32]*emmaSdk.Vm            `json:"vms"`
+	Spots          map[int32]*emmaSdk.Vm            `json:"spots"`
+	SecurityGroups map[int32]*emmaSdk.SecurityGroup `json:"securityGroups"`
+	SshKeys        map[int32]*emmaSdk.SshKey        `json:"sshKeys"`
+	Kubernetes     map[int32]*emmaSdk.Kubernetes    `json:"kubernetes"`
+}
+
+// Server is the fake emma API.
+type Server struct {
+	mutex     sync.Mutex
+	mux       *http.ServeMux
+	statePath string
+	state     state
+}
+
+// NewServer returns the fake emma API. If the state path isn't empty, the data is loaded from the file and
+// stored in it after every change, so the resources are kept between the runs of Terraform.
+func NewServer(statePath string) (*Server, error) {
+	s := &Server{mux: http.NewServeMux(), statePath: statePath, state: newState()}
+	if statePath != "" {
+		content, err := os.ReadFile(statePath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if err == nil {
+			if err := json.Unmarshal(content, &s.state); err != nil {
+				return nil, fmt.Errorf("invalid mock state file %s: %w", statePath, err)
+			}
+		}
+	}
+
+	s.mux.HandleFunc("POST /v1/issue-token", s.issueToken)
+	s.mux.HandleFunc("POST /v1/refresh-token", s.issueToken)
+
+	s.handle("GET /v1/data-centers", s.getDataCenters)
+	s.handle("GET /v1/data-centers/{dataCenterId}", s.getDataCenter)
+	s.handle("GET /v1/locations", s.getLocations)
+	s.handle("GET /v1/locations/{locationId}", s.getLocation)
+	s.handle("GET /v1/operating-systems", s.getOperatingSystems)
+	s.handle("GET /v1/operating-systems/{operatingSystemId}", s.getOperatingSystem)
+	s.handle("GET /v1/providers", s.getProviders)
+	s.handle("GET /v1/providers/{providerId}", s.getProvider)
+
+	s.handle("GET /v1/vms", s.getVms)
+	s.handle("POST /v1/vms", s.createVm)
+	s.handle("GET /v1/vms/{vmId}", s.getVm)
+	s.handle("DELETE /v1/vms/{vmId}", s.deleteVm)
+	s.handle("POST /v1/vms/{vmId}/actions", s.vmActions)
+	s.handle("GET /v1/spot-instances", s.getSpots)
+	s.handle("POST /v1/spot-instances", s.createSpot)
+	s.handle("GET /v1/spot-instances/{spotInstanceId}", s.getSpot)
+	s.handle("DELETE /v1/spot-instances/{spotInstanceId}", s.deleteSpot)
+	s.handle("POST /v1/spot-instances/{spotInstanceId}/actions", s.spotActions)
+	s.handle("POST /v1/volumes/{volumeId}/actions", s.volumeActions)
+
+	s.handle("GET /v1/security-groups", s.getSecurityGroups)
+	s.handle("POST /v1/security-groups", s.createSecurityGroup)
+	s.handle("GET /v1/security-groups/{securityGroupId}", s.getSecurityGroup)
+	s.handle("PUT /v1/security-groups/{securityGroupId}", s.updateSecurityGroup)
+	s.handle("DELETE /v1/security-groups/{securityGroupId}", s.deleteSecurityGroup)
+	s.handle("GET /v1/security-groups/{securityGroupId}/instances", s.getSecurityGroupInstances)
+	s.handle("POST /v1/security-groups/{securityGroupId}/instances", s.addSecurityGroupInstance)
+
+	s.handle("GET /v1/ssh-keys", s.getSshKeys)
+	s.handle("POST /v1/ssh-keys", s.createSshKey)
+	s.handle("GET /v1/ssh-keys/{sshKeyId}", s.getSshKey)
+	s.handle("PUT /v1/ssh-keys/{sshKeyId}", s.updateSshKey)
+	s.handle("DELETE /v1/ssh-keys/{sshKeyId}", s.deleteSshKey)
+
+	s.handle("GET /v1/kubernetes", s.getKubernetesClusters)
+	s.handle("POST /v1/kubernetes", s.createKubernetes)
+	s.handle("GET /v1/kubernetes/{kubernetesId}", s.getKubernetes)
+	s.handle("PUT /v1/kubernetes/{kubernetesId}", s.updateKubernetes)
+	s.handle("DELETE /v1/kubernetes/{kubernetesId}", s.deleteKubernetes)
+	return s, nil
+}
+
+func newState() state {
+	s := state{
+		NextIds: map[string]int32{
+			"vm":            firstVmId,
+			"securityGroup": firstSecurityGroupId,
+			"sshKey":        firstSshKeyId,
+			"kubernetes":    firstKubernetesId,
+			"volume":        firstVolumeId,
+			"network":       firstNetworkId,
+			"node":          firstNodeId,
+		},
+		Vms:            make(map[int32]*emmaSdk.Vm),
+		Spots:          make(map[int32]*emmaSdk.Vm),
+		SecurityGroups: make(map[int32]*emmaSdk.SecurityGroup),
+		SshKeys:        make(map[int32]*emmaSdk.SshKey),
+		Kubernetes:     make(map[int32]*emmaSdk.Kubernetes),
+	}
+	// every project has the default security group, the instances are added to it if no security group is set
+	id := s.nextId("securityGroup")
+	s.SecurityGroups[id] = &emmaSdk.SecurityGroup{
+		Id:                    emmaSdk.PtrInt32(id),
+		Name:                  emmaSdk.PtrString(defaultSecurityGroup),
+		SynchronizationStatus: emmaSdk.PtrString(statusSynchronized),
+		RecomposingStatus:     emmaSdk.PtrString(statusRecomposed),
+		Rules:                 defaultSecurityGroupRules(),
+	}
+	return s
+}
+
+// nextId returns the next id of the kind of resources.
+func (s *state) nextId(kind string) int32 {
+	id := s.NextIds[kind]
+	s.NextIds[kind] = id + 1
+	return id
+}
+
+// ServeHTTP serves the request of the emma API, the base path of the host before /v1/ is ignored.
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if index := strings.Index(req.URL.Path, "/v1/"); index > 0 {
+		req = req.Clone(req.Context())
+		req.URL.Path = req.URL.Path[index:]
+		req.URL.RawPath = ""
+	}
+	s.mux.ServeHTTP(w, req)
+}
+
+// handle registers the handler of the endpoint that requires the access token. The handlers are called one at
+// a time, and the state is stored after every request.
+func (s *Server) handle(pattern string, handler func(w http.ResponseWriter, req *http.Request) error) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer "+AccessToken {
+			writeError(w, http.StatusUnauthorized, "Invalid access token")
+			return
+		}
+
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		if err := handler(w, req); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		// the reads change the transitional states too
+		if err := s.save(); err != nil {
+			tflog.Warn(req.Context(), "Unable to store the mock state: "+err.Error())
+		}
+	})
+}
+
+// save stores the state in the state file.
+func (s *Server) save() error {
+	if s.statePath == "" {
+		return nil
+	}
+	content, err := json.Marshal(s.state)
+	if err != nil {
+		return err
+	}
+	return tools.WriteSensitiveFile(s.statePath, string(content))
+}
+
+func (s *Server) issueToken(w http.ResponseWriter, req *http.Request) {
+	var credentials emmaSdk.Credentials
+	if err := json.NewDecoder(req.Body).Decode(&credentials); err != nil && req.URL.Path == "/v1/issue-token" {
+		writeError(w, http.StatusBadRequest, "Invalid credentials")
+		return
+	}
+	writeJSON(w, http.StatusOK, emmaSdk.Token{
+		AccessToken:      emmaSdk.PtrString(AccessToken),
+		RefreshToken:     emmaSdk.PtrString("mock-refresh-token"),
+		ExpiresIn:        emmaSdk.PtrInt32(3600),
+		RefreshExpiresIn: emmaSdk.PtrInt32(86400),
+		TokenType:        emmaSdk.PtrString("Bearer"),
+	})
+}
+
+// pathId returns the id of the path parameter, the not found error is written if it isn't a number.
+func pathId(w http.ResponseWriter, req *http.Request, name string) (int32, bool) {
+	id, err := strconv.ParseInt(req.PathValue(name), 10, 32)
+	if err != nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Resource %s not found", req.PathValue(name)))
+		return 0, false
+	}
+	return int32(id), true
+}
+
+// readJSON decodes the request body, the bad request error is written if it is invalid.
+func readJSON(w http.ResponseWriter, req *http.Request, value any) bool {
+	if err := json.NewDecoder(req.Body).Decode(value); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// sortedValues returns the resources ordered by the id, the function is applied to every resource before.
+func sortedValues[T any](resources map[int32]*T, apply func(resource *T)) []*T {
+	ids := make([]int32, 0, len(resources))
+	for id := range resources {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	result := make([]*T, 0, len(ids))
+	for _, id := range ids {
+		if apply != nil {
+			apply(resources[id])
+		}
+		result = append(result, resources[id])
+	}
+	return result
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, tools.ErrorResponse{Code: strconv.Itoa(status), Message: message})
+}
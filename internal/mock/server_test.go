@@ -0,0 +1,235 @@
+package mock
+
+import (
+	"context"
+	emmaSdk "github.com/emma-community/emma-go-sdk"
+	"github.com/emma-community/terraform-provider-emma/tools"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"path/filepath"
+	"testing"
+)
+
+// newTestClient returns the emma API client that sends the requests to the server and the authorized context.
+func newTestClient(t *testing.T, server *Server) (*emmaSdk.APIClient, context.Context) {
+	configuration := emmaSdk.NewConfiguration()
+	configuration.HTTPClient = &http.Client{Transport: &Transport{Handler: server}}
+	apiClient := emmaSdk.NewAPIClient(configuration)
+	token, _, err := apiClient.AuthenticationAPI.IssueToken(context.Background()).
+		Credentials(emmaSdk.Credentials{ClientId: "id", ClientSecret: "secret"}).Execute()
+	require.NoError(t, err)
+	return apiClient, context.WithValue(context.Background(), emmaSdk.ContextAccessToken, *token.AccessToken)
+}
+
+func TestServerRequiresAccessToken(t *testing.T) {
+	server, err := NewServer("")
+	require.NoError(t, err)
+	apiClient, _ := newTestClient(t, server)
+
+	_, response, err := apiClient.DataCentersAPI.GetDataCenters(context.Background()).Execute()
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+}
+
+func TestServerCatalog(t *testing.T) {
+	server, err := NewServer("")
+	require.NoError(t, err)
+	apiClient, auth := newTestClient(t, server)
+
+	dataCenters, _, err := apiClient.DataCentersAPI.GetDataCenters(auth).ProviderName("DigitalOcean").Execute()
+	require.NoError(t, err)
+	assert.Len(t, dataCenters, 2)
+
+	operatingSystems, _, err := apiClient.OperatingSystemsAPI.GetOperatingSystems(auth).Type_("Ubuntu").Version("22.04").Execute()
+	require.NoError(t, err)
+	require.Len(t, operatingSystems, 1)
+	assert.Equal(t, int32(2), *operatingSystems[0].Id)
+
+	_, response, err := apiClient.DataCentersAPI.GetDataCenter(auth, "unknown").Execute()
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
+
+func TestServerVm(t *testing.T) {
+	server, err := NewServer("")
+	require.NoError(t, err)
+	apiClient, auth := newTestClient(t, server)
+
+	vm, _, err := apiClient.VirtualMachinesAPI.VmCreate(auth).VmCreate(emmaSdk.VmCreate{
+		Name: "vm", DataCenterId: "digitalocean-ams3", OsId: 2, CloudNetworkType: "multi-cloud", VCpuType: "shared",
+		VCpu: 2, RamGb: 4, VolumeType: "ssd", VolumeGb: 16, UserPassword: emmaSdk.PtrString("password"),
+	}).Execute()
+	require.NoError(t, err)
+	assert.Equal(t, int32(firstVmId), *vm.Id)
+	assert.Equal(t, statusBusy, *vm.Status)
+	assert.Equal(t, defaultSecurityGroup, *vm.SecurityGroup.Name)
+
+	vm, _, err = apiClient.VirtualMachinesAPI.GetVm(auth, *vm.Id).Execute()
+	require.NoError(t, err)
+	assert.Equal(t, statusRunning, *vm.Status)
+
+	editHardware := emmaSdk.VmEditHardware{Action: "edithardware", VCpu: 4, RamGb: 8, VolumeGb: 8}
+	_, response, err := apiClient.VirtualMachinesAPI.VmActions(auth, *vm.Id).
+		VmActionsRequest(emmaSdk.VmActionsRequest{VmEditHardware: &editHardware}).Execute()
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode)
+
+	editHardware.VolumeGb = 32
+	vm, _, err = apiClient.VirtualMachinesAPI.VmActions(auth, *vm.Id).
+		VmActionsRequest(emmaSdk.VmActionsRequest{VmEditHardware: &editHardware}).Execute()
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), *vm.VCpu)
+	assert.Equal(t, int32(32), *vm.Disks[0].SizeGb)
+
+	_, response, err = apiClient.VirtualMachinesAPI.VmActions(auth, *vm.Id).
+		VmActionsRequest(emmaSdk.VmActionsRequest{VmEditHardware: &editHardware}).Execute()
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+
+	_, _, err = apiClient.VirtualMachinesAPI.GetVm(auth, *vm.Id).Execute()
+	require.NoError(t, err)
+	editHardware.VCpuType = emmaSdk.PtrString("standard")
+	_, response, err = apiClient.VirtualMachinesAPI.VmActions(auth, *vm.Id).
+		VmActionsRequest(emmaSdk.VmActionsRequest{VmEditHardware: &editHardware}).Execute()
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode)
+
+	_, _, err = apiClient.VirtualMachinesAPI.VmDelete(auth, *vm.Id).Execute()
+	require.NoError(t, err)
+	_, response, err = apiClient.VirtualMachinesAPI.GetVm(auth, *vm.Id).Execute()
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
+
+func TestServerSpotInterruption(t *testing.T) {
+	server, err := NewServer("")
+	require.NoError(t, err)
+	apiClient, auth := newTestClient(t, server)
+
+	spotCreate := emmaSdk.SpotCreate{
+		Name: "spot", DataCenterId: "aws-eu-central-1", OsId: 3, CloudNetworkType: "multi-cloud", VCpuType: "shared",
+		VCpu: 2, RamGb: 4, VolumeType: "ssd", VolumeGb: 16, UserPassword: emmaSdk.PtrString("password"),
+	}
+	_, response, err := apiClient.SpotInstancesAPI.SpotCreate(auth).SpotCreate(spotCreate).Execute()
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode)
+
+	spotCreate.Price = 0.05
+	spot, _, err := apiClient.SpotInstancesAPI.SpotCreate(auth).SpotCreate(spotCreate).Execute()
+	require.NoError(t, err)
+
+	assert.True(t, server.InterruptSpot(*spot.Id))
+	assert.False(t, server.InterruptSpot(*spot.Id+1))
+	spot, _, err = apiClient.SpotInstancesAPI.GetSpot(auth, *spot.Id).Execute()
+	require.NoError(t, err)
+	assert.Equal(t, statusFailed, *spot.Status)
+}
+
+func TestServerSshKey(t *testing.T) {
+	server, err := NewServer("")
+	require.NoError(t, err)
+	apiClient, auth := newTestClient(t, server)
+
+	_, authorizedKey, err := tools.GenerateSshKeyPair("ED25519")
+	require.NoError(t, err)
+	imported, _, err := apiClient.SSHKeysAPI.SshKeysCreateImport(auth).SshKeysCreateImportRequest(emmaSdk.SshKeysCreateImportRequest{
+		SshKeyImport: &emmaSdk.SshKeyImport{Name: "imported", Key: authorizedKey},
+	}).Execute()
+	require.NoError(t, err)
+	// the response of the imported key has the null private key, so it is decoded as the generated key
+	require.NotNil(t, imported.SshKeyGenerated)
+	assert.Equal(t, "ED25519", *imported.SshKeyGenerated.KeyType)
+	assert.Nil(t, imported.SshKeyGenerated.PrivateKey)
+
+	generated, _, err := apiClient.SSHKeysAPI.SshKeysCreateImport(auth).SshKeysCreateImportRequest(emmaSdk.SshKeysCreateImportRequest{
+		SshKeyCreate: &emmaSdk.SshKeyCreate{Name: "generated", KeyType: "ED25519"},
+	}).Execute()
+	require.NoError(t, err)
+	require.NotNil(t, generated.SshKeyGenerated)
+	assert.NotEmpty(t, *generated.SshKeyGenerated.PrivateKey)
+
+	sshKeys, _, err := apiClient.SSHKeysAPI.SshKeys(auth).Execute()
+	require.NoError(t, err)
+	assert.Len(t, sshKeys, 2)
+}
+
+func TestServerSecurityGroup(t *testing.T) {
+	server, err := NewServer("")
+	require.NoError(t, err)
+	apiClient, auth := newTestClient(t, server)
+
+	securityGroup, _, err := apiClient.SecurityGroupsAPI.SecurityGroupCreate(auth).SecurityGroupRequest(emmaSdk.SecurityGroupRequest{
+		Name: "web",
+		Rules: []emmaSdk.SecurityGroupRuleRequest{
+			{Direction: "INBOUND", Protocol: "TCP", Ports: "22", IpRange: "0.0.0.0/0"},
+			{Direction: "INBOUND", Protocol: "TCP", Ports: "443", IpRange: "0.0.0.0/0"},
+		},
+	}).Execute()
+	require.NoError(t, err)
+	assert.Len(t, securityGroup.Rules, 3)
+	assert.Equal(t, statusSynchronizing, *securityGroup.SynchronizationStatus)
+
+	securityGroup, _, err = apiClient.SecurityGroupsAPI.GetSecurityGroup(auth, *securityGroup.Id).Execute()
+	require.NoError(t, err)
+	assert.Equal(t, statusSynchronized, *securityGroup.SynchronizationStatus)
+
+	_, response, err := apiClient.SecurityGroupsAPI.SecurityGroupDelete(auth, firstSecurityGroupId).Execute()
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode)
+}
+
+func TestServerKubernetes(t *testing.T) {
+	server, err := NewServer("")
+	require.NoError(t, err)
+	apiClient, auth := newTestClient(t, server)
+
+	kubernetes, _, err := apiClient.KubernetesClustersAPI.CreateKubernetesCluster(auth).KubernetesCreate(emmaSdk.KubernetesCreate{
+		Name: "cluster", DeploymentLocation: "aws-eu-north-1",
+		WorkerNodes: []emmaSdk.KubernetesCreateWorkerNodesInner{
+			{Name: "first", DataCenterId: "aws-eu-north-1", VCpuType: "shared", VCpu: 2, RamGb: 4, VolumeType: "ssd", VolumeGb: 16},
+			{Name: "second", DataCenterId: "aws-eu-north-1", VCpuType: "shared", VCpu: 2, RamGb: 4, VolumeType: "ssd", VolumeGb: 16},
+		},
+	}).Execute()
+	require.NoError(t, err)
+	require.Len(t, kubernetes.NodeGroups[0].Nodes, 2)
+	firstNode := kubernetes.NodeGroups[0].Nodes[0]
+
+	_, _, err = apiClient.KubernetesClustersAPI.EditKubernetesCluster(auth, *kubernetes.Id).KubernetesUpdate(emmaSdk.KubernetesUpdate{
+		WorkerNodes: []emmaSdk.KubernetesUpdateWorkerNodesInner{
+			{Id: firstNode.Id, Name: "first", DataCenterId: "aws-eu-north-1", VCpuType: "shared", VCpu: 2, RamGb: 4, VolumeType: "ssd", VolumeGb: 16},
+			{Name: "third", DataCenterId: "aws-eu-central-1", VCpuType: "shared", VCpu: 4, RamGb: 8, VolumeType: "ssd", VolumeGb: 32},
+		},
+	}).Execute()
+	require.NoError(t, err)
+
+	kubernetes, _, err = apiClient.KubernetesClustersAPI.GetKubernetesCluster(auth, *kubernetes.Id).Execute()
+	require.NoError(t, err)
+	assert.Equal(t, statusRunning, *kubernetes.Status)
+	require.Len(t, kubernetes.NodeGroups[0].Nodes, 2)
+	assert.Equal(t, *firstNode.Id, *kubernetes.NodeGroups[0].Nodes[0].Id)
+	assert.Equal(t, int32(firstNodeId+2), *kubernetes.NodeGroups[0].Nodes[1].Id)
+}
+
+func TestServerState(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	server, err := NewServer(statePath)
+	require.NoError(t, err)
+	apiClient, auth := newTestClient(t, server)
+
+	securityGroup, _, err := apiClient.SecurityGroupsAPI.SecurityGroupCreate(auth).
+		SecurityGroupRequest(emmaSdk.SecurityGroupRequest{Name: "kept"}).Execute()
+	require.NoError(t, err)
+
+	server, err = NewServer(statePath)
+	require.NoError(t, err)
+	apiClient, auth = newTestClient(t, server)
+	kept, _, err := apiClient.SecurityGroupsAPI.GetSecurityGroup(auth, *securityGroup.Id).Execute()
+	require.NoError(t, err)
+	assert.Equal(t, "kept", *kept.Name)
+
+	created, _, err := apiClient.SecurityGroupsAPI.SecurityGroupCreate(auth).
+		SecurityGroupRequest(emmaSdk.SecurityGroupRequest{Name: "next"}).Execute()
+	require.NoError(t, err)
+	assert.Equal(t, *securityGroup.Id+1, *created.Id)
+}
@@ -0,0 +1,121 @@
+package mock
+
+import (
+	"fmt"
+	emmaSdk "github.com/emma-community/emma-go-sdk"
+	emma "github.com/emma-community/terraform-provider-emma/internal/emma/validation"
+	"github.com/emma-community/terraform-provider-emma/tools"
+	"net/http"
+)
+
+// sshKeyResponse is the created or imported ssh key, the private key is null if the key is imported.
+type sshKeyResponse struct {
+	Id          *int32  `json:"id"`
+	Name        *string `json:"name"`
+	Key         *string `json:"key"`
+	Fingerprint *string `json:"fingerprint"`
+	KeyType     *string `json:"keyType"`
+	PrivateKey  *string `json:"privateKey"`
+	CreatedAt   *string `json:"createdAt"`
+}
+
+// findSshKey returns the ssh key of the path parameter, the not found error is written if it doesn't exist.
+func (s *Server) findSshKey(w http.ResponseWriter, req *http.Request) *emmaSdk.SshKey {
+	id, ok := pathId(w, req, "sshKeyId")
+	if !ok {
+		return nil
+	}
+	sshKey := s.state.SshKeys[id]
+	if sshKey == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("SSH key %d not found", id))
+	}
+	return sshKey
+}
+
+func (s *Server) getSshKeys(w http.ResponseWriter, req *http.Request) error {
+	writeJSON(w, http.StatusOK, sortedValues(s.state.SshKeys, nil))
+	return nil
+}
+
+func (s *Server) createSshKey(w http.ResponseWriter, req *http.Request) error {
+	var request struct {
+		Name    string `json:"name"`
+		Key     string `json:"key"`
+		KeyType string `json:"keyType"`
+	}
+	if !readJSON(w, req, &request) {
+		return nil
+	}
+	if request.Name == "" || (request.Key == "") == (request.KeyType == "") {
+		writeError(w, http.StatusBadRequest, "name and either key or keyType are required")
+		return nil
+	}
+
+	var privateKey *string
+	if request.Key != "" {
+		keyType, err := emma.ParseSshPublicKey(request.Key)
+		if err != nil {
+			writeError(w, http.StatusUnprocessableEntity, "Invalid SSH key: "+err.Error())
+			return nil
+		}
+		request.KeyType = keyType
+	} else {
+		generatedPrivateKey, authorizedKey, err := tools.GenerateSshKeyPair(request.KeyType)
+		if err != nil {
+			writeError(w, http.StatusUnprocessableEntity, err.Error())
+			return nil
+		}
+		request.Key = authorizedKey
+		privateKey = &generatedPrivateKey
+	}
+	fingerprint, err := tools.SshKeyFingerprint(request.Key)
+	if err != nil {
+		return err
+	}
+
+	id := s.state.nextId("sshKey")
+	sshKey := &emmaSdk.SshKey{
+		Id:          emmaSdk.PtrInt32(id),
+		Name:        emmaSdk.PtrString(request.Name),
+		Key:         emmaSdk.PtrString(request.Key),
+		Fingerprint: emmaSdk.PtrString(fingerprint),
+		KeyType:     emmaSdk.PtrString(request.KeyType),
+		CreatedAt:   emmaSdk.PtrString(createdAt),
+	}
+	s.state.SshKeys[id] = sshKey
+	writeJSON(w, http.StatusCreated, sshKeyResponse{Id: sshKey.Id, Name: sshKey.Name, Key: sshKey.Key,
+		Fingerprint: sshKey.Fingerprint, KeyType: sshKey.KeyType, PrivateKey: privateKey, CreatedAt: sshKey.CreatedAt})
+	return nil
+}
+
+func (s *Server) getSshKey(w http.ResponseWriter, req *http.Request) error {
+	sshKey := s.findSshKey(w, req)
+	if sshKey != nil {
+		writeJSON(w, http.StatusOK, sshKey)
+	}
+	return nil
+}
+
+func (s *Server) updateSshKey(w http.ResponseWriter, req *http.Request) error {
+	sshKey := s.findSshKey(w, req)
+	if sshKey == nil {
+		return nil
+	}
+	var request emmaSdk.SshKeyUpdate
+	if !readJSON(w, req, &request) {
+		return nil
+	}
+	sshKey.Name = emmaSdk.PtrString(request.Name)
+	writeJSON(w, http.StatusOK, sshKey)
+	return nil
+}
+
+func (s *Server) deleteSshKey(w http.ResponseWriter, req *http.Request) error {
+	sshKey := s.findSshKey(w, req)
+	if sshKey == nil {
+		return nil
+	}
+	delete(s.state.SshKeys, *sshKey.Id)
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
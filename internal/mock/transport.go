@@ -0,0 +1,20 @@
+package mock
+
+import (
+	"net/http"
+	"net/http/httptest"
+)
+
+// Transport is the round tripper that serves the requests with the handler in-process, the host of the request
+// is ignored.
+type Transport struct {
+	Handler http.Handler
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorder := httptest.NewRecorder()
+	t.Handler.ServeHTTP(recorder, req)
+	response := recorder.Result()
+	response.Request = req
+	return response, nil
+}
@@ -1,10 +1,15 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"flag"
+	"fmt"
 	"github.com/emma-community/terraform-provider-emma/internal/emma"
 	"log"
+	"os"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 )
@@ -20,6 +25,12 @@ import (
 //go:generate go run github.com/hashicorp/terraform-plugin-docs/cmd/tfplugindocs generate -provider-name emma -examples-dir ./examples/
 
 func main() {
+	// Terraform runs the provider without arguments, the import sub-command is run by the practitioners
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		generateImportConfig(os.Args[2:])
+		return
+	}
+
 	var debug bool
 
 	flag.BoolVar(&debug, "debug", false, "set to true to run the provider with support for debuggers like delve")
@@ -41,3 +52,41 @@ func main() {
 		log.Fatal(err.Error())
 	}
 }
+
+// generateImportConfig runs the import sub-command, it writes the Terraform import blocks and the configuration of
+// the resources in the emma project of the EMMA_CLIENT_ID and EMMA_CLIENT_SECRET credentials.
+func generateImportConfig(args []string) {
+	var host, resourceTypes, out string
+
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	flags.StringVar(&host, "host", "", "emma API host, the EMMA_HOST environment variable or the public emma API by default")
+	flags.StringVar(&resourceTypes, "resource-types", strings.Join(emma.ImportConfigResourceTypes(), ","),
+		"comma-separated resource types to import")
+	flags.StringVar(&out, "out", "", "new file to write the configuration to, the standard output by default")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s import [options]\n\n"+
+			"Writes the import blocks and the configuration of the resources in the emma project.\n\nOptions:\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+
+	options := emma.ImportConfigOptions{Host: host, ResourceTypes: strings.Split(resourceTypes, ",")}
+	var config bytes.Buffer
+	if err := emma.GenerateImportConfig(context.Background(), &config, options); err != nil {
+		log.Fatal(err.Error())
+	}
+
+	if out == "" {
+		_, _ = os.Stdout.Write(config.Bytes())
+		return
+	}
+	// an existing file isn't overwritten like by terraform plan -generate-config-out
+	file, err := os.OpenFile(out, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err == nil {
+		_, err = file.Write(config.Bytes())
+		err = errors.Join(err, file.Close())
+	}
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+}
@@ -0,0 +1,123 @@
+package tools
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Cache keeps the fetched values in memory for the lifetime of the process and, if the directory is set,
+// on disk until the time to live expires. Concurrent requests of the same key fetch the value once.
+type Cache struct {
+	mutex     sync.Mutex
+	entries   map[string]*cacheEntry
+	directory string
+	ttl       time.Duration
+}
+
+type cacheEntry struct {
+	done  chan struct{}
+	value any
+	err   error
+}
+
+type cacheFile struct {
+	Key       string          `json:"key"`
+	CreatedAt time.Time       `json:"created_at"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// NewCache returns a cache, the values are stored on disk only if the directory isn't empty.
+func NewCache(directory string, ttl time.Duration) *Cache {
+	return &Cache{entries: make(map[string]*cacheEntry), directory: directory, ttl: ttl}
+}
+
+// GetCached returns the cached value of the key or the value returned by fetch. The values that failed
+// to be fetched aren't cached. The second result reports whether the value was cached.
+func GetCached[T any](c *Cache, key string, fetch func() (T, error)) (T, bool, error) {
+	c.mutex.Lock()
+	entry, ok := c.entries[key]
+	if ok {
+		c.mutex.Unlock()
+		<-entry.done
+		if entry.err != nil {
+			var zero T
+			return zero, false, entry.err
+		}
+		return entry.value.(T), true, nil
+	}
+	entry = &cacheEntry{done: make(chan struct{})}
+	c.entries[key] = entry
+	c.mutex.Unlock()
+
+	value, cached, err := loadCached(c, key, fetch)
+	entry.value, entry.err = value, err
+	if err != nil {
+		c.mutex.Lock()
+		delete(c.entries, key)
+		c.mutex.Unlock()
+	}
+	close(entry.done)
+	return value, cached, err
+}
+
+// loadCached reads the value from disk or fetches it and stores it on disk.
+func loadCached[T any](c *Cache, key string, fetch func() (T, error)) (T, bool, error) {
+	if value, ok := readCacheFile[T](c, key); ok {
+		return value, true, nil
+	}
+	value, err := fetch()
+	if err != nil {
+		return value, false, err
+	}
+	// the value is cached in memory even if it can't be stored on disk
+	_ = c.writeFile(key, value)
+	return value, false, nil
+}
+
+func (c *Cache) filePath(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return filepath.Join(c.directory, hex.EncodeToString(hash[:])+".json")
+}
+
+// readCacheFile returns the value stored on disk if it isn't expired.
+func readCacheFile[T any](c *Cache, key string) (T, bool) {
+	var value T
+	if c.directory == "" {
+		return value, false
+	}
+	content, err := os.ReadFile(c.filePath(key))
+	if err != nil {
+		return value, false
+	}
+	var file cacheFile
+	if err := json.Unmarshal(content, &file); err != nil || file.Key != key || time.Since(file.CreatedAt) > c.ttl {
+		return value, false
+	}
+	if err := json.Unmarshal(file.Value, &value); err != nil {
+		return value, false
+	}
+	return value, true
+}
+
+func (c *Cache) writeFile(key string, value any) error {
+	if c.directory == "" {
+		return nil
+	}
+	valueContent, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	content, err := json.Marshal(cacheFile{Key: key, CreatedAt: time.Now(), Value: valueContent})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.directory, 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(c.filePath(key), content, 0600)
+}
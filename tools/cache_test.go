@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetCached(t *testing.T) {
+	cache := NewCache("", 0)
+	fetches := 0
+	fetch := func() ([]string, error) {
+		fetches++
+		return []string{"value"}, nil
+	}
+
+	value, cached, err := GetCached(cache, "key", fetch)
+	assert.NoError(t, err)
+	assert.False(t, cached)
+	assert.Equal(t, []string{"value"}, value)
+
+	value, cached, err = GetCached(cache, "key", fetch)
+	assert.NoError(t, err)
+	assert.True(t, cached)
+	assert.Equal(t, []string{"value"}, value)
+	assert.Equal(t, 1, fetches)
+
+	_, _, err = GetCached(cache, "other", fetch)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, fetches)
+}
+
+func TestGetCached_ErrorNotCached(t *testing.T) {
+	cache := NewCache("", 0)
+	_, _, err := GetCached(cache, "key", func() (string, error) {
+		return "", errors.New("unavailable")
+	})
+	assert.EqualError(t, err, "unavailable")
+
+	value, cached, err := GetCached(cache, "key", func() (string, error) {
+		return "value", nil
+	})
+	assert.NoError(t, err)
+	assert.False(t, cached)
+	assert.Equal(t, "value", value)
+}
+
+func TestGetCached_ConcurrentFetchOnce(t *testing.T) {
+	cache := NewCache("", 0)
+	var fetches atomic.Int32
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, _, err := GetCached(cache, "key", func() (int, error) {
+				fetches.Add(1)
+				<-release
+				return 42, nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, 42, value)
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), fetches.Load())
+}
+
+func TestGetCached_Disk(t *testing.T) {
+	directory := t.TempDir()
+	_, _, err := GetCached(NewCache(directory, time.Hour), "key", func() ([]string, error) {
+		return []string{"value"}, nil
+	})
+	assert.NoError(t, err)
+
+	// a new cache of the next process reads the value from disk
+	value, cached, err := GetCached(NewCache(directory, time.Hour), "key", func() ([]string, error) {
+		return nil, errors.New("offline")
+	})
+	assert.NoError(t, err)
+	assert.True(t, cached)
+	assert.Equal(t, []string{"value"}, value)
+
+	// the expired value is fetched again
+	value, cached, err = GetCached(NewCache(directory, -time.Second), "key", func() ([]string, error) {
+		return []string{"new value"}, nil
+	})
+	assert.NoError(t, err)
+	assert.False(t, cached)
+	assert.Equal(t, []string{"new value"}, value)
+}
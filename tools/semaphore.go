@@ -0,0 +1,51 @@
+package tools
+
+import "context"
+
+// Semaphore limits the number of concurrent operations.
+type Semaphore struct {
+	slots chan struct{}
+}
+
+// NewSemaphore returns a semaphore that allows the given number of concurrent operations, at least one.
+func NewSemaphore(limit int) *Semaphore {
+	if limit < 1 {
+		limit = 1
+	}
+	return &Semaphore{slots: make(chan struct{}, limit)}
+}
+
+// TryAcquire acquires a slot without waiting and reports whether the slot was acquired.
+func (s *Semaphore) TryAcquire() bool {
+	select {
+	case s.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Acquire waits for a free slot, the error of the context is returned if it is done before.
+func (s *Semaphore) Acquire(ctx context.Context) error {
+	select {
+	case s.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Release frees the slot acquired before.
+func (s *Semaphore) Release() {
+	<-s.slots
+}
+
+// Limit returns the number of concurrent operations allowed by the semaphore.
+func (s *Semaphore) Limit() int {
+	return cap(s.slots)
+}
+
+// InUse returns the number of acquired slots.
+func (s *Semaphore) InUse() int {
+	return len(s.slots)
+}
@@ -0,0 +1,55 @@
+package tools
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSemaphore(t *testing.T) {
+	semaphore := NewSemaphore(2)
+	assert.Equal(t, 2, semaphore.Limit())
+
+	assert.True(t, semaphore.TryAcquire())
+	assert.NoError(t, semaphore.Acquire(context.Background()))
+	assert.Equal(t, 2, semaphore.InUse())
+	assert.False(t, semaphore.TryAcquire())
+
+	semaphore.Release()
+	assert.Equal(t, 1, semaphore.InUse())
+	assert.True(t, semaphore.TryAcquire())
+}
+
+func TestSemaphore_AcquireWaitsForRelease(t *testing.T) {
+	semaphore := NewSemaphore(1)
+	assert.True(t, semaphore.TryAcquire())
+
+	acquired := make(chan error)
+	go func() {
+		acquired <- semaphore.Acquire(context.Background())
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("slot acquired before release")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	semaphore.Release()
+	assert.NoError(t, <-acquired)
+}
+
+func TestSemaphore_AcquireCanceled(t *testing.T) {
+	semaphore := NewSemaphore(1)
+	assert.True(t, semaphore.TryAcquire())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, semaphore.Acquire(ctx), context.Canceled)
+	assert.Equal(t, 1, semaphore.InUse())
+}
+
+func TestNewSemaphore_MinimumLimit(t *testing.T) {
+	assert.Equal(t, 1, NewSemaphore(0).Limit())
+}
@@ -0,0 +1,69 @@
+package tools
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/pem"
+	"fmt"
+	"golang.org/x/crypto/ssh"
+	"strings"
+)
+
+// RsaKeySize is the size in bits of the RSA keys generated locally.
+const RsaKeySize = 4096
+
+// GenerateSshKeyPair generates an RSA or ED25519 key and returns the private key in the OpenSSH PEM format
+// and the public key in the authorized_keys format.
+func GenerateSshKeyPair(keyType string) (string, string, error) {
+	var privateKey crypto.Signer
+	var err error
+	switch keyType {
+	case "RSA":
+		privateKey, err = rsa.GenerateKey(rand.Reader, RsaKeySize)
+	case "ED25519":
+		_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return "", "", fmt.Errorf("unsupported key type %s", keyType)
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	privateKeyBlock, err := ssh.MarshalPrivateKey(privateKey, "")
+	if err != nil {
+		return "", "", err
+	}
+	publicKey, err := ssh.NewPublicKey(privateKey.Public())
+	if err != nil {
+		return "", "", err
+	}
+	authorizedKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(publicKey)))
+	return string(pem.EncodeToMemory(privateKeyBlock)), authorizedKey, nil
+}
+
+// SshKeyFingerprint returns the SHA256 fingerprint of the public key in the authorized_keys format.
+func SshKeyFingerprint(authorizedKey string) (string, error) {
+	publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(authorizedKey))
+	if err != nil {
+		return "", err
+	}
+	return ssh.FingerprintSHA256(publicKey), nil
+}
+
+// SshKeyFingerprintMatches reports whether the fingerprint belongs to the public key in the authorized_keys format.
+// Both the SHA256 and the legacy MD5 fingerprint formats are accepted, with or without the hash name prefix.
+func SshKeyFingerprintMatches(authorizedKey string, fingerprint string) bool {
+	publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(authorizedKey))
+	if err != nil {
+		return false
+	}
+	fingerprint = strings.TrimSpace(fingerprint)
+	sha256Fingerprint := ssh.FingerprintSHA256(publicKey)
+	if fingerprint == sha256Fingerprint || fingerprint == strings.TrimPrefix(sha256Fingerprint, "SHA256:") {
+		return true
+	}
+	md5Fingerprint := ssh.FingerprintLegacyMD5(publicKey)
+	return strings.EqualFold(strings.TrimPrefix(fingerprint, "MD5:"), md5Fingerprint)
+}
@@ -5,6 +5,8 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strconv"
 )
 
@@ -14,6 +16,10 @@ type ErrorResponse struct {
 }
 
 func ExtractErrorMessage(response *http.Response) string {
+	// response is nil when the request was not sent, e.g. the context was cancelled
+	if response == nil || response.Body == nil {
+		return ""
+	}
 	responseBytes, err := io.ReadAll(response.Body)
 	if err != nil {
 		return ""
@@ -75,3 +81,21 @@ func GetInt64OrDefault(configValue *int32, defaultValue types.Int64) types.Int64
 	}
 	return defaultValue
 }
+
+// WriteSensitiveFile writes the content to the file that only the current user can read and write,
+// the permissions of an existing file are changed to 0600 as well.
+func WriteSensitiveFile(path string, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err = file.Chmod(0600); err != nil {
+		return err
+	}
+	_, err = file.WriteString(content)
+	return err
+}
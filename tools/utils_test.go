@@ -4,6 +4,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -19,7 +21,26 @@ func TestExtractErrorMessage(t *testing.T) {
 	readCloser.Close()
 }
 
+func TestExtractErrorMessage_NilResponse(t *testing.T) {
+	assert.Equal(t, "", ExtractErrorMessage(nil))
+}
+
 func TestStringToInt32(t *testing.T) {
 	str := "42"
 	assert.Equal(t, int32(42), StringToInt32(str))
 }
+
+func TestWriteSensitiveFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "id_rsa")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+	assert.NoError(t, os.WriteFile(path, []byte("old content"), 0644))
+
+	assert.NoError(t, WriteSensitiveFile(path, "private key"))
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "private key", string(content))
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}